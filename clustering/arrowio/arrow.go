@@ -0,0 +1,149 @@
+// Package arrowio converts between clustering datasets and models and Apache Arrow record batches and tables.
+package arrowio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/frederikdesmedt/clustering"
+)
+
+// FromVectorColumn will create a dataset of VectorNs from a `fixed_size_list<float64>` column of the record batch without copying:
+// every vector is a view on the memory of the record batch. The record batch must therefore be retained as long as the dataset is in use,
+// and the vectors must not be modified unless modifying the record batch is intended.
+func FromVectorColumn(record arrow.RecordBatch, column string) (clustering.Dataset, error) {
+	list, err := vectorColumn(record, column)
+	if err != nil {
+		return clustering.Dataset{}, err
+	}
+	dimension := int(list.DataType().(*arrow.FixedSizeListType).Len())
+	values := list.ListValues().(*array.Float64).Float64Values()
+	data := make([]clustering.Vector, list.Len())
+	for i := range data {
+		start, end := list.ValueOffsets(i)
+		data[i] = clustering.VectorN(values[start:end:end])
+	}
+	return clustering.CreateDataset(data, make(clustering.VectorN, dimension).Creator()), nil
+}
+
+func vectorColumn(record arrow.RecordBatch, column string) (*array.FixedSizeList, error) {
+	indices := record.Schema().FieldIndices(column)
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("The record batch has no column %q", column)
+	}
+	list, ok := record.Column(indices[0]).(*array.FixedSizeList)
+	if !ok || !arrow.TypeEqual(list.DataType().(*arrow.FixedSizeListType).Elem(), arrow.PrimitiveTypes.Float64) {
+		return nil, fmt.Errorf("Column %q is a %v column instead of a fixed_size_list<float64> column", column, record.Column(indices[0]).DataType())
+	}
+	if list.NullN() > 0 || list.ListValues().NullN() > 0 {
+		return nil, fmt.Errorf("Column %q contains null values", column)
+	}
+	return list, nil
+}
+
+// FromColumns will create a dataset of VectorNs whose components are the values of the selected numeric columns of the record batch.
+// As Arrow stores columns rather than rows the values are copied.
+func FromColumns(record arrow.RecordBatch, columns ...string) (clustering.Dataset, error) {
+	if len(columns) == 0 {
+		return clustering.Dataset{}, errors.New("Expected at least one column to read")
+	}
+	rows := make([]clustering.VectorN, record.NumRows())
+	for i := range rows {
+		rows[i] = make(clustering.VectorN, len(columns))
+	}
+	for component, column := range columns {
+		indices := record.Schema().FieldIndices(column)
+		if len(indices) == 0 {
+			return clustering.Dataset{}, fmt.Errorf("The record batch has no column %q", column)
+		}
+		values := record.Column(indices[0])
+		if values.NullN() > 0 {
+			return clustering.Dataset{}, fmt.Errorf("Column %q contains null values", column)
+		}
+		value, err := numericValue(values)
+		if err != nil {
+			return clustering.Dataset{}, fmt.Errorf("Column %q: %v", column, err)
+		}
+		for i := range rows {
+			rows[i][component] = value(i)
+		}
+	}
+	data := make([]clustering.Vector, len(rows))
+	for i, row := range rows {
+		data[i] = row
+	}
+	return clustering.CreateDataset(data, make(clustering.VectorN, len(columns)).Creator()), nil
+}
+
+func numericValue(values arrow.Array) (func(i int) float64, error) {
+	switch typed := values.(type) {
+	case *array.Float64:
+		return typed.Value, nil
+	case *array.Float32:
+		return func(i int) float64 { return float64(typed.Value(i)) }, nil
+	case *array.Int64:
+		return func(i int) float64 { return float64(typed.Value(i)) }, nil
+	case *array.Int32:
+		return func(i int) float64 { return float64(typed.Value(i)) }, nil
+	default:
+		return nil, fmt.Errorf("Values of type %v are not supported", values.DataType())
+	}
+}
+
+// CentroidsTable will create a table with a `cluster` column and a `centroid` column of type `fixed_size_list<float64>` holding the centroid of every cluster.
+// The caller is responsible for releasing the table.
+func CentroidsTable(mem memory.Allocator, clusterer clustering.CentroidClusterer) (arrow.Table, error) {
+	centroids := []clustering.Vector(clusterer)
+	dimension := 0
+	if len(centroids) > 0 {
+		dimension = clustering.Dimension(centroids[0])
+	}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "cluster", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "centroid", Type: arrow.FixedSizeListOf(int32(dimension), arrow.PrimitiveTypes.Float64)},
+	}, nil)
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+
+	clusters := builder.Field(0).(*array.Int32Builder)
+	vectors := builder.Field(1).(*array.FixedSizeListBuilder)
+	components := vectors.ValueBuilder().(*array.Float64Builder)
+	for cluster, centroid := range centroids {
+		if clustering.Dimension(centroid) != dimension {
+			return nil, fmt.Errorf("Expected centroids of dimension %d but centroid %d has dimension %d", dimension, cluster, clustering.Dimension(centroid))
+		}
+		clusters.Append(int32(cluster))
+		vectors.Append(true)
+		for i := 0; i < dimension; i++ {
+			components.Append(clustering.Component(centroid, i))
+		}
+	}
+	return recordTable(builder.NewRecordBatch()), nil
+}
+
+// AssignmentsTable will create a table with a `row` column and a `cluster` column holding the cluster every row was assigned to.
+// The caller is responsible for releasing the table.
+func AssignmentsTable(mem memory.Allocator, assignments []clustering.Cluster) arrow.Table {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "row", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "cluster", Type: arrow.PrimitiveTypes.Int32},
+	}, nil)
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+
+	rows := builder.Field(0).(*array.Int64Builder)
+	clusters := builder.Field(1).(*array.Int32Builder)
+	for row, cluster := range assignments {
+		rows.Append(int64(row))
+		clusters.Append(int32(cluster))
+	}
+	return recordTable(builder.NewRecordBatch())
+}
+
+func recordTable(record arrow.RecordBatch) arrow.Table {
+	defer record.Release()
+	return array.NewTableFromRecords(record.Schema(), []arrow.RecordBatch{record})
+}
@@ -0,0 +1,102 @@
+package clustering
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Assignment is the cluster of a single row of a dataset, identified by the ID of the row in its source, e.g., to join assignments back to a source table.
+type Assignment struct {
+	// ID identifies the row in its source, e.g., its primary key.
+	ID string
+	// Cluster is the cluster the row was assigned to.
+	Cluster Cluster
+	// Distance is the Euclidean distance between the row and the centroid of its cluster, NaN for Noise.
+	Distance float64
+}
+
+// AssignRows will assign every vector of the dataset to a cluster, where `ids[i]` identifies the `i`th vector, or its index if the IDs are nil.
+// The distances are to the centroids of a *CentroidClusterer, for any other clusterer they are to the mean of the vectors of the dataset assigned to the same cluster.
+func AssignRows(dataset *Dataset, clusterer SimpleFlatClusterer, ids []string) ([]Assignment, error) {
+	if ids != nil && len(ids) != dataset.Count() {
+		return nil, fmt.Errorf("Expected %d IDs but got %d", dataset.Count(), len(ids))
+	}
+	clusters, err := dataset.FindClusters(clusterer)
+	if err != nil {
+		return nil, err
+	}
+	centroids := fittedCentroids(dataset, clusterer, clusters)
+	assignments := make([]Assignment, dataset.Count())
+	for i, vec := range dataset.data {
+		assignments[i] = Assignment{ID: strconv.Itoa(i), Cluster: clusters[i], Distance: math.NaN()}
+		if ids != nil {
+			assignments[i].ID = ids[i]
+		}
+		if centroid, ok := centroids[clusters[i]]; ok && clusters[i] != Noise {
+			assignments[i].Distance = Euclidean(vec, centroid)
+		}
+	}
+	return assignments, nil
+}
+
+// fittedCentroids returns the centroids of a *CentroidClusterer, or the mean of the vectors of the dataset assigned to every cluster by any other clusterer.
+func fittedCentroids(dataset *Dataset, clusterer SimpleFlatClusterer, clusters []Cluster) map[Cluster]Vector {
+	if fitted, ok := clusterer.(*CentroidClusterer); ok {
+		return fitted.Centroids()
+	}
+	labels, _, means := assignmentCentroids(dataset, clusters)
+	centroids := make(map[Cluster]Vector, len(means))
+	for i, cluster := range clusters {
+		centroids[cluster] = means[labels[i]]
+	}
+	return centroids
+}
+
+// WriteAssignmentsCSV will write the assignments as CSV records of the ID, the cluster and the distance, preceded by the header `id,cluster,distance`.
+// Distances that are NaN are written as empty fields.
+func WriteAssignmentsCSV(w io.Writer, assignments []Assignment) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "cluster", "distance"}); err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		distance := ""
+		if !math.IsNaN(assignment.Distance) {
+			distance = strconv.FormatFloat(assignment.Distance, 'g', -1, 64)
+		}
+		if err := writer.Write([]string{assignment.ID, strconv.Itoa(int(assignment.Cluster)), distance}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteAssignmentsNDJSON will write the assignments as newline-delimited JSON objects with the fields `id`, `cluster` and `distance`,
+// the distance being null if it is NaN.
+func WriteAssignmentsNDJSON(w io.Writer, assignments []Assignment) error {
+	encoder := json.NewEncoder(w)
+	for _, assignment := range assignments {
+		if err := encoder.Encode(assignment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON will encode the assignment as an object with the fields `id`, `cluster` and `distance`, the distance being null if it is NaN.
+func (assignment Assignment) MarshalJSON() ([]byte, error) {
+	encoded := struct {
+		ID       string   `json:"id"`
+		Cluster  Cluster  `json:"cluster"`
+		Distance *float64 `json:"distance"`
+	}{ID: assignment.ID, Cluster: assignment.Cluster}
+	if !math.IsNaN(assignment.Distance) {
+		encoded.Distance = &assignment.Distance
+	}
+	return json.Marshal(encoded)
+}
@@ -0,0 +1,121 @@
+package clustering
+
+import "math"
+
+// BallTree is a NeighbourIndex under any metric satisfying the triangle inequality that recursively splits the vectors of a dataset into two balls around the means of their vectors,
+// which unlike a KDTree keeps pruning in high dimensions and for metrics that are not aligned with the axes. The tree does not follow later changes to the dataset.
+type BallTree struct {
+	data   []Vector
+	metric Metric
+	// order holds the indices of the vectors such that every node covers a contiguous range.
+	order []int
+	nodes []ballNode
+}
+
+// ballNode covers the vectors in `order[lo:hi]`, which are all within `radius` of the centre, its children are -1 for a leaf.
+type ballNode struct {
+	lo, hi      int
+	centre      Vector
+	radius      float64
+	left, right int
+}
+
+// NewBallTree will build a BallTree over the vectors of the dataset with distances measured by the metric.
+func NewBallTree(dataset *Dataset, metric Metric) *BallTree {
+	tree := &BallTree{data: dataset.data, metric: metric, order: make([]int, dataset.Count())}
+	for i := range tree.order {
+		tree.order[i] = i
+	}
+	if len(tree.order) > 0 {
+		tree.build(0, len(tree.order))
+	}
+	return tree
+}
+
+// build adds the node covering `order[lo:hi]` and its descendants, returning its position.
+func (tree *BallTree) build(lo, hi int) int {
+	var collector bucketCollector
+	for _, i := range tree.order[lo:hi] {
+		collector.Collect(tree.data[i], 1)
+	}
+	node := ballNode{lo: lo, hi: hi, centre: collector.Average(), left: -1, right: -1}
+	furthest := tree.order[lo]
+	for _, i := range tree.order[lo:hi] {
+		if distance := tree.metric(node.centre, tree.data[i]); distance > node.radius {
+			node.radius, furthest = distance, i
+		}
+	}
+	position := len(tree.nodes)
+	tree.nodes = append(tree.nodes, node)
+	if hi-lo <= kdLeafSize || node.radius == 0 {
+		return position
+	}
+
+	// The vectors are split by whether they are closer to the vector furthest from the centre or to the vector furthest from that one.
+	a, b, spread := tree.data[furthest], tree.data[furthest], 0.0
+	for _, i := range tree.order[lo:hi] {
+		if distance := tree.metric(a, tree.data[i]); distance > spread {
+			b, spread = tree.data[i], distance
+		}
+	}
+	middle := lo
+	for j := lo; j < hi; j++ {
+		if i := tree.order[j]; tree.metric(a, tree.data[i]) <= tree.metric(b, tree.data[i]) {
+			tree.order[j], tree.order[middle] = tree.order[middle], tree.order[j]
+			middle++
+		}
+	}
+	if middle == lo || middle == hi {
+		return position
+	}
+	left := tree.build(lo, middle)
+	right := tree.build(middle, hi)
+	tree.nodes[position].left, tree.nodes[position].right = left, right
+	return position
+}
+
+// search visits every vector of the node that may be within the bound of the query, given the distance between the query and the centre of the node.
+func (tree *BallTree) search(position int, query Vector, toCentre float64, visit func(i int, distance float64), bound func() float64) {
+	node := tree.nodes[position]
+	if toCentre-node.radius > bound() {
+		return
+	}
+	if node.left < 0 {
+		for _, i := range tree.order[node.lo:node.hi] {
+			visit(i, tree.metric(query, tree.data[i]))
+		}
+		return
+	}
+	toLeft, toRight := tree.metric(query, tree.nodes[node.left].centre), tree.metric(query, tree.nodes[node.right].centre)
+	if toLeft <= toRight {
+		tree.search(node.left, query, toLeft, visit, bound)
+		tree.search(node.right, query, toRight, visit, bound)
+	} else {
+		tree.search(node.right, query, toRight, visit, bound)
+		tree.search(node.left, query, toLeft, visit, bound)
+	}
+}
+
+// Nearest returns the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+func (tree *BallTree) Nearest(query Vector, k int) []Neighbour {
+	nearest := &nearestNeighbours{k: max(k, 0)}
+	if len(tree.nodes) > 0 {
+		tree.search(0, query, tree.metric(query, tree.nodes[0].centre), nearest.offer, nearest.bound)
+	}
+	return nearest.sorted()
+}
+
+// WithinRadius returns the vectors within distance `radius` of the query in increasing order of distance.
+func (tree *BallTree) WithinRadius(query Vector, radius float64) []Neighbour {
+	var within []Neighbour
+	if len(tree.nodes) == 0 || radius < 0 || math.IsNaN(radius) {
+		return within
+	}
+	tree.search(0, query, tree.metric(query, tree.nodes[0].centre), func(i int, distance float64) {
+		if distance <= radius {
+			within = append(within, Neighbour{Index: i, Distance: distance})
+		}
+	}, func() float64 { return radius })
+	sortNeighbours(within)
+	return within
+}
@@ -0,0 +1,99 @@
+package clustering
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+)
+
+// Algorithm is a named clustering algorithm to compare in a Benchmark.
+type Algorithm struct {
+	// Name identifies the algorithm in the results.
+	Name string
+	// Run clusters the dataset, returning the cluster of every vector, e.g., the Assignments of a fitted clusterer or the result of DBSCAN.
+	Run func(dataset *Dataset) ([]Cluster, error)
+}
+
+// BenchmarkDataset is a named dataset to cluster in a Benchmark.
+type BenchmarkDataset struct {
+	// Name identifies the dataset in the results.
+	Name string
+	// Dataset is the dataset to cluster.
+	Dataset *Dataset
+	// Labels holds the ground-truth label of every vector, if known.
+	Labels []Cluster
+}
+
+// BenchmarkResult holds the measurements of running a single algorithm on a single dataset.
+type BenchmarkResult struct {
+	// Algorithm and Dataset are the names of the algorithm and the dataset.
+	Algorithm, Dataset string
+	// Duration is the time taken by the algorithm.
+	Duration time.Duration
+	// Clusters is the number of distinct clusters found.
+	Clusters int
+	// Inertia is the sum of squared Euclidean distances between every vector and the mean of its cluster.
+	Inertia float64
+	// Silhouette is the mean Silhouette using Euclidean distances, NaN if there are fewer than two clusters.
+	Silhouette float64
+	// AdjustedRand is the AdjustedRandIndex against the labels of the dataset, NaN if the dataset has no labels.
+	AdjustedRand float64
+	// Err holds the error of the algorithm, all measurements but the duration are meaningless if it is set.
+	Err error
+}
+
+// Benchmark will run every algorithm on every dataset and measure its runtime and the quality of the resulting clusterings, e.g., to choose an algorithm empirically.
+// The algorithms run one at a time, such that their durations are comparable, and the results are ordered by dataset and then by algorithm.
+func Benchmark(algorithms []Algorithm, datasets []BenchmarkDataset) []BenchmarkResult {
+	results := make([]BenchmarkResult, 0, len(algorithms)*len(datasets))
+	for _, dataset := range datasets {
+		for _, algorithm := range algorithms {
+			results = append(results, benchmark(algorithm, dataset))
+		}
+	}
+	return results
+}
+
+func benchmark(algorithm Algorithm, dataset BenchmarkDataset) BenchmarkResult {
+	result := BenchmarkResult{Algorithm: algorithm.Name, Dataset: dataset.Name, Silhouette: math.NaN(), AdjustedRand: math.NaN()}
+	start := time.Now()
+	assignments, err := algorithm.Run(dataset.Dataset)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if len(assignments) != dataset.Dataset.Count() {
+		result.Err = fmt.Errorf("Expected a cluster for all %d vectors but got %d", dataset.Dataset.Count(), len(assignments))
+		return result
+	}
+	labels, sizes, centroids := assignmentCentroids(dataset.Dataset, assignments)
+	result.Clusters = len(sizes)
+	result.Inertia = withinSumOfSquares(dataset.Dataset, labels, centroids)
+	if values, err := silhouetteValues(dataset.Dataset.data, assignments, Euclidean); err == nil {
+		result.Silhouette = sum(values) / float64(len(values))
+	}
+	if dataset.Labels != nil {
+		if result.AdjustedRand, err = AdjustedRandIndex(dataset.Labels, assignments); err != nil {
+			result.AdjustedRand, result.Err = math.NaN(), err
+		}
+	}
+	return result
+}
+
+// WriteBenchmarkTable will write the results as an aligned text table with a row per result.
+func WriteBenchmarkTable(w io.Writer, results []BenchmarkResult) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "dataset\talgorithm\tduration\tclusters\tinertia\tsilhouette\tadjusted rand\terror")
+	for _, result := range results {
+		failure := ""
+		if result.Err != nil {
+			failure = result.Err.Error()
+		}
+		fmt.Fprintf(table, "%s\t%s\t%v\t%d\t%.4g\t%.4f\t%.4f\t%s\n", result.Dataset, result.Algorithm, result.Duration.Round(time.Microsecond),
+			result.Clusters, result.Inertia, result.Silhouette, result.AdjustedRand, failure)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,86 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Binner is a Transformer discretizing every component into the index of the bin it falls in, e.g., for grid-based clustering
+// or to build a categorical view of numeric data. Values below the first or above the last edge fall in the first or last bin respectively.
+type Binner struct {
+	// Edges holds the increasing boundaries between consecutive bins of every component, a component with `b` bins has `b - 1` edges.
+	// A value equal to an edge belongs to the bin above the edge. The fitted binners give a constant component no edges, so all of its values fall in bin 0.
+	Edges [][]float64
+}
+
+// FitEqualWidthBinner will create a Binner dividing the range of every component of the vectors in the dataset into `bins` bins of equal width.
+func FitEqualWidthBinner(dataset *Dataset, bins int) (Binner, error) {
+	if dataset.IsEmpty() {
+		return Binner{}, errors.New("Cannot fit a binner on an empty dataset")
+	}
+	if bins <= 0 {
+		return Binner{}, fmt.Errorf("Expected a positive number of bins but got %d", bins)
+	}
+	scaler, err := FitMinMaxScaler(dataset)
+	if err != nil {
+		return Binner{}, err
+	}
+	binner := Binner{Edges: make([][]float64, len(scaler.Min))}
+	for i := range binner.Edges {
+		if scaler.Max[i] == scaler.Min[i] {
+			binner.Edges[i] = []float64{}
+			continue
+		}
+		width := (scaler.Max[i] - scaler.Min[i]) / float64(bins)
+		binner.Edges[i] = make([]float64, bins-1)
+		for b := range binner.Edges[i] {
+			binner.Edges[i][b] = scaler.Min[i] + float64(b+1)*width
+		}
+	}
+	return binner, nil
+}
+
+// FitQuantileBinner will create a Binner dividing every component of the vectors in the dataset into `bins` bins holding roughly equally many vectors.
+// Components with many equal values may result in fewer distinct bins.
+func FitQuantileBinner(dataset *Dataset, bins int) (Binner, error) {
+	if dataset.IsEmpty() {
+		return Binner{}, errors.New("Cannot fit a binner on an empty dataset")
+	}
+	if bins <= 0 {
+		return Binner{}, fmt.Errorf("Expected a positive number of bins but got %d", bins)
+	}
+	dimension := Dimension(dataset.data[0])
+	binner := Binner{Edges: make([][]float64, dimension)}
+	values := make([]float64, dataset.Count())
+	for i := range binner.Edges {
+		for j, vec := range dataset.data {
+			checkTransformDimension(vec, dimension)
+			values[j] = Component(vec, i)
+		}
+		sort.Float64s(values)
+		if values[0] == values[len(values)-1] {
+			binner.Edges[i] = []float64{}
+			continue
+		}
+		binner.Edges[i] = make([]float64, bins-1)
+		for b := range binner.Edges[i] {
+			binner.Edges[i][b] = quantile(values, float64(b+1)/float64(bins))
+		}
+	}
+	return binner, nil
+}
+
+// Transform maps every component of the vector onto the index of its bin.
+func (binner Binner) Transform(v Vector) Vector {
+	checkTransformDimension(v, len(binner.Edges))
+	return v.Creator().New(func(i int) float64 {
+		return float64(binner.Bin(i, Component(v, i)))
+	})
+}
+
+// Bin will return the index of the bin the value falls in for the `i`th component.
+func (binner Binner) Bin(i int, value float64) int {
+	edges := binner.Edges[i]
+	return sort.Search(len(edges), func(b int) bool { return edges[b] > value })
+}
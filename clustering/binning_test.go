@@ -0,0 +1,19 @@
+package clustering
+
+import "testing"
+
+func TestBinnersPutConstantComponentsInTheFirstBin(t *testing.T) {
+	dataset := CreateNonEmptyDataset([]Vector{VectorN{0, 5}, VectorN{1, 5}, VectorN{2, 5}, VectorN{3, 5}})
+	for name, fit := range map[string]func(*Dataset, int) (Binner, error){"equal width": FitEqualWidthBinner, "quantile": FitQuantileBinner} {
+		binner, err := fit(&dataset, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < dataset.Count(); i++ {
+			binned := binner.Transform(dataset.At(i))
+			if Component(binned, 0) != float64(i) || Component(binned, 1) != 0 {
+				t.Errorf("%s: Expected %v to fall in the bins [%d 0] but got %v", name, dataset.At(i), i, binned)
+			}
+		}
+	}
+}
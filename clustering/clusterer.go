@@ -1,70 +1,234 @@
-package clustering
-
-import "errors"
-
-// Cluster is a value expressing a single cluster.
-type Cluster int
-
-// SimpleFlatClusterer assigns a vector to exactly one cluster.
-type SimpleFlatClusterer interface {
-	// FindCluster returns the uniue cluster a vector is a part of.
-	FindCluster(v Vector) Cluster
-	// Clusters returns all the clusters this clusterer contains.
-	Clusters() []Cluster
-	// ClusteredPartition will split the dataset according to the cluster each element belongs to
-	// such that every element in the dataset is assigned to exactly one cluster and the
-	// union of all vector slices is equal to the datapoint slice of the original dataset.
-	ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error)
-}
-
-// CentroidClusterer is a clusterer that assigns a vector to a cluster such that the centroid of that cluster is at least as close to the supplied vector as every other centroid.
-type CentroidClusterer []Vector
-
-// Centroids will return a map from one of the clusters to the centroid of that cluster.
-func (clusterer *CentroidClusterer) Centroids() map[Cluster]Vector {
-	mapping := make(map[Cluster]Vector)
-	for cluster, centroid := range []Vector(*clusterer) {
-		mapping[Cluster(cluster)] = centroid
-	}
-	return mapping
-}
-
-// Clusters returns all the clusters this clusterer contains.
-func (clusterer *CentroidClusterer) Clusters() []Cluster {
-	length := len([]Vector(*clusterer))
-	clusters := make([]Cluster, length)
-	for i := 0; i < length; i++ {
-		clusters[i] = Cluster(i)
-	}
-	return clusters
-}
-
-// FindCluster returns the unique cluster a vector is a part of.
-func (clusterer *CentroidClusterer) FindCluster(v Vector) (Cluster, error) {
-	if len(clusterer.Centroids()) == 0 {
-		return -1, errors.New("There are no centroids in the CentroidClusterer")
-	}
-	assignedCluster, assignedClusterCentroid := Cluster(0), clusterer.Centroids()[0]
-	for cluster, centroid := range clusterer.Centroids() {
-		if centroid.DistanceTo(v) < assignedClusterCentroid.DistanceTo(v) {
-			assignedCluster = Cluster(cluster)
-			assignedClusterCentroid = centroid
-		}
-	}
-	return assignedCluster, nil
-}
-
-// ClusteredPartition will split the dataset according to the cluster each element belongs to
-// such that every element in the dataset is assigned to exactly one cluster and the
-// union of all vector slices is equal to the datapoint slice of the original dataset.
-func (clusterer CentroidClusterer) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
-	partition := make(map[Cluster][]Vector)
-	for _, vec := range dataset.AsSlice() {
-		cluster, err := clusterer.FindCluster(vec)
-		if err != nil {
-			return nil, err
-		}
-		partition[cluster] = append(partition[cluster], vec)
-	}
-	return partition, nil
-}
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Cluster is a value expressing a single cluster.
+type Cluster int
+
+// SimpleFlatClusterer assigns a vector to exactly one cluster.
+type SimpleFlatClusterer interface {
+	// FindCluster returns the unique cluster a vector is a part of.
+	FindCluster(v Vector) (Cluster, error)
+	// Clusters returns all the clusters this clusterer contains.
+	Clusters() []Cluster
+	// ClusteredPartition will split the dataset according to the cluster each element belongs to
+	// such that every element in the dataset is assigned to exactly one cluster and the
+	// union of all vector slices is equal to the datapoint slice of the original dataset.
+	ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error)
+}
+
+// SoftClusterer assigns a vector to every cluster with a degree of membership, e.g., a GaussianMixture or FuzzyCMeans.
+type SoftClusterer interface {
+	// Memberships returns the degree to which a vector belongs to every cluster, the memberships sum to 1.
+	Memberships(v Vector) map[Cluster]float64
+	// Clusters returns all the clusters this clusterer contains.
+	Clusters() []Cluster
+}
+
+// Harden will return the SimpleFlatClusterer assigning every vector to the cluster it has the largest membership of, the smallest such cluster on ties.
+func Harden(clusterer SoftClusterer) SimpleFlatClusterer {
+	return hardClusterer{soft: clusterer}
+}
+
+type hardClusterer struct {
+	soft SoftClusterer
+}
+
+// FindCluster returns the unique cluster a vector is a part of.
+func (clusterer hardClusterer) FindCluster(v Vector) (Cluster, error) {
+	best, largest := Noise, -1.0
+	for cluster, membership := range clusterer.soft.Memberships(v) {
+		if membership > largest || (membership == largest && cluster < best) {
+			best, largest = cluster, membership
+		}
+	}
+	if best == Noise {
+		return -1, errors.New("There are no clusters in the SoftClusterer")
+	}
+	return best, nil
+}
+
+// Clusters returns all the clusters this clusterer contains.
+func (clusterer hardClusterer) Clusters() []Cluster {
+	return clusterer.soft.Clusters()
+}
+
+// ClusteredPartition will split the dataset according to the cluster each element belongs to
+// such that every element in the dataset is assigned to exactly one cluster and the
+// union of all vector slices is equal to the datapoint slice of the original dataset.
+func (clusterer hardClusterer) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	partition := make(map[Cluster][]Vector)
+	for _, vec := range dataset.data {
+		cluster, err := clusterer.FindCluster(vec)
+		if err != nil {
+			return nil, err
+		}
+		partition[cluster] = append(partition[cluster], vec)
+	}
+	return partition, nil
+}
+
+// MembershipMatrix will return the memberships of every vector of the dataset, where `matrix[i][c]` is the membership of the `i`th vector of the `c`th cluster
+// in the order of Clusters, e.g., to compute the XieBeni index.
+func MembershipMatrix(dataset *Dataset, clusterer SoftClusterer) [][]float64 {
+	clusters := clusterer.Clusters()
+	matrix := make([][]float64, dataset.Count())
+	for i, vec := range dataset.data {
+		memberships := clusterer.Memberships(vec)
+		matrix[i] = make([]float64, len(clusters))
+		for c, cluster := range clusters {
+			matrix[i][c] = memberships[cluster]
+		}
+	}
+	return matrix
+}
+
+// CentroidClusterer is a clusterer that assigns a vector to a cluster such that the centroid of that cluster is at least as close to the supplied vector as every other centroid.
+type CentroidClusterer []Vector
+
+// Centroids will return a map from one of the clusters to the centroid of that cluster.
+func (clusterer *CentroidClusterer) Centroids() map[Cluster]Vector {
+	mapping := make(map[Cluster]Vector)
+	for cluster, centroid := range []Vector(*clusterer) {
+		mapping[Cluster(cluster)] = centroid
+	}
+	return mapping
+}
+
+// Clusters returns all the clusters this clusterer contains.
+func (clusterer *CentroidClusterer) Clusters() []Cluster {
+	length := len([]Vector(*clusterer))
+	clusters := make([]Cluster, length)
+	for i := 0; i < length; i++ {
+		clusters[i] = Cluster(i)
+	}
+	return clusters
+}
+
+// FindCluster returns the unique cluster a vector is a part of, the cluster of its closest centroid and the smallest such cluster on ties.
+func (clusterer *CentroidClusterer) FindCluster(v Vector) (Cluster, error) {
+	centroids := []Vector(*clusterer)
+	if len(centroids) == 0 {
+		return -1, errors.New("There are no centroids in the CentroidClusterer")
+	}
+	if err := checkCentroidQuery(centroids, v); err != nil {
+		return -1, err
+	}
+	return Cluster(nearestCentroid(centroids, v)), nil
+}
+
+// checkCentroidQuery returns an error when the vector is of another kind or dimension than the centroids, for which measuring the distances would panic.
+func checkCentroidQuery(centroids []Vector, v Vector) error {
+	if reflect.TypeOf(v) != reflect.TypeOf(centroids[0]) {
+		return fmt.Errorf("Expected a vector of type %T but got %T", centroids[0], v)
+	}
+	if Dimension(v) != Dimension(centroids[0]) {
+		return fmt.Errorf("Expected a vector of dimension %d but got dimension %d", Dimension(centroids[0]), Dimension(v))
+	}
+	return nil
+}
+
+// FindClusters returns the unique cluster every vector is a part of, in order, assigning the vectors in parallel.
+func (clusterer *CentroidClusterer) FindClusters(vs []Vector) ([]Cluster, error) {
+	centroids := []Vector(*clusterer)
+	if len(centroids) == 0 {
+		return nil, errors.New("There are no centroids in the CentroidClusterer")
+	}
+	for i, v := range vs {
+		if err := checkCentroidQuery(centroids, v); err != nil {
+			return nil, fmt.Errorf("Vector %d: %v", i, err)
+		}
+	}
+	clusters := make([]Cluster, len(vs))
+	parallelFor(len(vs), func(i int) {
+		clusters[i] = Cluster(nearestCentroid(centroids, vs[i]))
+	})
+	return clusters, nil
+}
+
+// ClusteredPartition will split the dataset according to the cluster each element belongs to
+// such that every element in the dataset is assigned to exactly one cluster and the
+// union of all vector slices is equal to the datapoint slice of the original dataset.
+func (clusterer CentroidClusterer) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	partition := make(map[Cluster][]Vector)
+	for _, vec := range dataset.data {
+		cluster, err := clusterer.FindCluster(vec)
+		if err != nil {
+			return nil, err
+		}
+		partition[cluster] = append(partition[cluster], vec)
+	}
+	return partition, nil
+}
+
+// IndexedPartition will split the dataset according to the cluster each element belongs to like ClusteredPartition,
+// but identifies every element by its index in the dataset instead, such that the elements can be traced back to their input rows.
+func (clusterer CentroidClusterer) IndexedPartition(dataset *Dataset) (map[Cluster][]int, error) {
+	assignments, err := Assignments(dataset, &clusterer)
+	if err != nil {
+		return nil, err
+	}
+	return PartitionIndices(assignments), nil
+}
+
+// Assignments will return the cluster the clusterer assigns to every vector of the dataset, in order.
+func Assignments(dataset *Dataset, clusterer SimpleFlatClusterer) ([]Cluster, error) {
+	assignments := make([]Cluster, dataset.Count())
+	for i, vec := range dataset.data {
+		cluster, err := clusterer.FindCluster(vec)
+		if err != nil {
+			return nil, err
+		}
+		assignments[i] = cluster
+	}
+	return assignments, nil
+}
+
+// BatchClusterer is a clusterer that assigns many vectors at once more efficiently than one at a time, e.g., a CentroidClusterer.
+type BatchClusterer interface {
+	// FindClusters returns the unique cluster every vector is a part of, in order.
+	FindClusters(vs []Vector) ([]Cluster, error)
+}
+
+// FindClusters will return the cluster the clusterer assigns to every vector, in order, using all cores.
+// Clusterers that are a BatchClusterer assign the vectors themselves, any other clusterer must be safe for concurrent use.
+func FindClusters(clusterer SimpleFlatClusterer, vs []Vector) ([]Cluster, error) {
+	if batch, ok := clusterer.(BatchClusterer); ok {
+		return batch.FindClusters(vs)
+	}
+	clusters, errs := make([]Cluster, len(vs)), make([]error, len(vs))
+	parallelFor(len(vs), func(i int) {
+		clusters[i], errs[i] = clusterer.FindCluster(vs[i])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return clusters, nil
+}
+
+// FindClusters will return the cluster the clusterer assigns to every vector of this dataset, in order, using all cores like FindClusters.
+func (dataset *Dataset) FindClusters(clusterer SimpleFlatClusterer) ([]Cluster, error) {
+	start := time.Now()
+	clusters, err := FindClusters(clusterer, dataset.data)
+	if err == nil {
+		dataset.instrument().Assignment("predict", dataset.Count(), time.Since(start))
+	}
+	return clusters, err
+}
+
+// PartitionIndices will group the indices of the elements by the cluster they were assigned to, e.g., the output of Assignments or DBSCAN.
+// The indices of every cluster are in increasing order.
+func PartitionIndices(assignments []Cluster) map[Cluster][]int {
+	partition := make(map[Cluster][]int)
+	for i, cluster := range assignments {
+		partition[cluster] = append(partition[cluster], i)
+	}
+	return partition
+}
@@ -0,0 +1,222 @@
+// Package clusterplot draws clusterings of two-dimensional vectors with gonum/plot: a scatter plot coloured by cluster,
+// optionally with a marker at the centroid, the convex hull and the Voronoi cell of every cluster.
+package clusterplot
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/frederikdesmedt/clustering"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// NoiseColour is the colour of vectors assigned to clustering.Noise.
+var NoiseColour color.Color = color.Gray{Y: 160}
+
+// Options determine how a clustering is drawn.
+type Options struct {
+	// Colours holds the colour of every cluster, in the order of the clusters of the clusterer, and is cycled if there are more clusters.
+	// The colours of plotutil are used when it is not set.
+	Colours []color.Color
+	// Radius is the radius of the glyph of every vector, the centroid markers are twice as large. It defaults to the radius of plotter.DefaultGlyphStyle.
+	Radius vg.Length
+	// Centroids draws a marker at the centroid of every cluster: the centroids of a *CentroidClusterer, or the mean of the vectors of every cluster of any other clusterer.
+	Centroids bool
+	// Hulls draws the convex hull of the vectors of every cluster, filled with a translucent version of its colour.
+	Hulls bool
+	// Cells draws the outline of the Voronoi cell of every centroid of a *CentroidClusterer, clipped to the bounding box of the vectors and centroids.
+	Cells bool
+	// Legend adds every cluster to the legend of the plot.
+	Legend bool
+}
+
+func (opts Options) withDefaults() Options {
+	if len(opts.Colours) == 0 {
+		opts.Colours = plotutil.DefaultColors
+	}
+	if opts.Radius == 0 {
+		opts.Radius = plotter.DefaultGlyphStyle.Radius
+	}
+	return opts
+}
+
+// New will create a plot of the vectors of the dataset coloured by the cluster the clusterer assigns them to, see Add.
+func New(dataset *clustering.Dataset, clusterer clustering.SimpleFlatClusterer, opts Options) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	return p, Add(p, dataset, clusterer, opts)
+}
+
+// Add will add the vectors of the dataset to the plot coloured by the cluster the clusterer assigns them to, the vectors must be two-dimensional.
+// The first component of every vector is drawn along the X axis, the second along the Y axis, and vectors that are Noise are drawn in the NoiseColour.
+func Add(p *plot.Plot, dataset *clustering.Dataset, clusterer clustering.SimpleFlatClusterer, opts Options) error {
+	opts = opts.withDefaults()
+	vectors := dataset.Vectors()
+	for i, vec := range vectors {
+		if clustering.Dimension(vec) != 2 {
+			return fmt.Errorf("Expected two-dimensional vectors but vector %d has dimension %d", i, clustering.Dimension(vec))
+		}
+	}
+	assignments, err := clustering.FindClusters(clusterer, vectors)
+	if err != nil {
+		return err
+	}
+	colours := make(map[clustering.Cluster]color.Color)
+	clusters := clusterer.Clusters()
+	for i, cluster := range clusters {
+		colours[cluster] = opts.Colours[i%len(opts.Colours)]
+	}
+	partition := make(map[clustering.Cluster]plotter.XYs)
+	for i, cluster := range assignments {
+		if _, ok := partition[cluster]; !ok {
+			if _, ok := colours[cluster]; !ok {
+				if cluster != clustering.Noise {
+					colours[cluster] = opts.Colours[len(colours)%len(opts.Colours)]
+				}
+				clusters = append(clusters, cluster)
+			}
+		}
+		partition[cluster] = append(partition[cluster], plotter.XY{X: clustering.Component(vectors[i], 0), Y: clustering.Component(vectors[i], 1)})
+	}
+	colours[clustering.Noise] = NoiseColour
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i] < clusters[j] })
+
+	centroids := make(map[clustering.Cluster]plotter.XY)
+	if fitted, ok := clusterer.(*clustering.CentroidClusterer); ok {
+		for cluster, centroid := range fitted.Centroids() {
+			centroids[cluster] = plotter.XY{X: clustering.Component(centroid, 0), Y: clustering.Component(centroid, 1)}
+		}
+	} else {
+		for cluster, xys := range partition {
+			var centroid plotter.XY
+			for _, xy := range xys {
+				centroid.X, centroid.Y = centroid.X+xy.X/float64(len(xys)), centroid.Y+xy.Y/float64(len(xys))
+			}
+			centroids[cluster] = centroid
+		}
+	}
+
+	if fitted, ok := clusterer.(*clustering.CentroidClusterer); ok && opts.Cells && len(*fitted) > 0 {
+		if err := addCells(p, *fitted, vectors, colours); err != nil {
+			return err
+		}
+	}
+
+	for _, cluster := range clusters {
+		xys := partition[cluster]
+		if opts.Hulls && cluster != clustering.Noise && len(xys) > 2 {
+			hull, err := plotter.NewPolygon(ConvexHull(xys))
+			if err != nil {
+				return err
+			}
+			r, g, b, _ := colours[cluster].RGBA()
+			hull.Color = color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 48}
+			hull.LineStyle.Color = colours[cluster]
+			p.Add(hull)
+		}
+		if len(xys) == 0 {
+			continue
+		}
+		scatter, err := plotter.NewScatter(xys)
+		if err != nil {
+			return err
+		}
+		scatter.GlyphStyle = draw.GlyphStyle{Color: colours[cluster], Radius: opts.Radius, Shape: draw.CircleGlyph{}}
+		p.Add(scatter)
+		if opts.Legend {
+			name := fmt.Sprintf("cluster %d", cluster)
+			if cluster == clustering.Noise {
+				name = "noise"
+			}
+			p.Legend.Add(name, scatter)
+		}
+	}
+	if opts.Centroids {
+		for _, cluster := range clusters {
+			centroid, ok := centroids[cluster]
+			if !ok || cluster == clustering.Noise {
+				continue
+			}
+			marker, err := plotter.NewScatter(plotter.XYs{centroid})
+			if err != nil {
+				return err
+			}
+			marker.GlyphStyle = draw.GlyphStyle{Color: colours[cluster], Radius: 2 * opts.Radius, Shape: draw.PyramidGlyph{}}
+			p.Add(marker)
+		}
+	}
+	return nil
+}
+
+// addCells draws the outline of the Voronoi cell of every centroid, clipped to the bounding box of the vectors and centroids widened on every side by 5% of its largest side.
+func addCells(p *plot.Plot, centroids clustering.CentroidClusterer, vectors []clustering.Vector, colours map[clustering.Cluster]color.Color) error {
+	bounds := clustering.Rect{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, vec := range append(append([]clustering.Vector(nil), vectors...), centroids...) {
+		bounds.MinX, bounds.MaxX = math.Min(bounds.MinX, clustering.Component(vec, 0)), math.Max(bounds.MaxX, clustering.Component(vec, 0))
+		bounds.MinY, bounds.MaxY = math.Min(bounds.MinY, clustering.Component(vec, 1)), math.Max(bounds.MaxY, clustering.Component(vec, 1))
+	}
+	pad := 0.05 * math.Max(bounds.MaxX-bounds.MinX, bounds.MaxY-bounds.MinY)
+	if pad == 0 {
+		pad = 1
+	}
+	bounds = clustering.Rect{MinX: bounds.MinX - pad, MinY: bounds.MinY - pad, MaxX: bounds.MaxX + pad, MaxY: bounds.MaxY + pad}
+	cells, err := centroids.Voronoi(bounds)
+	if err != nil {
+		return err
+	}
+	for cluster, cell := range cells {
+		if len(cell) == 0 {
+			continue
+		}
+		outline := make(plotter.XYs, len(cell))
+		for i, vertex := range cell {
+			outline[i] = plotter.XY{X: vertex[0], Y: vertex[1]}
+		}
+		polygon, err := plotter.NewPolygon(outline)
+		if err != nil {
+			return err
+		}
+		polygon.Color = nil
+		polygon.LineStyle.Color = colours[clustering.Cluster(cluster)]
+		polygon.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+		p.Add(polygon)
+	}
+	return nil
+}
+
+// ConvexHull will return the vertices of the convex hull of the points in counter-clockwise order (Andrew's monotone chain),
+// collinear points on the boundary of the hull are left out.
+func ConvexHull(points plotter.XYs) plotter.XYs {
+	sorted := append(plotter.XYs(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].X < sorted[j].X || (sorted[i].X == sorted[j].X && sorted[i].Y < sorted[j].Y)
+	})
+	if len(sorted) < 3 {
+		return sorted
+	}
+	cross := func(o, a, b plotter.XY) float64 {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+	hull := make(plotter.XYs, 0, 2*len(sorted))
+	for _, point := range sorted {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], point) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, point)
+	}
+	for i, lower := len(sorted)-2, len(hull)+1; i >= 0; i-- {
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], sorted[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, sorted[i])
+	}
+	return hull[:len(hull)-1]
+}
@@ -0,0 +1,53 @@
+package clusterplot
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/frederikdesmedt/clustering"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// NewDendrogram will create a plot of the dendrogram with the leaves along the X axis, in the order of a depth-first traversal from the root, and the heights of the merges along the Y axis.
+// The `i`th leaf is labelled `labels[i]`, or its index if the labels are nil. Save the plot with an `.svg` extension to render the dendrogram as SVG.
+func NewDendrogram(dendrogram clustering.Dendrogram, labels []string) (*plot.Plot, error) {
+	n := dendrogram.Size()
+	if labels != nil && len(labels) != n {
+		return nil, fmt.Errorf("Expected %d labels but got %d", n, len(labels))
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Y.Label.Text = "height"
+
+	// Every leaf is placed at its position in the traversal, every other node halfway between its children.
+	positions := make([]float64, 2*n-1)
+	ticks := make([]plot.Tick, 0, n)
+	for i, leaf := range dendrogram.Leaves(dendrogram.Root()) {
+		positions[leaf] = float64(i)
+		label := strconv.Itoa(leaf)
+		if labels != nil {
+			label = labels[leaf]
+		}
+		ticks = append(ticks, plot.Tick{Value: float64(i), Label: label})
+	}
+	for i, merge := range dendrogram.Merges() {
+		positions[n+i] = (positions[merge.A] + positions[merge.B]) / 2
+		u, err := plotter.NewLine(plotter.XYs{
+			{X: positions[merge.A], Y: dendrogram.Height(merge.A)},
+			{X: positions[merge.A], Y: merge.Height},
+			{X: positions[merge.B], Y: merge.Height},
+			{X: positions[merge.B], Y: dendrogram.Height(merge.B)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		p.Add(u)
+	}
+	p.X.Tick.Marker = plot.ConstantTicks(ticks)
+	p.X.Min, p.X.Max = -0.5, float64(n)-0.5
+	p.Y.Min, p.Y.Max = 0, 1.05*dendrogram.Height(dendrogram.Root())
+	return p, nil
+}
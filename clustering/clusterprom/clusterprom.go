@@ -0,0 +1,77 @@
+// Package clusterprom exports the measurements of clustering.Instrumentation as Prometheus metrics, e.g., to monitor the clustering jobs of a service.
+package clusterprom
+
+import (
+	"time"
+
+	"github.com/frederikdesmedt/clustering"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Instrumentation is a clustering.Instrumentation and a prometheus.Collector counting the iterations, distance computations and assigned vectors of every algorithm
+// and observing the latency of its assignments, every metric labelled with the name of the algorithm.
+type Instrumentation struct {
+	iterations  *prometheus.CounterVec
+	distances   *prometheus.CounterVec
+	vectors     *prometheus.CounterVec
+	assignments *prometheus.HistogramVec
+}
+
+var _ clustering.Instrumentation = (*Instrumentation)(nil)
+
+// New will create the Instrumentation of metrics named `<namespace>_clustering_...`, e.g., `myservice_clustering_iterations_total`, or `clustering_...` without a namespace.
+// It must be registered with a prometheus.Registerer for its metrics to be collected.
+func New(namespace string) *Instrumentation {
+	labels := []string{"algorithm"}
+	return &Instrumentation{
+		iterations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "clustering", Name: "iterations_total",
+			Help: "Number of iterations of iterative clustering algorithms.",
+		}, labels),
+		distances: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "clustering", Name: "distance_computations_total",
+			Help: "Number of distances computed by clustering algorithms.",
+		}, labels),
+		vectors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "clustering", Name: "assigned_vectors_total",
+			Help: "Number of vectors assigned to clusters.",
+		}, labels),
+		assignments: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "clustering", Name: "assignment_duration_seconds",
+			Help:    "Latency of assigning a batch of vectors to clusters.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, labels),
+	}
+}
+
+// Iteration will count the iteration of the algorithm.
+func (instrumentation *Instrumentation) Iteration(algorithm string, iteration int) {
+	instrumentation.iterations.WithLabelValues(algorithm).Inc()
+}
+
+// Distances will count the distances the algorithm computed.
+func (instrumentation *Instrumentation) Distances(algorithm string, count int) {
+	instrumentation.distances.WithLabelValues(algorithm).Add(float64(count))
+}
+
+// Assignment will count the vectors the algorithm assigned and observe the latency of the assignment.
+func (instrumentation *Instrumentation) Assignment(algorithm string, vectors int, elapsed time.Duration) {
+	instrumentation.vectors.WithLabelValues(algorithm).Add(float64(vectors))
+	instrumentation.assignments.WithLabelValues(algorithm).Observe(elapsed.Seconds())
+}
+
+// Describe sends the descriptors of all metrics to the channel.
+func (instrumentation *Instrumentation) Describe(descriptors chan<- *prometheus.Desc) {
+	instrumentation.iterations.Describe(descriptors)
+	instrumentation.distances.Describe(descriptors)
+	instrumentation.vectors.Describe(descriptors)
+	instrumentation.assignments.Describe(descriptors)
+}
+
+// Collect sends the current value of all metrics to the channel.
+func (instrumentation *Instrumentation) Collect(metrics chan<- prometheus.Metric) {
+	instrumentation.iterations.Collect(metrics)
+	instrumentation.distances.Collect(metrics)
+	instrumentation.vectors.Collect(metrics)
+	instrumentation.assignments.Collect(metrics)
+}
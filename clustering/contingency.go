@@ -0,0 +1,127 @@
+package clustering
+
+import (
+	"math"
+	"sort"
+)
+
+// Contingency is the table counting the elements in every pair of a cluster and a ground-truth label, together with the best one-to-one matching between them.
+type Contingency struct {
+	// Clusters holds the clusters of the rows in increasing order.
+	Clusters []Cluster
+	// Labels holds the labels of the columns in increasing order.
+	Labels []Cluster
+	// Counts holds the number of elements of cluster `Clusters[i]` with label `Labels[j]` at `Counts[i][j]`.
+	Counts [][]int
+	// Matching maps the clusters onto distinct labels such that as many elements as possible have the label of their cluster (Hungarian algorithm).
+	// When there are more clusters than labels, the clusters without a label are not in the matching.
+	Matching map[Cluster]Cluster
+	// Accuracy is the fraction of elements that have the label matched with their cluster.
+	Accuracy float64
+}
+
+// ContingencyTable will count the elements in every pair of a cluster and a ground-truth label, where `clusters[i]` is the cluster, e.g., from Assignments,
+// and `labels[i]` the label of the `i`th element, and match the clusters with the labels.
+func ContingencyTable(clusters, labels []Cluster) (Contingency, error) {
+	if _, _, _, err := contingency(clusters, labels); err != nil {
+		return Contingency{}, err
+	}
+	table := Contingency{Clusters: sortedClusters(clusters), Labels: sortedClusters(labels), Matching: make(map[Cluster]Cluster)}
+	rows, columns := clusterIndices(table.Clusters), clusterIndices(table.Labels)
+	table.Counts = make([][]int, len(table.Clusters))
+	for i := range table.Counts {
+		table.Counts[i] = make([]int, len(table.Labels))
+	}
+	for i := range clusters {
+		table.Counts[rows[clusters[i]]][columns[labels[i]]]++
+	}
+
+	// Maximizing the matched counts is minimizing the matched deficits of a square table padded with zeros.
+	size := max(len(table.Clusters), len(table.Labels))
+	costs := newMatrix(size, size)
+	for i, row := range table.Counts {
+		for j, count := range row {
+			costs[i][j] = -float64(count)
+		}
+	}
+	matched := 0
+	for i, j := range hungarian(costs) {
+		if i < len(table.Clusters) && j < len(table.Labels) {
+			table.Matching[table.Clusters[i]] = table.Labels[j]
+			matched += table.Counts[i][j]
+		}
+	}
+	table.Accuracy = float64(matched) / float64(len(clusters))
+	return table, nil
+}
+
+func sortedClusters(assignments []Cluster) []Cluster {
+	seen := make(map[Cluster]bool)
+	var distinct []Cluster
+	for _, cluster := range assignments {
+		if !seen[cluster] {
+			seen[cluster] = true
+			distinct = append(distinct, cluster)
+		}
+	}
+	sort.Slice(distinct, func(a, b int) bool { return distinct[a] < distinct[b] })
+	return distinct
+}
+
+func clusterIndices(clusters []Cluster) map[Cluster]int {
+	indices := make(map[Cluster]int, len(clusters))
+	for i, cluster := range clusters {
+		indices[cluster] = i
+	}
+	return indices
+}
+
+// hungarian solves the assignment problem for the square cost matrix, returning the column assigned to every row such that the total cost is minimal.
+func hungarian(costs matrix) []int {
+	n := len(costs)
+	// The potentials u and v of the rows and columns and the matched row of every column are 1-based, column 0 is a sentinel.
+	u, v := make([]float64, n+1), make([]float64, n+1)
+	rowOf, way := make([]int, n+1), make([]int, n+1)
+	for row := 1; row <= n; row++ {
+		rowOf[0] = row
+		column := 0
+		slack, used := make([]float64, n+1), make([]bool, n+1)
+		for j := range slack {
+			slack[j] = math.Inf(1)
+		}
+		for rowOf[column] != 0 {
+			used[column] = true
+			current, delta, next := rowOf[column], math.Inf(1), 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if reduced := costs[current-1][j-1] - u[current] - v[j]; reduced < slack[j] {
+					slack[j], way[j] = reduced, column
+				}
+				if slack[j] < delta {
+					delta, next = slack[j], j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[rowOf[j]] += delta
+					v[j] -= delta
+				} else {
+					slack[j] -= delta
+				}
+			}
+			column = next
+		}
+		for column != 0 {
+			previous := way[column]
+			rowOf[column] = rowOf[previous]
+			column = previous
+		}
+	}
+	assignment := make([]int, n)
+	for j := 1; j <= n; j++ {
+		assignment[rowOf[j]-1] = j - 1
+	}
+	return assignment
+}
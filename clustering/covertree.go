@@ -0,0 +1,155 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CoverTree is a NeighbourIndex under any metric satisfying the triangle inequality (Beygelzimer, Kakade and Langford),
+// whose query time grows only logarithmically with the size of the dataset for data of a low intrinsic dimension.
+// Every node on level `l` covers its children within distance `2^l`, which are on level `l-1`. The tree does not follow later changes to the dataset.
+type CoverTree struct {
+	data   []Vector
+	metric Metric
+	root   *coverNode
+}
+
+// coverNode is a vector of a CoverTree together with the vectors that coincide with it.
+type coverNode struct {
+	index      int
+	duplicates []int
+	level      int
+	children   []*coverNode
+	// maxDistance is the largest distance between the vector and any of its descendants.
+	maxDistance float64
+}
+
+// coverDistance returns the distance within which the node covers its children.
+func (node *coverNode) coverDistance() float64 {
+	return math.Ldexp(1, node.level)
+}
+
+// NewCoverTree will build a CoverTree over the vectors of the dataset with distances measured by the metric, inserting the vectors one at a time (Izbicki and Shelton).
+// It panics when a distance is infinite or NaN, e.g., for vectors with such components, as no level covers such a vector.
+func NewCoverTree(dataset *Dataset, metric Metric) *CoverTree {
+	tree := &CoverTree{data: dataset.data, metric: metric}
+	for i := range dataset.data {
+		tree.insert(i)
+	}
+	if tree.root != nil {
+		tree.measure(tree.root)
+	}
+	return tree
+}
+
+func (tree *CoverTree) distance(node *coverNode, i int) float64 {
+	distance := tree.metric(tree.data[node.index], tree.data[i])
+	if math.IsInf(distance, 0) || math.IsNaN(distance) {
+		panic(fmt.Sprintf("Expected finite distances but the distance between vectors %d and %d is %v", node.index, i, distance))
+	}
+	return distance
+}
+
+func (tree *CoverTree) insert(i int) {
+	if tree.root == nil {
+		tree.root = &coverNode{index: i}
+		return
+	}
+	distance := tree.distance(tree.root, i)
+	if distance <= tree.root.coverDistance() {
+		tree.insertBelow(tree.root, i, distance)
+		return
+	}
+	// The root is raised by making any of its leaves the parent of the root, which covers all descendants as these are within twice the cover distance of the root.
+	for distance > 2*tree.root.coverDistance() {
+		if len(tree.root.children) == 0 {
+			tree.root.level = int(math.Ceil(math.Log2(distance))) - 1
+			continue
+		}
+		parent, leaf := tree.root, tree.root.children[len(tree.root.children)-1]
+		for len(leaf.children) > 0 {
+			parent, leaf = leaf, leaf.children[len(leaf.children)-1]
+		}
+		parent.children = parent.children[:len(parent.children)-1]
+		leaf.level, leaf.children = tree.root.level+1, []*coverNode{tree.root}
+		tree.root = leaf
+		distance = tree.distance(tree.root, i)
+	}
+	tree.root = &coverNode{index: i, level: tree.root.level + 1, children: []*coverNode{tree.root}}
+}
+
+// insertBelow inserts the `i`th vector as a descendant of the node, which covers it at the provided distance.
+func (tree *CoverTree) insertBelow(node *coverNode, i int, distance float64) {
+	for distance > 0 {
+		var next *coverNode
+		for _, child := range node.children {
+			if childDistance := tree.distance(child, i); childDistance <= child.coverDistance() {
+				next, distance = child, childDistance
+				break
+			}
+		}
+		if next == nil {
+			node.children = append(node.children, &coverNode{index: i, level: node.level - 1})
+			return
+		}
+		node = next
+	}
+	node.duplicates = append(node.duplicates, i)
+}
+
+// measure computes the largest distance between every node and its descendants, returning the vectors of the subtree.
+func (tree *CoverTree) measure(node *coverNode) []int {
+	descendants := []int{}
+	for _, child := range node.children {
+		descendants = append(descendants, tree.measure(child)...)
+	}
+	node.maxDistance = 0
+	for _, i := range descendants {
+		node.maxDistance = max(node.maxDistance, tree.distance(node, i))
+	}
+	descendants = append(descendants, node.index)
+	return append(descendants, node.duplicates...)
+}
+
+// search visits every vector of the subtree that may be within the bound of the query, given the distance between the query and the node.
+func (tree *CoverTree) search(node *coverNode, query Vector, distance float64, visit func(i int, distance float64), bound func() float64) {
+	visit(node.index, distance)
+	for _, i := range node.duplicates {
+		visit(i, distance)
+	}
+	children := make([]Neighbour, len(node.children))
+	for c, child := range node.children {
+		children[c] = Neighbour{Index: c, Distance: tree.metric(query, tree.data[child.index])}
+	}
+	sort.Slice(children, func(a, b int) bool { return children[a].Distance < children[b].Distance })
+	for _, child := range children {
+		if child.Distance-node.children[child.Index].maxDistance <= bound() {
+			tree.search(node.children[child.Index], query, child.Distance, visit, bound)
+		}
+	}
+}
+
+// Nearest returns the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+func (tree *CoverTree) Nearest(query Vector, k int) []Neighbour {
+	nearest := &nearestNeighbours{k: max(k, 0)}
+	if tree.root != nil {
+		tree.search(tree.root, query, tree.metric(query, tree.data[tree.root.index]), nearest.offer, nearest.bound)
+	}
+	return nearest.sorted()
+}
+
+// WithinRadius returns the vectors within distance `radius` of the query in increasing order of distance.
+func (tree *CoverTree) WithinRadius(query Vector, radius float64) []Neighbour {
+	var within []Neighbour
+	if tree.root == nil || radius < 0 || math.IsNaN(radius) {
+		return within
+	}
+	tree.search(tree.root, query, tree.metric(query, tree.data[tree.root.index]), func(i int, distance float64) {
+		if distance <= radius {
+			within = append(within, Neighbour{Index: i, Distance: distance})
+		}
+	}, func() float64 { return radius })
+	sortNeighbours(within)
+	return within
+}
@@ -0,0 +1,198 @@
+package clustering
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVOptions configures how datasets are read from and written to CSV.
+type CSVOptions struct {
+	// Delimiter separates the fields of a record, a comma is used when it is not set.
+	Delimiter rune
+	// Comment marks lines that should be ignored when loading if they start with it, comments are not allowed when it is not set.
+	Comment rune
+	// Header indicates that the first record holds the names of the columns instead of data.
+	Header bool
+	// Columns selects the columns, by index, that make up the components of the vectors when loading.
+	// When neither Columns nor ColumnNames are set every column is used.
+	Columns []int
+	// ColumnNames selects the columns, by their name in the header, that make up the components of the vectors when loading.
+	// When writing, ColumnNames are the names of the components in the header.
+	ColumnNames []string
+}
+
+// LoadCSV will parse the selected numeric columns of every record into a VectorN and return the resulting dataset.
+func LoadCSV(r io.Reader, opts CSVOptions) (Dataset, error) {
+	source := NewCSVSource(r, opts)
+	var data []Vector
+	for vec, ok := source.Next(); ok; vec, ok = source.Next() {
+		data = append(data, vec)
+	}
+	if err := source.Err(); err != nil {
+		return Dataset{}, err
+	}
+	return CreateDataset(data, vectorNCreator(len(source.columns))), nil
+}
+
+// CSVSource is a DataSource parsing the selected numeric columns of CSV records into VectorNs one record at a time.
+type CSVSource struct {
+	reader  *csv.Reader
+	opts    CSVOptions
+	columns []int
+	header  []string
+	started bool
+	err     error
+}
+
+// NewCSVSource will create a DataSource reading VectorNs from the CSV records read from `r`, as configured by the options.
+func NewCSVSource(r io.Reader, opts CSVOptions) *CSVSource {
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.Comment = opts.Comment
+	reader.ReuseRecord = true
+	return &CSVSource{reader: reader, opts: opts, columns: append([]int(nil), opts.Columns...)}
+}
+
+// Next returns the vector parsed from the next record, or false when all records have been read or an error occurred.
+func (source *CSVSource) Next() (Vector, bool) {
+	if source.err != nil {
+		return nil, false
+	}
+	if !source.started {
+		source.started = true
+		if source.err = source.readHeader(); source.err != nil {
+			return nil, false
+		}
+	}
+
+	record, err := source.reader.Read()
+	if err != nil {
+		if err != io.EOF {
+			source.err = err
+		}
+		return nil, false
+	}
+	if source.columns == nil {
+		source.columns = make([]int, len(record))
+		for i := range source.columns {
+			source.columns[i] = i
+		}
+	}
+	line, _ := source.reader.FieldPos(0)
+	vec := make(VectorN, len(source.columns))
+	for i, column := range source.columns {
+		if column < 0 || column >= len(record) {
+			source.err = fmt.Errorf("Record on line %d has no column %d", line, column)
+			return nil, false
+		}
+		if vec[i], err = strconv.ParseFloat(record[column], 64); err != nil {
+			source.err = fmt.Errorf("Column %d on line %d is not numeric: %v", column, line, err)
+			return nil, false
+		}
+	}
+	return vec, true
+}
+
+// Err returns the error that ended reading, if any.
+func (source *CSVSource) Err() error {
+	return source.err
+}
+
+// ColumnNames returns the names in the header of the columns the components of the vectors are parsed from,
+// or nil if the CSV has no header or no vector has been read yet.
+func (source *CSVSource) ColumnNames() []string {
+	if source.header == nil {
+		return nil
+	}
+	if source.columns == nil {
+		return append([]string(nil), source.header...)
+	}
+	names := make([]string, len(source.columns))
+	for i, column := range source.columns {
+		if column >= 0 && column < len(source.header) {
+			names[i] = source.header[column]
+		}
+	}
+	return names
+}
+
+func (source *CSVSource) readHeader() error {
+	if len(source.opts.ColumnNames) > 0 && !source.opts.Header {
+		return errors.New("Columns can only be selected by name when the CSV has a header")
+	}
+	if !source.opts.Header {
+		return nil
+	}
+	header, err := source.reader.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	source.header = append([]string(nil), header...)
+	source.columns, err = selectNamedColumns(header, source.columns, source.opts.ColumnNames)
+	return err
+}
+
+func selectNamedColumns(header []string, columns []int, names []string) ([]int, error) {
+	indices := make(map[string]int)
+	for i, name := range header {
+		indices[name] = i
+	}
+	for _, name := range names {
+		index, ok := indices[name]
+		if !ok {
+			return nil, fmt.Errorf("The header has no column named %q", name)
+		}
+		columns = append(columns, index)
+	}
+	return columns, nil
+}
+
+// WriteCSV will write every vector of the dataset as a record, followed by the cluster it was assigned to if assignments are provided.
+// When a header is requested it consists of the ColumnNames, or `x0`, `x1`, ... if those are not set, followed by `cluster`.
+func WriteCSV(w io.Writer, dataset *Dataset, assignments []Cluster, opts CSVOptions) error {
+	if assignments != nil && len(assignments) != dataset.Count() {
+		return fmt.Errorf("Expected %d assignments but got %d", dataset.Count(), len(assignments))
+	}
+	writer := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		writer.Comma = opts.Delimiter
+	}
+
+	dimension := Dimension(dataset.creator.Null())
+	if opts.Header {
+		header := append([]string(nil), opts.ColumnNames...)
+		if len(header) == 0 {
+			for i := 0; i < dimension; i++ {
+				header = append(header, "x"+strconv.Itoa(i))
+			}
+		}
+		if assignments != nil {
+			header = append(header, "cluster")
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+
+	for i, vec := range dataset.data {
+		record := make([]string, 0, Dimension(vec)+1)
+		for j := 0; j < Dimension(vec); j++ {
+			record = append(record, strconv.FormatFloat(Component(vec, j), 'g', -1, 64))
+		}
+		if assignments != nil {
+			record = append(record, strconv.Itoa(int(assignments[i])))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,30 @@
+package clustering
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCSVSourceColumnNames(t *testing.T) {
+	input := "id,width,label,height\n1,2,a,3\n4,5,b,6\n"
+	tests := []struct {
+		input string
+		opts  CSVOptions
+		want  []string
+	}{
+		{input, CSVOptions{Header: true, ColumnNames: []string{"height", "width"}}, []string{"height", "width"}},
+		{input, CSVOptions{Header: true, Columns: []int{1, 3}}, []string{"width", "height"}},
+		{"x,y\n1,2\n", CSVOptions{Header: true}, []string{"x", "y"}},
+		{"1,2\n3,4\n", CSVOptions{}, nil},
+	}
+	for _, test := range tests {
+		source := NewCSVSource(strings.NewReader(test.input), test.opts)
+		if _, err := Collect(source, VectorN{}.Creator()); err != nil {
+			t.Fatal(err)
+		}
+		if names := source.ColumnNames(); fmt.Sprint(names) != fmt.Sprint(test.want) || (names == nil) != (test.want == nil) {
+			t.Errorf("Expected the columns %q for %+v but got %q", test.want, test.opts, names)
+		}
+	}
+}
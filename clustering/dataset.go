@@ -0,0 +1,313 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+// CreateWeightedDataset will create a dataset containing the provided data in which the `i`th vector has weight `weights[i]`,
+// e.g., to count every vector as if it occurred `weights[i]` times. Weight-aware algorithms such as K-Means and k-medoids use these weights automatically.
+func CreateWeightedDataset(data []Vector, weights []float64, creator VectorCreator) (Dataset, error) {
+	dataset := CreateDataset(data, creator)
+	if err := dataset.SetWeights(weights); err != nil {
+		return Dataset{}, err
+	}
+	return dataset, nil
+}
+
+// SetWeights will set the weight of every vector of this dataset, the weights must be non-negative and there must be one for every vector.
+// Nil weights make this dataset unweighted again, i.e., every vector gets weight 1.
+func (dataset *Dataset) SetWeights(weights []float64) error {
+	if weights == nil {
+		dataset.weights = nil
+		return nil
+	}
+	if len(weights) != dataset.Count() {
+		return fmt.Errorf("Expected %d weights but got %d", dataset.Count(), len(weights))
+	}
+	for i, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return fmt.Errorf("Expected finite, non-negative weights but weight %d is %v", i, weight)
+		}
+	}
+	dataset.weights = append([]float64(nil), weights...)
+	return nil
+}
+
+// IsWeighted returns true if and only if weights were set on this dataset.
+func (dataset *Dataset) IsWeighted() bool {
+	return dataset.weights != nil
+}
+
+// Weight will return the weight of the `i`th vector of this dataset, which is 1 if the dataset is not weighted.
+func (dataset *Dataset) Weight(i int) float64 {
+	if dataset.weights == nil {
+		return 1
+	}
+	return dataset.weights[i]
+}
+
+// Weights will return the weight of every vector of this dataset.
+func (dataset *Dataset) Weights() []float64 {
+	weights := make([]float64, dataset.Count())
+	for i := range weights {
+		weights[i] = dataset.Weight(i)
+	}
+	return weights
+}
+
+// TotalWeight will return the sum of the weights of all vectors of this dataset, which is its size if it is not weighted.
+func (dataset *Dataset) TotalWeight() float64 {
+	if dataset.weights == nil {
+		return float64(dataset.Count())
+	}
+	return sum(dataset.weights)
+}
+
+// subset returns a dataset holding the vectors, and their weights, at the provided indices.
+func (dataset *Dataset) subset(indices []int) Dataset {
+	data := dataset.data
+	result := Dataset{data: make([]Vector, len(indices)), creator: dataset.creator}
+	if dataset.weights != nil {
+		result.weights = make([]float64, len(indices))
+	}
+	for i, index := range indices {
+		result.data[i] = data[index]
+		if result.weights != nil {
+			result.weights[i] = dataset.weights[index]
+		}
+	}
+	return result
+}
+
+// Append will add the vectors to the end of this dataset, returning an error without changing the dataset if one of them does not belong to its vector space.
+// A dataset without a creator adopts the creator of the first vector.
+func (dataset *Dataset) Append(vecs ...Vector) error {
+	if dataset.creator == nil && len(vecs) > 0 {
+		dataset.creator = vecs[0].Creator()
+	}
+	for _, vec := range vecs {
+		if err := dataset.checkVector(vec); err != nil {
+			return err
+		}
+	}
+	dataset.data, dataset.index = append(dataset.data, vecs...), nil
+	if dataset.weights != nil {
+		for range vecs {
+			dataset.weights = append(dataset.weights, 1)
+		}
+	}
+	return nil
+}
+
+// AppendWeighted will add the vector with the provided weight to the end of this dataset, making the dataset weighted if it was not.
+func (dataset *Dataset) AppendWeighted(vec Vector, weight float64) error {
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return fmt.Errorf("Expected a finite, non-negative weight but got %v", weight)
+	}
+	weights := dataset.Weights()
+	if err := dataset.Append(vec); err != nil {
+		return err
+	}
+	dataset.weights = append(weights, weight)
+	return nil
+}
+
+// Remove will remove the `i`th vector from this dataset, shifting the subsequent vectors one position to the front.
+// The vectors are copied into new storage, so datasets sharing the storage of this dataset are left as they were.
+func (dataset *Dataset) Remove(i int) error {
+	if err := dataset.checkIndex(i); err != nil {
+		return err
+	}
+	dataset.data, dataset.index = append(append(make([]Vector, 0, len(dataset.data)-1), dataset.data[:i]...), dataset.data[i+1:]...), nil
+	if dataset.weights != nil {
+		dataset.weights = append(append(make([]float64, 0, len(dataset.weights)-1), dataset.weights[:i]...), dataset.weights[i+1:]...)
+	}
+	return nil
+}
+
+// Replace will replace the `i`th vector of this dataset by the provided vector, which must belong to the vector space of this dataset.
+// The replacement keeps the weight of the replaced vector.
+func (dataset *Dataset) Replace(i int, vec Vector) error {
+	if err := dataset.checkIndex(i); err != nil {
+		return err
+	}
+	if err := dataset.checkVector(vec); err != nil {
+		return err
+	}
+	dataset.data[i], dataset.index = vec, nil
+	return nil
+}
+
+// Concat will return a dataset holding the vectors of `a` followed by the vectors of `b`, e.g., to combine datasets loaded from multiple files or shards.
+// Both datasets must belong to the same vector space, a dataset without a creator is compatible with any dataset.
+// The concatenated dataset is weighted if either dataset is weighted.
+func Concat(a, b Dataset) (Dataset, error) {
+	if a.creator == nil {
+		a.creator = b.creator
+	} else if b.creator != nil {
+		if err := a.checkVector(b.creator.Null()); err != nil {
+			return Dataset{}, fmt.Errorf("Could not concatenate datasets: %v", err)
+		}
+	}
+	concatenated := Dataset{data: make([]Vector, 0, a.Count()+b.Count()), creator: a.creator}
+	concatenated.data = append(append(concatenated.data, a.data...), b.data...)
+	if a.IsWeighted() || b.IsWeighted() {
+		concatenated.weights = append(a.Weights(), b.Weights()...)
+	}
+	return concatenated, nil
+}
+
+// Filter will return a dataset holding the vectors of this dataset satisfying the predicate, in order.
+func (dataset *Dataset) Filter(pred func(Vector) bool) Dataset {
+	var selected []int
+	for i, vec := range dataset.data {
+		if pred(vec) {
+			selected = append(selected, i)
+		}
+	}
+	return dataset.subset(selected)
+}
+
+// Map will return a dataset holding every vector of this dataset mapped by `f`, in order.
+// The mapped vectors may belong to another vector space, as long as they all belong to the same one.
+func (dataset *Dataset) Map(f func(Vector) Vector) Dataset {
+	mapped := make([]Vector, dataset.Count())
+	for i, vec := range dataset.data {
+		mapped[i] = f(vec)
+	}
+	creator := dataset.creator
+	if len(mapped) > 0 {
+		creator = mapped[0].Creator()
+	}
+	result := Dataset{data: mapped, creator: creator}
+	if dataset.weights != nil {
+		result.weights = append([]float64(nil), dataset.weights...)
+	}
+	return result
+}
+
+// Sample will return a uniform random subsample of `n` vectors of this dataset, drawn without replacement and kept in their original order.
+// The entire dataset is returned when it has no more than `n` vectors. Sample panics if `n` is negative.
+func (dataset *Dataset) Sample(n int, src rand.Source) Dataset {
+	if n < 0 {
+		panic(fmt.Sprintf("Expected a non-negative sample size but got %d", n))
+	}
+	return dataset.subset(sampleIndices(dataset.Count(), n, rand.New(src)))
+}
+
+// Shuffle will return a dataset with the vectors of this dataset in a uniformly random order determined by the source, leaving this dataset untouched.
+func (dataset *Dataset) Shuffle(src rand.Source) Dataset {
+	return dataset.subset(rand.New(src).Perm(dataset.Count()))
+}
+
+// Split will randomly divide the vectors of this dataset into two datasets, the first holding a fraction `frac` of the vectors and the second holding the rest,
+// e.g., to fit a clusterer on one part and evaluate it on the held-out part. Both datasets keep the original order of their vectors.
+// Split panics if `frac` is not between 0 and 1.
+func (dataset *Dataset) Split(frac float64, src rand.Source) (Dataset, Dataset) {
+	if frac < 0 || frac > 1 || math.IsNaN(frac) {
+		panic(fmt.Sprintf("Expected a fraction between 0 and 1 but got %v", frac))
+	}
+	n := dataset.Count()
+	first := sampleIndices(n, int(math.Round(frac*float64(n))), rand.New(src))
+	second := make([]int, 0, n-len(first))
+	for i, next := 0, 0; i < n; i++ {
+		if next < len(first) && first[next] == i {
+			next++
+		} else {
+			second = append(second, i)
+		}
+	}
+	return dataset.subset(first), dataset.subset(second)
+}
+
+// StratifiedSample will return a random subsample of `n` vectors of this dataset in which every group is represented proportionally to its size,
+// where `labels[i]` is the group of the `i`th vector. Within every group the vectors are drawn uniformly without replacement.
+// The entire dataset is returned when it has no more than `n` vectors.
+func (dataset *Dataset) StratifiedSample(n int, labels []Cluster, src rand.Source) (Dataset, error) {
+	if n < 0 {
+		return Dataset{}, fmt.Errorf("Expected a non-negative sample size but got %d", n)
+	}
+	if len(labels) != dataset.Count() {
+		return Dataset{}, fmt.Errorf("Expected %d labels but got %d", dataset.Count(), len(labels))
+	}
+	groups := make(map[Cluster][]int)
+	for i, label := range labels {
+		groups[label] = append(groups[label], i)
+	}
+	order := make([]Cluster, 0, len(groups))
+	for label := range groups {
+		order = append(order, label)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	// Every group gets the integer part of its share, the remaining vectors go to the groups with the largest fractional parts.
+	n = min(n, len(labels))
+	sizes, remainders, assigned := make([]int, len(order)), make([]float64, len(order)), 0
+	for i, label := range order {
+		share := float64(n) * float64(len(groups[label])) / float64(len(labels))
+		sizes[i] = int(math.Floor(share))
+		remainders[i] = share - float64(sizes[i])
+		assigned += sizes[i]
+	}
+	byRemainder := make([]int, len(order))
+	for i := range byRemainder {
+		byRemainder[i] = i
+	}
+	sort.SliceStable(byRemainder, func(i, j int) bool { return remainders[byRemainder[i]] > remainders[byRemainder[j]] })
+	for _, i := range byRemainder[:n-assigned] {
+		sizes[i]++
+	}
+
+	rng := rand.New(src)
+	var selected []int
+	for i, label := range order {
+		for _, j := range sampleIndices(len(groups[label]), sizes[i], rng) {
+			selected = append(selected, groups[label][j])
+		}
+	}
+	sort.Ints(selected)
+	return dataset.subset(selected), nil
+}
+
+// StratifiedSampleByClusters will return a random subsample of `n` vectors of this dataset in which every cluster of the clusterer is represented proportionally to its size.
+func (dataset *Dataset) StratifiedSampleByClusters(n int, clusterer SimpleFlatClusterer, src rand.Source) (Dataset, error) {
+	labels, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return Dataset{}, err
+	}
+	return dataset.StratifiedSample(n, labels, src)
+}
+
+// sampleIndices selects `n` of the indices 0 up to `total` uniformly at random without replacement, in increasing order (Knuth's algorithm S).
+func sampleIndices(total, n int, rng *rand.Rand) []int {
+	indices := make([]int, 0, min(n, total))
+	for i := 0; i < total && len(indices) < n; i++ {
+		if rng.Intn(total-i) < n-len(indices) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func (dataset *Dataset) checkIndex(i int) error {
+	if i < 0 || i >= dataset.Count() {
+		return fmt.Errorf("Index %d is out of range for a dataset of %d vectors", i, dataset.Count())
+	}
+	return nil
+}
+
+// checkVector returns an error if the vector is not of the same type and dimension as the vectors created by the creator of this dataset.
+func (dataset *Dataset) checkVector(vec Vector) error {
+	null := dataset.creator.Null()
+	if reflect.TypeOf(vec) != reflect.TypeOf(null) {
+		return fmt.Errorf("Expected a %v but got a %v", reflect.TypeOf(null), reflect.TypeOf(vec))
+	}
+	if Dimension(vec) != Dimension(null) {
+		return fmt.Errorf("Expected a vector of dimension %d but got dimension %d", Dimension(null), Dimension(vec))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package clustering
+
+import "fmt"
+
+// Noise is the cluster of elements that do not belong to any cluster, e.g., the outliers found by DBSCAN.
+const Noise Cluster = -1
+
+// DBSCANFromDistances will perform DBSCAN clustering on the elements of the distance matrix and return the cluster of every element.
+// Elements with at least `minPoints` elements (themselves included) within distance `eps` are core elements, clusters are formed by
+// core elements within distance `eps` of each other together with the elements within reach of those core elements, all other elements are Noise.
+func DBSCANFromDistances(matrix DistanceMatrix, eps float64, minPoints int) ([]Cluster, error) {
+	if err := checkDistanceMatrix(matrix); err != nil {
+		return nil, err
+	}
+	if err := checkDBSCANParameters(eps, minPoints); err != nil {
+		return nil, err
+	}
+	n := matrix.Size()
+	neighbours := func(i int) []int {
+		var result []int
+		for j := 0; j < n; j++ {
+			if matrix.Distance(i, j) <= eps {
+				result = append(result, j)
+			}
+		}
+		return result
+	}
+	assignments := dbscan(n, neighbours, minPoints)
+	return assignments, distanceMatrixErr(matrix)
+}
+
+// DBSCAN will perform DBSCAN clustering, as in DBSCANFromDistances, on the vectors of this dataset using WithinRadius queries,
+// which are answered by the attached NeighbourIndex if there is one and by measuring all Euclidean distances otherwise.
+// Attaching an index, e.g., a KDTree, reduces the cost of every query from linear to roughly logarithmic in the size of the dataset.
+func (dataset *Dataset) DBSCAN(eps float64, minPoints int) ([]Cluster, error) {
+	if err := checkDBSCANParameters(eps, minPoints); err != nil {
+		return nil, err
+	}
+	neighbours := func(i int) []int {
+		return neighbourIndices(dataset.WithinRadius(dataset.data[i], eps))
+	}
+	return dbscan(dataset.Count(), neighbours, minPoints), nil
+}
+
+// neighbourIndices returns the indices of the neighbours.
+func neighbourIndices(neighbours []Neighbour) []int {
+	indices := make([]int, len(neighbours))
+	for i, neighbour := range neighbours {
+		indices[i] = neighbour.Index
+	}
+	return indices
+}
+
+func checkDBSCANParameters(eps float64, minPoints int) error {
+	if eps < 0 {
+		return fmt.Errorf("Expected a non-negative eps but got %v", eps)
+	}
+	if minPoints <= 0 {
+		return fmt.Errorf("Expected a positive minimum number of points but got %d", minPoints)
+	}
+	return nil
+}
+
+// dbscan clusters `n` elements given a function returning the indices of the elements within distance `eps` of an element, itself included.
+func dbscan(n int, neighbours func(i int) []int, minPoints int) []Cluster {
+	assignments := make([]Cluster, n)
+	visited := make([]bool, n)
+	for i := range assignments {
+		assignments[i] = Noise
+	}
+
+	cluster := Cluster(0)
+	for i := 0; i < n; i++ {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		queue := neighbours(i)
+		if len(queue) < minPoints {
+			continue
+		}
+
+		assignments[i] = cluster
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			if assignments[j] == Noise {
+				assignments[j] = cluster
+			}
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if reachable := neighbours(j); len(reachable) >= minPoints {
+				queue = append(queue, reachable...)
+			}
+		}
+		cluster++
+	}
+	return assignments
+}
@@ -0,0 +1,77 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+)
+
+// Deduplicate will collapse vectors within Euclidean distance `tol` of each other into a single weighted vector, shrinking datasets with many (near-)duplicates.
+// Every vector is merged into the earliest representative within distance `tol`, if any, where the representatives are the vectors that were not merged themselves,
+// so a vector may remain even though an earlier vector that was merged lies within the tolerance. The weight of every representative in the returned dataset
+// is the total weight of the vectors merged into it. A tolerance of 0 only merges exact duplicates.
+func (dataset *Dataset) Deduplicate(tol float64) Dataset {
+	if tol < 0 || math.IsNaN(tol) {
+		panic(fmt.Sprintf("Expected a non-negative tolerance but got %v", tol))
+	}
+	var unique []Vector
+	var weights []float64
+	// Representatives are bucketed by the grid cell of their first few components, such that only neighbouring cells need to be searched.
+	buckets := make(map[[3]int64][]int)
+	for i, vec := range dataset.data {
+		cell := dedupCell(vec, tol)
+		// The earliest representative within the tolerance is searched in all neighbouring cells, as the cells are not visited in order of their representatives.
+		first := -1
+		forEachNeighbourCell(cell, min(3, Dimension(vec)), tol > 0, func(neighbour [3]int64) {
+			for _, representative := range buckets[neighbour] {
+				if first >= 0 && representative >= first {
+					break
+				}
+				if Euclidean(unique[representative], vec) <= tol {
+					first = representative
+					break
+				}
+			}
+		})
+		if first >= 0 {
+			weights[first] += dataset.Weight(i)
+		} else {
+			buckets[cell] = append(buckets[cell], len(unique))
+			unique, weights = append(unique, vec), append(weights, dataset.Weight(i))
+		}
+	}
+	return Dataset{data: unique, creator: dataset.creator, weights: weights}
+}
+
+func dedupCell(vec Vector, tol float64) [3]int64 {
+	var cell [3]int64
+	for i := 0; i < min(3, Dimension(vec)); i++ {
+		if tol > 0 {
+			cell[i] = int64(math.Floor(Component(vec, i) / tol))
+		} else {
+			// Adding 0 turns -0 into +0, which are duplicates of each other.
+			cell[i] = int64(math.Float64bits(Component(vec, i) + 0))
+		}
+	}
+	return cell
+}
+
+// forEachNeighbourCell calls `f` for the cell and, if requested, every adjacent cell along the first `dimensions` axes.
+func forEachNeighbourCell(cell [3]int64, dimensions int, adjacent bool, f func([3]int64)) {
+	if !adjacent {
+		f(cell)
+		return
+	}
+	var visit func(axis int, current [3]int64)
+	visit = func(axis int, current [3]int64) {
+		if axis == dimensions {
+			f(current)
+			return
+		}
+		for offset := int64(-1); offset <= 1; offset++ {
+			next := current
+			next[axis] += offset
+			visit(axis+1, next)
+		}
+	}
+	visit(0, cell)
+}
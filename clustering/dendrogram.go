@@ -0,0 +1,228 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HierarchicalClusterer is a clustering at every level of detail, e.g., the Dendrogram of a dataset, which is cut into a flat clustering on demand.
+// Every cut numbers its clusters from 0 in order of the first element of every cluster.
+type HierarchicalClusterer interface {
+	// CutAtHeight returns the clusters that exist at the height, i.e., after all merges up to and including the height.
+	CutAtHeight(height float64) SimpleFlatClusterer
+	// CutIntoK returns the `k` clusters that exist right before the last `k-1` merges.
+	CutIntoK(k int) (SimpleFlatClusterer, error)
+}
+
+// Dendrogram is the tree of merges of a hierarchical clustering of `n` elements, e.g., as returned by AgglomerativeFromDistances.
+// Its nodes are the clusters of the merges: every element on its own is one of the leaves 0 up to `n-1`, the node created by the `i`th merge is node `n+i`.
+type Dendrogram struct {
+	merges []Merge
+}
+
+// NewDendrogram will create the Dendrogram of the `n-1` merges of `n` elements, which must merge every node exactly once, except for the root.
+func NewDendrogram(merges []Merge) (Dendrogram, error) {
+	n := len(merges) + 1
+	merged := make([]bool, 2*n-1)
+	for i, merge := range merges {
+		if merge.A == merge.B {
+			return Dendrogram{}, fmt.Errorf("Merge %d merges node %d with itself", i, merge.A)
+		}
+		for _, node := range []Cluster{merge.A, merge.B} {
+			if node < 0 || int(node) >= n+i {
+				return Dendrogram{}, fmt.Errorf("Merge %d merges node %d which does not exist yet", i, node)
+			}
+			if merged[node] {
+				return Dendrogram{}, fmt.Errorf("Merge %d merges node %d which was merged before", i, node)
+			}
+			merged[node] = true
+		}
+	}
+	return Dendrogram{merges: append([]Merge(nil), merges...)}, nil
+}
+
+// Size returns the number of elements, the leaves of the dendrogram.
+func (dendrogram Dendrogram) Size() int {
+	return len(dendrogram.merges) + 1
+}
+
+// Merges returns the merges of the dendrogram in order.
+func (dendrogram Dendrogram) Merges() []Merge {
+	return append([]Merge(nil), dendrogram.merges...)
+}
+
+// Root returns the node holding all elements.
+func (dendrogram Dendrogram) Root() Cluster {
+	return Cluster(2 * len(dendrogram.merges))
+}
+
+// IsLeaf reports whether the node is a single element.
+func (dendrogram Dendrogram) IsLeaf(node Cluster) bool {
+	return int(node) < dendrogram.Size()
+}
+
+// Children returns the two nodes merged into the node, which must not be a leaf.
+func (dendrogram Dendrogram) Children(node Cluster) (Cluster, Cluster) {
+	merge := dendrogram.merges[int(node)-dendrogram.Size()]
+	return merge.A, merge.B
+}
+
+// Height returns the height at which the node was created, 0 for a leaf.
+func (dendrogram Dendrogram) Height(node Cluster) float64 {
+	if dendrogram.IsLeaf(node) {
+		return 0
+	}
+	return dendrogram.merges[int(node)-dendrogram.Size()].Height
+}
+
+// Leaves returns the elements in the node, in the order of a depth-first traversal visiting A before B.
+func (dendrogram Dendrogram) Leaves(node Cluster) []int {
+	var leaves []int
+	for stack := []Cluster{node}; len(stack) > 0; {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if dendrogram.IsLeaf(current) {
+			leaves = append(leaves, int(current))
+			continue
+		}
+		a, b := dendrogram.Children(current)
+		stack = append(stack, b, a)
+	}
+	return leaves
+}
+
+// AssignmentsAtHeight returns the cluster of every element at the height, i.e., after all merges up to and including the height,
+// numbered from 0 in order of the first element of every cluster. When heights are not monotone, e.g., for centroid linkage,
+// every merge up to the height is applied together with all merges below it in the tree.
+func (dendrogram Dendrogram) AssignmentsAtHeight(height float64) []Cluster {
+	return dendrogram.cut(func(merge Merge, _ int) bool { return merge.Height <= height })
+}
+
+// AssignmentsIntoK returns the cluster of every element of the `k` clusters that exist right before the last `k-1` merges,
+// numbered from 0 in order of the first element of every cluster.
+func (dendrogram Dendrogram) AssignmentsIntoK(k int) ([]Cluster, error) {
+	if k <= 0 || k > dendrogram.Size() {
+		return nil, fmt.Errorf("Expected between 1 and %d clusters but got %d", dendrogram.Size(), k)
+	}
+	return dendrogram.cut(func(_ Merge, i int) bool { return i < dendrogram.Size()-k }), nil
+}
+
+// Over will return the HierarchicalClusterer of the dendrogram of the vectors of the dataset, whose elements are the vectors in order.
+// Its cuts assign every vector to the cluster of the vector of the dataset closest to it according to the metric, as AssignmentClusterer does.
+func (dendrogram Dendrogram) Over(dataset *Dataset, metric Metric) (HierarchicalClusterer, error) {
+	if dataset.Count() != dendrogram.Size() {
+		return nil, fmt.Errorf("Expected a dataset of the %d elements of the dendrogram but got %d vectors", dendrogram.Size(), dataset.Count())
+	}
+	return datasetDendrogram{dendrogram: dendrogram, dataset: dataset, metric: metric}, nil
+}
+
+type datasetDendrogram struct {
+	dendrogram Dendrogram
+	dataset    *Dataset
+	metric     Metric
+}
+
+// CutAtHeight returns the clusters that exist at the height, i.e., after all merges up to and including the height.
+func (hierarchy datasetDendrogram) CutAtHeight(height float64) SimpleFlatClusterer {
+	return assignmentClusterer{dataset: hierarchy.dataset, assignments: hierarchy.dendrogram.AssignmentsAtHeight(height), metric: hierarchy.metric}
+}
+
+// CutIntoK returns the `k` clusters that exist right before the last `k-1` merges.
+func (hierarchy datasetDendrogram) CutIntoK(k int) (SimpleFlatClusterer, error) {
+	assignments, err := hierarchy.dendrogram.AssignmentsIntoK(k)
+	if err != nil {
+		return nil, err
+	}
+	return assignmentClusterer{dataset: hierarchy.dataset, assignments: assignments, metric: hierarchy.metric}, nil
+}
+
+// cut applies the merges for which apply holds together with all merges below them, and returns the resulting cluster of every element.
+func (dendrogram Dendrogram) cut(apply func(merge Merge, i int) bool) []Cluster {
+	n := dendrogram.Size()
+	applied := make([]bool, len(dendrogram.merges))
+	for i := len(dendrogram.merges) - 1; i >= 0; i-- {
+		merge := dendrogram.merges[i]
+		if !applied[i] && !apply(merge, i) {
+			continue
+		}
+		applied[i] = true
+		for _, child := range []Cluster{merge.A, merge.B} {
+			if !dendrogram.IsLeaf(child) {
+				applied[int(child)-n] = true
+			}
+		}
+	}
+	// The merges are applied bottom-up on the elements, every node is represented by one of its leaves, which the merges below it have already joined with the others.
+	parents, leaves := make([]int, n), make([]int, 2*n-1)
+	for i := range parents {
+		parents[i], leaves[i] = i, i
+	}
+	var find func(element int) int
+	find = func(element int) int {
+		if parents[element] != element {
+			parents[element] = find(parents[element])
+		}
+		return parents[element]
+	}
+	for i, merge := range dendrogram.merges {
+		leaves[n+i] = leaves[merge.A]
+		if applied[i] {
+			parents[find(leaves[merge.B])] = find(leaves[merge.A])
+		}
+	}
+	roots := make([]Cluster, n)
+	for i := range roots {
+		roots[i] = Cluster(find(i))
+	}
+	labels, _ := denseLabels(roots)
+	assignments := make([]Cluster, n)
+	for i, label := range labels {
+		assignments[i] = Cluster(label)
+	}
+	return assignments
+}
+
+// AssignmentClusterer will return the SimpleFlatClusterer assigning every vector to the cluster of the vector of the dataset closest to it according to the metric,
+// where `assignments[i]` is the cluster of the `i`th vector of the dataset, e.g., the assignments of a cut of a Dendrogram or the result of DBSCAN.
+// The closest vector is found by a KNN query, which is answered by the attached NeighbourIndex if there is one.
+func AssignmentClusterer(dataset *Dataset, assignments []Cluster, metric Metric) (SimpleFlatClusterer, error) {
+	if len(assignments) != dataset.Count() {
+		return nil, fmt.Errorf("Expected a cluster for all %d vectors but got %d", dataset.Count(), len(assignments))
+	}
+	return assignmentClusterer{dataset: dataset, assignments: append([]Cluster(nil), assignments...), metric: metric}, nil
+}
+
+type assignmentClusterer struct {
+	dataset     *Dataset
+	assignments []Cluster
+	metric      Metric
+}
+
+// FindCluster returns the unique cluster a vector is a part of.
+func (clusterer assignmentClusterer) FindCluster(v Vector) (Cluster, error) {
+	nearest := clusterer.dataset.KNN(v, 1, clusterer.metric)
+	if len(nearest) == 0 {
+		return -1, errors.New("There are no vectors in the dataset of the AssignmentClusterer")
+	}
+	return clusterer.assignments[nearest[0].Index], nil
+}
+
+// Clusters returns all the clusters this clusterer contains.
+func (clusterer assignmentClusterer) Clusters() []Cluster {
+	return sortedClusters(clusterer.assignments)
+}
+
+// ClusteredPartition will split the dataset according to the cluster each element belongs to
+// such that every element in the dataset is assigned to exactly one cluster and the
+// union of all vector slices is equal to the datapoint slice of the original dataset.
+func (clusterer assignmentClusterer) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	partition := make(map[Cluster][]Vector)
+	for _, vec := range dataset.data {
+		cluster, err := clusterer.FindCluster(vec)
+		if err != nil {
+			return nil, err
+		}
+		partition[cluster] = append(partition[cluster], vec)
+	}
+	return partition, nil
+}
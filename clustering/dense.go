@@ -0,0 +1,30 @@
+package clustering
+
+import "gonum.org/v1/gonum/mat"
+
+// ToDense will return a matrix with a row for every vector of this dataset, in order, and a column for every component,
+// such that gonum's matrix routines (e.g., SVD or eigendecompositions) can be applied to the dataset. An empty dataset results in nil.
+func (dataset *Dataset) ToDense() *mat.Dense {
+	if dataset.IsEmpty() {
+		return nil
+	}
+	dimension := Dimension(dataset.data[0])
+	values := make([]float64, 0, dataset.Count()*dimension)
+	for _, vec := range dataset.data {
+		for i := 0; i < dimension; i++ {
+			values = append(values, Component(vec, i))
+		}
+	}
+	return mat.NewDense(dataset.Count(), dimension, values)
+}
+
+// DatasetFromDense will create a dataset holding every row of the matrix as a VectorN, in order.
+// The vectors are copies, so the matrix can be modified afterwards without affecting the dataset.
+func DatasetFromDense(m *mat.Dense) Dataset {
+	rows, columns := m.Dims()
+	data := make([]Vector, rows)
+	for i := range data {
+		data[i] = VectorN(mat.Row(nil, i, m))
+	}
+	return CreateDataset(data, vectorNCreator(columns))
+}
@@ -0,0 +1,161 @@
+package clustering
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DistanceMatrix gives the distances between every pair of elements of a collection of elements identified by their index.
+type DistanceMatrix interface {
+	// Size returns the number of elements in the collection.
+	Size() int
+	// Distance returns the distance between the `i`th and `j`th element.
+	Distance(i, j int) float64
+}
+
+// DenseDistanceMatrix is a DistanceMatrix keeping all distances in memory.
+type DenseDistanceMatrix [][]float64
+
+// NewDistanceMatrix will create a DistanceMatrix from the provided distances such that `distances[i][j]` is the distance between the `i`th and `j`th element.
+// An error is returned when the matrix is not square or contains negative distances.
+func NewDistanceMatrix(distances [][]float64) (DenseDistanceMatrix, error) {
+	for i, row := range distances {
+		if len(row) != len(distances) {
+			return nil, fmt.Errorf("Expected a square distance matrix but row %d has %d columns instead of %d", i, len(row), len(distances))
+		}
+		for j, distance := range row {
+			if distance < 0 || math.IsNaN(distance) {
+				return nil, fmt.Errorf("Expected non-negative distances but the distance between %d and %d is %v", i, j, distance)
+			}
+		}
+	}
+	return DenseDistanceMatrix(distances), nil
+}
+
+// Size returns the number of elements in the collection.
+func (matrix DenseDistanceMatrix) Size() int {
+	return len(matrix)
+}
+
+// Distance returns the distance between the `i`th and `j`th element.
+func (matrix DenseDistanceMatrix) Distance(i, j int) float64 {
+	return matrix[i][j]
+}
+
+// ReaderDistanceMatrix is a DistanceMatrix reading its distances on demand from some source, e.g., a file too large to keep in memory.
+// Distances are expected to be stored row by row as little-endian float64s.
+type ReaderDistanceMatrix struct {
+	reader io.ReaderAt
+	size   int
+	err    error
+}
+
+// OpenDistanceMatrix will create a DistanceMatrix of `n` elements reading its distances from the provided source, e.g., an `*os.File`.
+// Reading errors cannot be reported by Distance, instead the first error is retained and can be retrieved through Err.
+// The clustering algorithms operating on a DistanceMatrix check Err themselves.
+func OpenDistanceMatrix(reader io.ReaderAt, n int) *ReaderDistanceMatrix {
+	return &ReaderDistanceMatrix{reader: reader, size: n}
+}
+
+// Size returns the number of elements in the collection.
+func (matrix *ReaderDistanceMatrix) Size() int {
+	return matrix.size
+}
+
+// Distance returns the distance between the `i`th and `j`th element, or NaN if the distance could not be read.
+func (matrix *ReaderDistanceMatrix) Distance(i, j int) float64 {
+	var buffer [8]byte
+	offset := (int64(i)*int64(matrix.size) + int64(j)) * 8
+	if _, err := matrix.reader.ReadAt(buffer[:], offset); err != nil {
+		if matrix.err == nil {
+			matrix.err = fmt.Errorf("Could not read the distance between %d and %d: %v", i, j, err)
+		}
+		return math.NaN()
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(buffer[:]))
+}
+
+// Err returns the first error encountered while reading distances, if any.
+func (matrix *ReaderDistanceMatrix) Err() error {
+	return matrix.err
+}
+
+// WriteDistanceMatrix will write the distance matrix row by row as little-endian float64s, the format read by OpenDistanceMatrix.
+func WriteDistanceMatrix(w io.Writer, matrix DistanceMatrix) error {
+	n := matrix.Size()
+	row := make([]byte, 8*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			binary.LittleEndian.PutUint64(row[8*j:], math.Float64bits(matrix.Distance(i, j)))
+		}
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return distanceMatrixErr(matrix)
+}
+
+// distanceMatrixErr returns the error retained by the distance matrix, if it retains errors at all.
+func distanceMatrixErr(matrix DistanceMatrix) error {
+	if failing, ok := matrix.(interface{ Err() error }); ok {
+		return failing.Err()
+	}
+	return nil
+}
+
+func checkDistanceMatrix(matrix DistanceMatrix) error {
+	if matrix.Size() == 0 {
+		return errors.New("The distance matrix is empty")
+	}
+	return nil
+}
+
+// CachedDistanceMatrix is a DistanceMatrix over the elements of a dataset that computes distances with some Metric on first use and remembers them afterwards.
+// The distance between an element and itself is always 0 and the metric is assumed to be symmetric.
+// A CachedDistanceMatrix is not safe for concurrent use.
+type CachedDistanceMatrix struct {
+	data   []Vector
+	metric Metric
+	// cache holds the upper triangle of the matrix, row by row, and computed holds a bit for every distance of the cache telling whether it has been computed yet,
+	// such that a metric returning NaN is not called again.
+	cache    []float64
+	computed []uint64
+}
+
+// CachedDistances will create a DistanceMatrix over the elements of the dataset that memoizes the distances calculated by the metric.
+func CachedDistances(dataset *Dataset, metric Metric) *CachedDistanceMatrix {
+	return &CachedDistanceMatrix{data: dataset.data, metric: metric}
+}
+
+// Size returns the number of elements in the collection.
+func (matrix *CachedDistanceMatrix) Size() int {
+	return len(matrix.data)
+}
+
+// Distance returns the distance between the `i`th and `j`th element, calculating it only if it has not been calculated before.
+func (matrix *CachedDistanceMatrix) Distance(i, j int) float64 {
+	if i == j {
+		return 0
+	} else if i > j {
+		i, j = j, i
+	}
+	if matrix.cache == nil {
+		n := len(matrix.data)
+		matrix.cache = make([]float64, n*(n-1)/2)
+		matrix.computed = make([]uint64, (len(matrix.cache)+63)/64)
+	}
+	k := condensedIndex(len(matrix.data), i, j)
+	if bit := uint64(1) << (k % 64); matrix.computed[k/64]&bit == 0 {
+		matrix.cache[k] = matrix.metric(matrix.data[i], matrix.data[j])
+		matrix.computed[k/64] |= bit
+	}
+	return matrix.cache[k]
+}
+
+// condensedIndex returns the position of element `(i, j)`, with `i < j`, in the upper triangle of an `n` by `n` matrix stored row by row.
+func condensedIndex(n, i, j int) int {
+	return n*i - i*(i+1)/2 + j - i - 1
+}
@@ -0,0 +1,43 @@
+package clustering
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCachedDistancesComputeEveryDistanceOnce(t *testing.T) {
+	dataset := CreateNonEmptyDataset([]Vector{VectorN{0}, VectorN{1}, VectorN{2}, VectorN{3}})
+	// metric is undefined for the third element.
+	metric := func(a, b Vector) float64 {
+		if Component(a, 0) == 2 || Component(b, 0) == 2 {
+			return math.NaN()
+		}
+		return Euclidean(a, b)
+	}
+	calls := map[[2]float64]int{}
+	matrix := CachedDistances(&dataset, func(a, b Vector) float64 {
+		calls[[2]float64{Component(a, 0), Component(b, 0)}]++
+		return metric(a, b)
+	})
+	for repeat := 0; repeat < 3; repeat++ {
+		for i := 0; i < matrix.Size(); i++ {
+			for j := 0; j < matrix.Size(); j++ {
+				want := metric(dataset.At(i), dataset.At(j))
+				if i == j {
+					want = 0
+				}
+				if got := matrix.Distance(i, j); got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+					t.Errorf("Expected the distance between %d and %d to be %v but got %v", i, j, want, got)
+				}
+			}
+		}
+	}
+	if len(calls) != 6 {
+		t.Errorf("Expected the metric to be called for 6 pairs but got %v", calls)
+	}
+	for pair, count := range calls {
+		if count != 1 {
+			t.Errorf("Expected the distance of %v to be computed once but it was computed %d times", pair, count)
+		}
+	}
+}
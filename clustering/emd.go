@@ -0,0 +1,127 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthMovers will return the exact earth mover's distance (Wasserstein-1) between two 1D histograms,
+// where component `i` is the mass of the `i`th bin and neighbouring bins are at distance 1 of each other.
+// Both histograms are normalized to unit mass before comparing them, empty histograms are only close to other empty histograms.
+func EarthMovers(v, other Vector) float64 {
+	p, q := checkHistograms(v, other)
+	vMass, otherMass := sum(p), sum(q)
+	if vMass == 0 || otherMass == 0 {
+		if vMass == otherMass {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	// In 1D the optimal transport plan moves mass in order, so the distance is the area between both cumulative distributions.
+	distance, vCumulative, otherCumulative := 0.0, 0.0, 0.0
+	for i := 0; i < len(p)-1; i++ {
+		vCumulative += p[i] / vMass
+		otherCumulative += q[i] / otherMass
+		distance += math.Abs(vCumulative - otherCumulative)
+	}
+	return distance
+}
+
+// Sinkhorn will create a Metric approximating the earth mover's distance between histograms with an arbitrary ground distance,
+// e.g., 2D or 3D colour histograms, where `cost[i][j]` is the cost of moving a unit of mass from bin `i` to bin `j`.
+// The approximation solves the entropy regularized transport problem with the provided regularization strength:
+// smaller values are more accurate but need more iterations and are more prone to underflow.
+// Both histograms are normalized to unit mass before comparing them.
+func Sinkhorn(cost [][]float64, regularization float64, iterations int) Metric {
+	if regularization <= 0 {
+		panic(fmt.Sprintf("Expected a positive regularization but got %v", regularization))
+	}
+	n := len(cost)
+	kernel := make([][]float64, n)
+	for i, row := range cost {
+		if len(row) != n {
+			panic(fmt.Sprintf("Expected a square cost matrix but row %d has %d columns instead of %d", i, len(row), n))
+		}
+		kernel[i] = make([]float64, n)
+		for j, c := range row {
+			kernel[i][j] = math.Exp(-c / regularization)
+		}
+	}
+
+	return func(v, other Vector) float64 {
+		p, q := checkHistograms(v, other)
+		if len(p) != n {
+			panic(fmt.Sprintf("Expected histograms with %d bins but got %d", n, len(p)))
+		}
+		vMass, otherMass := sum(p), sum(q)
+		if vMass == 0 || otherMass == 0 {
+			if vMass == otherMass {
+				return 0
+			}
+			return math.Inf(1)
+		}
+
+		u, w := make([]float64, n), make([]float64, n)
+		for i := range w {
+			w[i] = 1
+		}
+		for iteration := 0; iteration < iterations; iteration++ {
+			for i := range u {
+				u[i] = p[i] / vMass / kernelProduct(kernel, w, i, false)
+			}
+			for j := range w {
+				w[j] = q[j] / otherMass / kernelProduct(kernel, u, j, true)
+			}
+		}
+
+		distance := 0.0
+		for i := range u {
+			for j := range w {
+				if plan := u[i] * kernel[i][j] * w[j]; plan > 0 {
+					distance += plan * cost[i][j]
+				}
+			}
+		}
+		return distance
+	}
+}
+
+// kernelProduct multiplies the kernel, or its transpose, with the provided vector and returns the `i`th component of the result.
+// A zero product is replaced by the smallest positive float so bins without mass do not lead to a division by zero.
+func kernelProduct(kernel [][]float64, vec []float64, i int, transposed bool) float64 {
+	result := 0.0
+	for j := range vec {
+		if transposed {
+			result += kernel[j][i] * vec[j]
+		} else {
+			result += kernel[i][j] * vec[j]
+		}
+	}
+	if result == 0 {
+		return math.SmallestNonzeroFloat64
+	}
+	return result
+}
+
+func checkHistograms(v, other Vector) ([]float64, []float64) {
+	if Dimension(v) != Dimension(other) {
+		panic(fmt.Sprintf("Expected histograms with an equal number of bins but got %d and %d", Dimension(v), Dimension(other)))
+	}
+	p, q := components(v), components(other)
+	for _, histogram := range [][]float64{p, q} {
+		for i, mass := range histogram {
+			if mass < 0 || math.IsNaN(mass) {
+				panic(fmt.Sprintf("Expected non-negative histogram bins but bin %d has mass %v", i, mass))
+			}
+		}
+	}
+	return p, q
+}
+
+func sum(values []float64) float64 {
+	result := 0.0
+	for _, value := range values {
+		result += value
+	}
+	return result
+}
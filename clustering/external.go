@@ -0,0 +1,218 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// AdjustedRandIndex will compute the Rand index of two clusterings of the same elements corrected for chance, e.g., to compare a clustering with the ground-truth labels
+// or with another clustering. `a[i]` and `b[i]` are the clusters of the `i`th element in both clusterings, see Assignments.
+// Identical clusterings, up to renaming the clusters, have index 1 while independent clusterings have an expected index of 0.
+func AdjustedRandIndex(a, b []Cluster) (float64, error) {
+	table, rows, columns, err := contingency(a, b)
+	if err != nil {
+		return 0, err
+	}
+	pairs, rowPairs, columnPairs := 0.0, 0.0, 0.0
+	for _, row := range table {
+		for _, count := range row {
+			pairs += choose2(count)
+		}
+	}
+	for _, count := range rows {
+		rowPairs += choose2(count)
+	}
+	for _, count := range columns {
+		columnPairs += choose2(count)
+	}
+	expected := 0.0
+	if total := choose2(len(a)); total > 0 {
+		expected = rowPairs * columnPairs / total
+	}
+	maximum := (rowPairs + columnPairs) / 2
+	if maximum == expected {
+		// Both clusterings put all elements in a single cluster or every element in its own cluster.
+		return 1, nil
+	}
+	return (pairs - expected) / (maximum - expected), nil
+}
+
+// PairCounts holds the number of pairs of elements in each of the four combinations of being together or apart in a clustering and in the ground-truth labels.
+type PairCounts struct {
+	// TruePositives is the number of pairs in the same cluster with the same label.
+	TruePositives float64
+	// FalsePositives is the number of pairs in the same cluster with different labels.
+	FalsePositives float64
+	// FalseNegatives is the number of pairs in different clusters with the same label.
+	FalseNegatives float64
+	// TrueNegatives is the number of pairs in different clusters with different labels.
+	TrueNegatives float64
+}
+
+// CountPairs will count the pairs of elements of a clustering of the same elements as the ground-truth labels that are together or apart in each,
+// where `labels[i]` is the class and `clusters[i]` the cluster of the `i`th element.
+func CountPairs(labels, clusters []Cluster) (PairCounts, error) {
+	table, classes, clusterSizes, err := contingency(labels, clusters)
+	if err != nil {
+		return PairCounts{}, err
+	}
+	together, sameClass, sameCluster := 0.0, 0.0, 0.0
+	for _, row := range table {
+		for _, count := range row {
+			together += choose2(count)
+		}
+	}
+	for _, size := range classes {
+		sameClass += choose2(size)
+	}
+	for _, size := range clusterSizes {
+		sameCluster += choose2(size)
+	}
+	return PairCounts{
+		TruePositives:  together,
+		FalsePositives: sameCluster - together,
+		FalseNegatives: sameClass - together,
+		TrueNegatives:  choose2(len(labels)) - sameClass - sameCluster + together,
+	}, nil
+}
+
+// Pairs returns the total number of pairs of elements.
+func (counts PairCounts) Pairs() float64 {
+	return counts.TruePositives + counts.FalsePositives + counts.FalseNegatives + counts.TrueNegatives
+}
+
+// Rand returns the Rand index, the fraction of pairs on which the clustering and the labels agree, or 1 if there are no pairs.
+func (counts PairCounts) Rand() float64 {
+	if counts.Pairs() == 0 {
+		return 1
+	}
+	return (counts.TruePositives + counts.TrueNegatives) / counts.Pairs()
+}
+
+// Jaccard returns the Jaccard index, the fraction of pairs together in the clustering or the labels that are together in both, or 1 if there are no such pairs.
+func (counts PairCounts) Jaccard() float64 {
+	if together := counts.TruePositives + counts.FalsePositives + counts.FalseNegatives; together > 0 {
+		return counts.TruePositives / together
+	}
+	return 1
+}
+
+// FowlkesMallows returns the Fowlkes-Mallows index, the geometric mean of the pair precision and recall, or 1 if no pairs are together in either.
+func (counts PairCounts) FowlkesMallows() float64 {
+	clustered, labeled := counts.TruePositives+counts.FalsePositives, counts.TruePositives+counts.FalseNegatives
+	if clustered == 0 && labeled == 0 {
+		return 1
+	}
+	if clustered == 0 || labeled == 0 {
+		return 0
+	}
+	return counts.TruePositives / math.Sqrt(clustered*labeled)
+}
+
+// RandIndex will compute the Rand index of two clusterings of the same elements, the fraction of pairs of elements on which they agree whether both elements are in the same cluster.
+// Unlike AdjustedRandIndex it is not corrected for chance, so random clusterings usually score well above 0.
+func RandIndex(a, b []Cluster) (float64, error) {
+	counts, err := CountPairs(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return counts.Rand(), nil
+}
+
+// VMeasure will compute the homogeneity, completeness and V-measure of a clustering of the same elements as the ground-truth labels,
+// where `labels[i]` is the class and `clusters[i]` the cluster of the `i`th element. Homogeneity is 1 if every cluster only contains elements of a single class,
+// i.e., the clustering does not merge classes, completeness is 1 if all elements of every class are in the same cluster, i.e., the clustering does not split classes,
+// and the V-measure is their harmonic mean.
+func VMeasure(labels, clusters []Cluster) (float64, float64, float64, error) {
+	table, classes, clusterSizes, err := contingency(labels, clusters)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	n := float64(len(labels))
+	classEntropy, clusterEntropy := entropy(classes, n), entropy(clusterSizes, n)
+	// The conditional entropies follow from the joint entropy of the classes and clusters.
+	joint := 0.0
+	for _, row := range table {
+		for _, count := range row {
+			if count > 0 {
+				p := float64(count) / n
+				joint -= p * math.Log(p)
+			}
+		}
+	}
+	homogeneity, completeness := 1.0, 1.0
+	if classEntropy > 0 {
+		homogeneity = 1 - (joint-clusterEntropy)/classEntropy
+	}
+	if clusterEntropy > 0 {
+		completeness = 1 - (joint-classEntropy)/clusterEntropy
+	}
+	vMeasure := 0.0
+	if homogeneity+completeness > 0 {
+		vMeasure = 2 * homogeneity * completeness / (homogeneity + completeness)
+	}
+	return homogeneity, completeness, vMeasure, nil
+}
+
+// Purity will compute the purity of a clustering of the same elements as the ground-truth labels, where `labels[i]` is the class and `clusters[i]` the cluster of the `i`th element:
+// the fraction of elements that belong to the most common class of their cluster. The purity of every cluster on its own is returned as well.
+// Note how putting every element in its own cluster results in a purity of 1.
+func Purity(labels, clusters []Cluster) (float64, map[Cluster]float64, error) {
+	table, sizes, _, err := contingency(clusters, labels)
+	if err != nil {
+		return 0, nil, err
+	}
+	numbers, _ := denseLabels(clusters)
+	perCluster := make(map[Cluster]float64, len(table))
+	majority := 0
+	for i, cluster := range clusters {
+		if _, ok := perCluster[cluster]; ok {
+			continue
+		}
+		row, largest := table[numbers[i]], 0
+		for _, count := range row {
+			largest = max(largest, count)
+		}
+		perCluster[cluster] = float64(largest) / float64(sizes[numbers[i]])
+		majority += largest
+	}
+	return float64(majority) / float64(len(labels)), perCluster, nil
+}
+
+// entropy returns the entropy of the distribution of `n` elements over groups of the provided sizes.
+func entropy(sizes []int, n float64) float64 {
+	result := 0.0
+	for _, size := range sizes {
+		if size > 0 {
+			p := float64(size) / n
+			result -= p * math.Log(p)
+		}
+	}
+	return result
+}
+
+// contingency counts the elements in every pair of clusters of both clusterings, where the clusters of each clustering are numbered as by denseLabels,
+// returning the table together with the sizes of the clusters of both clusterings.
+func contingency(a, b []Cluster) ([][]int, []int, []int, error) {
+	if len(a) != len(b) {
+		return nil, nil, nil, fmt.Errorf("Expected clusterings of the same number of elements but got %d and %d elements", len(a), len(b))
+	}
+	if len(a) == 0 {
+		return nil, nil, nil, errors.New("Expected clusterings of at least one element")
+	}
+	rowLabels, rows := denseLabels(a)
+	columnLabels, columns := denseLabels(b)
+	table := make([][]int, len(rows))
+	for i := range table {
+		table[i] = make([]int, len(columns))
+	}
+	for i := range a {
+		table[rowLabels[i]][columnLabels[i]]++
+	}
+	return table, rows, columns, nil
+}
+
+func choose2(n int) float64 {
+	return float64(n) * float64(n-1) / 2
+}
@@ -0,0 +1,57 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FeatureSelector is a Transformer keeping only the selected components of every vector, e.g., to exclude irrelevant or constant features before distance-based clustering.
+// The transformed vectors are VectorNs.
+type FeatureSelector struct {
+	// Features holds the indices of the kept components, the `i`th component of a transformed vector is component `Features[i]` of the original vector.
+	Features []int
+}
+
+// FitVarianceThreshold will create a FeatureSelector keeping the components whose (population) variance in the dataset exceeds the threshold,
+// a threshold of 0 removes exactly the constant components.
+func FitVarianceThreshold(dataset *Dataset, threshold float64) (FeatureSelector, error) {
+	if dataset.IsEmpty() {
+		return FeatureSelector{}, errors.New("Cannot fit a feature selector on an empty dataset")
+	}
+	standardizer, err := FitStandardizer(dataset)
+	if err != nil {
+		return FeatureSelector{}, err
+	}
+	var selector FeatureSelector
+	for i, deviation := range standardizer.StdDev {
+		if deviation*deviation > threshold {
+			selector.Features = append(selector.Features, i)
+		}
+	}
+	return selector, nil
+}
+
+// Transform keeps only the selected components of the vector.
+func (selector FeatureSelector) Transform(v Vector) Vector {
+	result := make(VectorN, len(selector.Features))
+	for i, feature := range selector.Features {
+		result[i] = Component(v, feature)
+	}
+	return result
+}
+
+// SelectFeatures will return a dataset of VectorNs holding only the components at the provided indices of every vector of this dataset, in the order of the indices.
+func (dataset *Dataset) SelectFeatures(indices ...int) (Dataset, error) {
+	dimension := 0
+	if dataset.creator != nil {
+		dimension = Dimension(dataset.creator.Null())
+	}
+	for _, i := range indices {
+		if i < 0 || i >= dimension {
+			return Dataset{}, fmt.Errorf("Feature %d is out of range for vectors of dimension %d", i, dimension)
+		}
+	}
+	selected := dataset.Transform(FeatureSelector{Features: append([]int(nil), indices...)})
+	selected.creator = vectorNCreator(len(indices))
+	return selected, nil
+}
@@ -0,0 +1,141 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// FuzzyCMeansOptions configures the fitting of FuzzyCMeans, every option that is not set falls back to its default.
+type FuzzyCMeansOptions struct {
+	// Fuzziness is the exponent applied to the memberships, which must be larger than 1, values close to 1 approach K-Means. 2 by default.
+	Fuzziness float64
+	// Iterations is the maximal number of iterations, 100 by default.
+	Iterations int
+	// Tolerance ends the fitting once no centroid moves more than it, 1e-6 by default.
+	Tolerance float64
+}
+
+func (opts FuzzyCMeansOptions) withDefaults() FuzzyCMeansOptions {
+	if opts.Fuzziness == 0 {
+		opts.Fuzziness = 2
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 100
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-6
+	}
+	return opts
+}
+
+// FuzzyCMeans is a fuzzy clustering (Bezdek) in which every vector belongs to every cluster to a degree that decreases with its distance to the centroid of the cluster,
+// as a SimpleFlatClusterer it assigns every vector to its nearest centroid.
+type FuzzyCMeans struct {
+	// Centroids holds the centroid of every cluster.
+	Centroids []Vector
+	// Fuzziness is the exponent applied to the memberships.
+	Fuzziness float64
+}
+
+// FuzzyCMeans will fit a FuzzyCMeans clustering with `k` clusters on this dataset, starting from the centroids of KMeansPlusPlus.
+// Every centroid is the mean of the vectors weighted by their membership raised to the fuzziness, times their weight when the dataset is weighted.
+func (dataset *Dataset) FuzzyCMeans(k int, opts FuzzyCMeansOptions, src rand.Source) (FuzzyCMeans, error) {
+	n := dataset.Count()
+	if k <= 0 || k > n {
+		return FuzzyCMeans{}, fmt.Errorf("Expected between 1 and %d clusters but got %d", n, k)
+	}
+	opts = opts.withDefaults()
+	if !(opts.Fuzziness > 1) {
+		return FuzzyCMeans{}, fmt.Errorf("Expected a fuzziness larger than 1 but got %v", opts.Fuzziness)
+	}
+	model := FuzzyCMeans{Centroids: dataset.KMeansPlusPlus(k, src), Fuzziness: opts.Fuzziness}
+	instrumentation, logger := dataset.instrument(), dataset.log()
+	for iteration := 0; iteration < opts.Iterations; iteration++ {
+		start := time.Now()
+		buckets := make([]bucketCollector, len(model.Centroids))
+		for i, vec := range dataset.data {
+			for c, membership := range model.memberships(vec) {
+				buckets[c].Collect(vec, dataset.Weight(i)*math.Pow(membership, model.Fuzziness))
+			}
+		}
+		instrumentation.Assignment("fuzzy-c-means", n, time.Since(start))
+		instrumentation.Distances("fuzzy-c-means", n*len(model.Centroids))
+		maxDelta := 0.0
+		for _, delta := range createNewCentroids(&model.Centroids, buckets) {
+			maxDelta = math.Max(maxDelta, delta)
+		}
+		instrumentation.Iteration("fuzzy-c-means", iteration+1)
+		logger.Debug("Moved the centroids", "algorithm", "fuzzy-c-means", "iteration", iteration+1, "max_delta", maxDelta)
+		if maxDelta < opts.Tolerance {
+			break
+		}
+	}
+	return model, nil
+}
+
+// memberships returns the membership of every cluster in the order of the centroids.
+func (model FuzzyCMeans) memberships(v Vector) []float64 {
+	distances := make([]float64, len(model.Centroids))
+	for c, centroid := range model.Centroids {
+		if distances[c] = Euclidean(v, centroid); distances[c] == 0 {
+			// A vector at a centroid belongs to that cluster entirely.
+			memberships := make([]float64, len(model.Centroids))
+			memberships[c] = 1
+			return memberships
+		}
+	}
+	exponent := 2 / (model.Fuzziness - 1)
+	memberships := make([]float64, len(model.Centroids))
+	for c := range memberships {
+		total := 0.0
+		for _, distance := range distances {
+			total += math.Pow(distances[c]/distance, exponent)
+		}
+		memberships[c] = 1 / total
+	}
+	return memberships
+}
+
+// Memberships returns the degree to which a vector belongs to every cluster, the memberships sum to 1.
+func (model FuzzyCMeans) Memberships(v Vector) map[Cluster]float64 {
+	memberships := make(map[Cluster]float64, len(model.Centroids))
+	for c, membership := range model.memberships(v) {
+		memberships[Cluster(c)] = membership
+	}
+	return memberships
+}
+
+// FindCluster returns the unique cluster a vector is a part of, which is the cluster it has the largest membership of.
+func (model FuzzyCMeans) FindCluster(v Vector) (Cluster, error) {
+	if len(model.Centroids) == 0 {
+		return -1, errors.New("There are no centroids in the FuzzyCMeans")
+	}
+	return Cluster(nearestCentroid(model.Centroids, v)), nil
+}
+
+// Clusters returns all the clusters this clusterer contains.
+func (model FuzzyCMeans) Clusters() []Cluster {
+	clusters := make([]Cluster, len(model.Centroids))
+	for i := range clusters {
+		clusters[i] = Cluster(i)
+	}
+	return clusters
+}
+
+// ClusteredPartition will split the dataset according to the cluster each element belongs to
+// such that every element in the dataset is assigned to exactly one cluster and the
+// union of all vector slices is equal to the datapoint slice of the original dataset.
+func (model FuzzyCMeans) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	partition := make(map[Cluster][]Vector)
+	for _, vec := range dataset.data {
+		cluster, err := model.FindCluster(vec)
+		if err != nil {
+			return nil, err
+		}
+		partition[cluster] = append(partition[cluster], vec)
+	}
+	return partition, nil
+}
@@ -0,0 +1,393 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// CovarianceType restricts the shape of the covariance matrices of the components of a GaussianMixture.
+type CovarianceType int
+
+const (
+	// FullCovariance allows every component an arbitrary covariance matrix.
+	FullCovariance CovarianceType = iota
+	// DiagonalCovariance restricts every component to a diagonal covariance matrix, i.e., axis-aligned ellipsoids.
+	DiagonalCovariance
+	// SphericalCovariance restricts every component to a multiple of the identity matrix, i.e., spheres.
+	SphericalCovariance
+)
+
+// GMMOptions configures the fitting of a GaussianMixture, every option that is not set falls back to its default.
+type GMMOptions struct {
+	// Covariance restricts the shape of the covariance matrices, full covariance matrices by default.
+	Covariance CovarianceType
+	// Iterations is the maximal number of expectation-maximization steps, 100 by default.
+	Iterations int
+	// Tolerance ends the fitting once a step improves the mean log-likelihood by less than it, 1e-6 by default.
+	Tolerance float64
+	// Regularization is added to the diagonal of every covariance matrix to keep it positive definite, 1e-6 by default.
+	Regularization float64
+}
+
+// GaussianMixture is a mixture of multivariate normal distributions whose components are the clusters,
+// as a SimpleFlatClusterer it assigns every vector to the component most likely to have generated it.
+type GaussianMixture struct {
+	// Weights holds the mixing weight of every component, the weights sum to 1.
+	Weights []float64
+	// Means holds the mean of every component.
+	Means []Vector
+	// Covariances holds the covariance matrix of every component.
+	Covariances [][][]float64
+	// Covariance is the shape the covariance matrices are restricted to.
+	Covariance CovarianceType
+	// factors caches the Cholesky factor of every covariance matrix once it is needed and is shared by the copies of a fitted or decoded mixture,
+	// a mixture assembled by hand has none and factors its covariance matrices whenever they are needed.
+	factors *factorCache
+}
+
+// factorCache holds the Cholesky factors of the covariance matrices of a mixture, or the error of factoring them.
+type factorCache struct {
+	once    sync.Once
+	factors []matrix
+	err     error
+}
+
+// cachedFactors returns a cache holding the factors that are already known.
+func cachedFactors(factors []matrix) *factorCache {
+	cache := &factorCache{}
+	cache.once.Do(func() { cache.factors = factors })
+	return cache
+}
+
+// GMM will fit a GaussianMixture of `k` components on this dataset with expectation-maximization, starting from the clusters of KMeansPlusPlus.
+// The weights of a weighted dataset count as repeated vectors.
+func (dataset *Dataset) GMM(k int, opts GMMOptions, src rand.Source) (GaussianMixture, error) {
+	n := dataset.Count()
+	if k <= 0 || k > n {
+		return GaussianMixture{}, fmt.Errorf("Expected between 1 and %d components but got %d", n, k)
+	}
+	if opts.Covariance < FullCovariance || opts.Covariance > SphericalCovariance {
+		return GaussianMixture{}, fmt.Errorf("Unknown covariance type %d", opts.Covariance)
+	}
+	opts = opts.withDefaults()
+
+	centroids := dataset.KMeansPlusPlus(k, src)
+	responsibilities := newMatrix(n, k)
+	for i, vec := range dataset.data {
+		responsibilities[i][nearestCentroid(centroids, vec)] = 1
+	}
+	mixture := GaussianMixture{Covariance: opts.Covariance}
+	previous, instrumentation, logger := math.Inf(-1), dataset.instrument(), dataset.log()
+	logger.Debug("Initialized the responsibilities from KMeansPlusPlus", "algorithm", "gaussian-mixture", "centroids", centroids)
+	for iteration := 0; iteration < opts.Iterations; iteration++ {
+		if err := mixture.maximize(dataset, responsibilities, opts.Regularization); err != nil {
+			return GaussianMixture{}, err
+		}
+		start := time.Now()
+		logLikelihood := mixture.expect(dataset, responsibilities) / dataset.TotalWeight()
+		instrumentation.Assignment("gaussian-mixture", n, time.Since(start))
+		instrumentation.Iteration("gaussian-mixture", iteration+1)
+		logger.Debug("Finished an expectation-maximization step", "algorithm", "gaussian-mixture", "iteration", iteration+1, "log_likelihood", logLikelihood,
+			"delta", logLikelihood-previous)
+		for c, weight := range mixture.Weights {
+			if weight*dataset.TotalWeight() < 1e-6 {
+				logger.Debug("A component lost all its vectors", "algorithm", "gaussian-mixture", "iteration", iteration+1, "component", c)
+			}
+		}
+		if logLikelihood-previous < opts.Tolerance {
+			break
+		}
+		previous = logLikelihood
+	}
+	return mixture, nil
+}
+
+func (opts GMMOptions) withDefaults() GMMOptions {
+	if opts.Iterations <= 0 {
+		opts.Iterations = 100
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-6
+	}
+	if opts.Regularization <= 0 {
+		opts.Regularization = 1e-6
+	}
+	return opts
+}
+
+// maximize estimates the weights, means and covariance matrices of the components from the responsibility of every component for every vector.
+func (mixture *GaussianMixture) maximize(dataset *Dataset, responsibilities matrix, regularization float64) error {
+	k, dimension := len(responsibilities[0]), Dimension(dataset.data[0])
+	creator := dataset.data[0].Creator()
+	mixture.Weights, mixture.Means = make([]float64, k), make([]Vector, k)
+	mixture.Covariances = make([][][]float64, k)
+	factors := make([]matrix, k)
+	total := 0.0
+	for c := 0; c < k; c++ {
+		// The tiny offset keeps components that lost all their vectors well defined.
+		mass, mean := 1e-10, make([]float64, dimension)
+		for i, vec := range dataset.data {
+			r := dataset.Weight(i) * responsibilities[i][c]
+			mass += r
+			for d := range mean {
+				mean[d] += r * Component(vec, d)
+			}
+		}
+		for d := range mean {
+			mean[d] /= mass
+		}
+		covariance, diff := newMatrix(dimension, dimension), make([]float64, dimension)
+		for i, vec := range dataset.data {
+			for d := range diff {
+				diff[d] = Component(vec, d) - mean[d]
+			}
+			covariance.addOuter(diff, dataset.Weight(i)*responsibilities[i][c]/mass)
+		}
+		restrictCovariance(covariance, mixture.Covariance)
+		for d := range covariance {
+			covariance[d][d] += regularization
+		}
+		factor, ok := covariance.cholesky()
+		if !ok {
+			return fmt.Errorf("The covariance matrix of component %d is not positive definite, try a larger regularization", c)
+		}
+		mixture.Weights[c], mixture.Means[c] = mass, creator.New(func(d int) float64 { return mean[d] })
+		mixture.Covariances[c], factors[c] = covariance, factor
+		total += mass
+	}
+	mixture.factors = cachedFactors(factors)
+	for c := range mixture.Weights {
+		mixture.Weights[c] /= total
+	}
+	return nil
+}
+
+// restrictCovariance projects the covariance matrix onto the shape required by the covariance type.
+func restrictCovariance(covariance matrix, covarianceType CovarianceType) {
+	if covarianceType == FullCovariance {
+		return
+	}
+	variance := 0.0
+	for d := range covariance {
+		variance += covariance[d][d] / float64(len(covariance))
+		for e := range covariance[d] {
+			if d != e {
+				covariance[d][e] = 0
+			}
+		}
+	}
+	if covarianceType == SphericalCovariance {
+		for d := range covariance {
+			covariance[d][d] = variance
+		}
+	}
+}
+
+// expect computes the responsibility of every component for every vector and returns the weighted log-likelihood of the dataset.
+func (mixture GaussianMixture) expect(dataset *Dataset, responsibilities matrix) float64 {
+	factors, logLikelihood := mixture.factors.factors, 0.0
+	for i, vec := range dataset.data {
+		logProbabilities := mixture.logProbabilities(vec, factors)
+		normalization := logSumExp(logProbabilities)
+		for c, logProbability := range logProbabilities {
+			responsibilities[i][c] = math.Exp(logProbability - normalization)
+		}
+		logLikelihood += dataset.Weight(i) * normalization
+	}
+	return logLikelihood
+}
+
+// logProbabilities returns the logarithm of the mixing weight times the density of every component at the vector.
+func (mixture GaussianMixture) logProbabilities(v Vector, factors []matrix) []float64 {
+	x := components(v)
+	result := make([]float64, len(mixture.Weights))
+	diff := make([]float64, len(x))
+	for c, factor := range factors {
+		for d := range diff {
+			diff[d] = x[d] - Component(mixture.Means[c], d)
+		}
+		y := factor.solveLower(diff)
+		logDeterminant := 0.0
+		for d := range factor {
+			logDeterminant += 2 * math.Log(factor[d][d])
+		}
+		result[c] = math.Log(mixture.Weights[c]) - (float64(len(x))*math.Log(2*math.Pi)+logDeterminant+dotProduct(y, y))/2
+	}
+	return result
+}
+
+// choleskyFactors returns the Cholesky factors of the covariance matrices, factoring them on first use,
+// or an error if one of them is not positive definite or does not match the means.
+func (mixture GaussianMixture) choleskyFactors() ([]matrix, error) {
+	if mixture.factors == nil {
+		return mixture.factorCovariances()
+	}
+	mixture.factors.once.Do(func() {
+		mixture.factors.factors, mixture.factors.err = mixture.factorCovariances()
+	})
+	return mixture.factors.factors, mixture.factors.err
+}
+
+func (mixture GaussianMixture) factorCovariances() ([]matrix, error) {
+	if len(mixture.Covariances) != len(mixture.Weights) || len(mixture.Means) != len(mixture.Weights) {
+		return nil, errors.New("Expected a weight, mean and covariance matrix for every component")
+	}
+	factors := make([]matrix, len(mixture.Covariances))
+	for c, covariance := range mixture.Covariances {
+		dimension := Dimension(mixture.Means[c])
+		if len(covariance) != dimension {
+			return nil, fmt.Errorf("Expected a %dx%d covariance matrix for component %d", dimension, dimension, c)
+		}
+		for _, row := range covariance {
+			if len(row) != dimension {
+				return nil, fmt.Errorf("Expected a %dx%d covariance matrix for component %d", dimension, dimension, c)
+			}
+		}
+		factor, ok := matrix(covariance).cholesky()
+		if !ok {
+			return nil, fmt.Errorf("The covariance matrix of component %d is not positive definite", c)
+		}
+		factors[c] = factor
+	}
+	return factors, nil
+}
+
+func logSumExp(values []float64) float64 {
+	largest := math.Inf(-1)
+	for _, value := range values {
+		largest = math.Max(largest, value)
+	}
+	if math.IsInf(largest, -1) {
+		return largest
+	}
+	total := 0.0
+	for _, value := range values {
+		total += math.Exp(value - largest)
+	}
+	return largest + math.Log(total)
+}
+
+// Probabilities will return the posterior probability of every component having generated the vector,
+// or an error if one of the covariance matrices is not positive definite.
+func (mixture GaussianMixture) Probabilities(v Vector) ([]float64, error) {
+	factors, err := mixture.choleskyFactors()
+	if err != nil {
+		return nil, err
+	}
+	logProbabilities := mixture.logProbabilities(v, factors)
+	normalization := logSumExp(logProbabilities)
+	for c := range logProbabilities {
+		logProbabilities[c] = math.Exp(logProbabilities[c] - normalization)
+	}
+	return logProbabilities, nil
+}
+
+// Memberships returns the posterior probability of every component having generated the vector, which makes the mixture a SoftClusterer.
+// There are no memberships if one of the covariance matrices is not positive definite, which Probabilities and FindCluster report.
+func (mixture GaussianMixture) Memberships(v Vector) map[Cluster]float64 {
+	probabilities, err := mixture.Probabilities(v)
+	if err != nil {
+		return nil
+	}
+	memberships := make(map[Cluster]float64, len(mixture.Weights))
+	for c, probability := range probabilities {
+		memberships[Cluster(c)] = probability
+	}
+	return memberships
+}
+
+// LogLikelihood will return the logarithm of the likelihood of the mixture generating the vectors of the dataset, counting weighted vectors as repeated vectors.
+func (mixture GaussianMixture) LogLikelihood(dataset *Dataset) (float64, error) {
+	factors, err := mixture.choleskyFactors()
+	if err != nil {
+		return 0, err
+	}
+	logLikelihood := 0.0
+	for i, vec := range dataset.data {
+		logLikelihood += dataset.Weight(i) * logSumExp(mixture.logProbabilities(vec, factors))
+	}
+	return logLikelihood, nil
+}
+
+// FindCluster returns the unique cluster a vector is a part of, which is the component most likely to have generated it.
+func (mixture GaussianMixture) FindCluster(v Vector) (Cluster, error) {
+	if len(mixture.Weights) == 0 {
+		return -1, errors.New("There are no components in the GaussianMixture")
+	}
+	factors, err := mixture.choleskyFactors()
+	if err != nil {
+		return -1, err
+	}
+	logProbabilities, best := mixture.logProbabilities(v, factors), 0
+	for c, logProbability := range logProbabilities {
+		if logProbability > logProbabilities[best] {
+			best = c
+		}
+	}
+	return Cluster(best), nil
+}
+
+// Clusters returns all the clusters this clusterer contains.
+func (mixture GaussianMixture) Clusters() []Cluster {
+	clusters := make([]Cluster, len(mixture.Weights))
+	for i := range clusters {
+		clusters[i] = Cluster(i)
+	}
+	return clusters
+}
+
+// ClusteredPartition will split the dataset according to the cluster each element belongs to
+// such that every element in the dataset is assigned to exactly one cluster and the
+// union of all vector slices is equal to the datapoint slice of the original dataset.
+func (mixture GaussianMixture) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	partition := make(map[Cluster][]Vector)
+	for _, vec := range dataset.data {
+		cluster, err := mixture.FindCluster(vec)
+		if err != nil {
+			return nil, err
+		}
+		partition[cluster] = append(partition[cluster], vec)
+	}
+	return partition, nil
+}
+
+// parameters returns the number of free parameters of the mixture.
+func (mixture GaussianMixture) parameters() int {
+	k := len(mixture.Weights)
+	if k == 0 {
+		return 0
+	}
+	dimension := Dimension(mixture.Means[0])
+	covariance := dimension * (dimension + 1) / 2
+	switch mixture.Covariance {
+	case DiagonalCovariance:
+		covariance = dimension
+	case SphericalCovariance:
+		covariance = 1
+	}
+	return k - 1 + k*dimension + k*covariance
+}
+
+// BIC will return the Bayesian information criterion of the mixture for the dataset, `-2 log L + p log n` for `p` free parameters and `n` vectors.
+// Lower values indicate a better trade-off between the fit and the complexity of the mixture.
+func (mixture GaussianMixture) BIC(dataset *Dataset) (float64, error) {
+	logLikelihood, err := mixture.LogLikelihood(dataset)
+	if err != nil {
+		return 0, err
+	}
+	return -2*logLikelihood + float64(mixture.parameters())*math.Log(dataset.TotalWeight()), nil
+}
+
+// AIC will return the Akaike information criterion of the mixture for the dataset, `-2 log L + 2p` for `p` free parameters.
+// Lower values indicate a better trade-off between the fit and the complexity of the mixture.
+func (mixture GaussianMixture) AIC(dataset *Dataset) (float64, error) {
+	logLikelihood, err := mixture.LogLikelihood(dataset)
+	if err != nil {
+		return 0, err
+	}
+	return -2*logLikelihood + 2*float64(mixture.parameters()), nil
+}
@@ -0,0 +1,103 @@
+package clustering
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NeighbourGraph is a sparse, symmetric graph over the vectors of a dataset whose edges are weighted by the distance between their vectors,
+// e.g., the k-nearest neighbour graph built by KNNGraph for spectral clustering or label propagation.
+type NeighbourGraph struct {
+	neighbours [][]Neighbour
+}
+
+// KNNGraph will connect every vector of the dataset to its `k` nearest neighbours using KNN queries, which are answered by the attached NeighbourIndex if there is one
+// and by measuring all distances with the metric otherwise. The queries run in parallel, so the index or metric must be safe for concurrent use.
+// The graph is made symmetric by keeping an edge if either vector is among the nearest neighbours of the other, or only if both are for a mutual graph.
+func KNNGraph(dataset *Dataset, k int, metric Metric, mutual bool) (NeighbourGraph, error) {
+	n := dataset.Count()
+	if k <= 0 || k >= n {
+		return NeighbourGraph{}, fmt.Errorf("Expected between 1 and %d neighbours but got %d", n-1, k)
+	}
+	directed := make([][]Neighbour, n)
+	parallelFor(n, func(i int) {
+		for _, neighbour := range dataset.KNN(dataset.data[i], k+1, metric) {
+			if neighbour.Index != i && len(directed[i]) < k {
+				directed[i] = append(directed[i], neighbour)
+			}
+		}
+	})
+
+	edges := make([]map[int]float64, n)
+	for i := range edges {
+		edges[i] = make(map[int]float64, k)
+	}
+	for i, neighbours := range directed {
+		for _, neighbour := range neighbours {
+			edges[i][neighbour.Index] = neighbour.Distance
+		}
+	}
+	graph := NeighbourGraph{neighbours: make([][]Neighbour, n)}
+	for i := range edges {
+		for j, distance := range edges[i] {
+			_, reverse := edges[j][i]
+			if mutual && !reverse {
+				continue
+			}
+			graph.neighbours[i] = append(graph.neighbours[i], Neighbour{Index: j, Distance: distance})
+			if !reverse {
+				graph.neighbours[j] = append(graph.neighbours[j], Neighbour{Index: i, Distance: distance})
+			}
+		}
+	}
+	for _, neighbours := range graph.neighbours {
+		sort.Slice(neighbours, func(a, b int) bool { return neighbours[a].Index < neighbours[b].Index })
+	}
+	return graph, nil
+}
+
+// Size returns the number of vertices of the graph.
+func (graph NeighbourGraph) Size() int {
+	return len(graph.neighbours)
+}
+
+// Neighbours returns the vertices connected to the `i`th vertex together with the weight of the edges, in increasing order of their index.
+func (graph NeighbourGraph) Neighbours(i int) []Neighbour {
+	return graph.neighbours[i]
+}
+
+// Edges returns the number of edges of the graph.
+func (graph NeighbourGraph) Edges() int {
+	degrees := 0
+	for _, neighbours := range graph.neighbours {
+		degrees += len(neighbours)
+	}
+	return degrees / 2
+}
+
+// Components will return the connected component of every vertex, numbered in order of their first vertex.
+func (graph NeighbourGraph) Components() []Cluster {
+	components := make([]Cluster, len(graph.neighbours))
+	for i := range components {
+		components[i] = Noise
+	}
+	component := Cluster(0)
+	for i := range components {
+		if components[i] != Noise {
+			continue
+		}
+		components[i] = component
+		for stack := []int{i}; len(stack) > 0; {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, neighbour := range graph.neighbours[current] {
+				if components[neighbour.Index] == Noise {
+					components[neighbour.Index] = component
+					stack = append(stack, neighbour.Index)
+				}
+			}
+		}
+		component++
+	}
+	return components
+}
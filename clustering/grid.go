@@ -0,0 +1,153 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+)
+
+// gridCell is the key of a cell of a GridIndex, unused components are 0.
+type gridCell [3]int
+
+// GridIndex is a NeighbourIndex under Euclidean distances for vectors of at most three dimensions, e.g., geographical coordinates,
+// that hashes every vector to its cell of a uniform grid such that the neighbourhood of a query is found by looking up the surrounding cells.
+// Queries are fastest when the cell size is close to the radius of the typical range query. The index does not follow later changes to the dataset.
+type GridIndex struct {
+	data      []Vector
+	dimension int
+	cellSize  float64
+	cells     map[gridCell][]int
+	// lowest and highest bound the cells holding vectors.
+	lowest, highest gridCell
+}
+
+// NewGridIndex will build a GridIndex over the vectors of the dataset with cells of the provided size,
+// it panics when the size is not positive or the vectors have more than three dimensions.
+func NewGridIndex(dataset *Dataset, cellSize float64) *GridIndex {
+	if !(cellSize > 0) || math.IsInf(cellSize, 1) {
+		panic(fmt.Sprintf("Expected a positive cell size but got %v", cellSize))
+	}
+	index := &GridIndex{data: dataset.data, cellSize: cellSize, cells: make(map[gridCell][]int)}
+	for i, vec := range dataset.data {
+		cell := index.cell(vec)
+		if i == 0 {
+			index.dimension, index.lowest, index.highest = Dimension(vec), cell, cell
+		}
+		for d := range cell {
+			index.lowest[d], index.highest[d] = min(index.lowest[d], cell[d]), max(index.highest[d], cell[d])
+		}
+		index.cells[cell] = append(index.cells[cell], i)
+	}
+	return index
+}
+
+func (index *GridIndex) cell(v Vector) gridCell {
+	if Dimension(v) > len(gridCell{}) {
+		panic(fmt.Sprintf("Expected vectors of at most %d dimensions but got %d", len(gridCell{}), Dimension(v)))
+	}
+	var cell gridCell
+	for d := range Dimension(v) {
+		// Cells are clamped to the range of gridLimit, which keeps infinite and huge components from overflowing while cells beyond the ring of a query stay beyond it.
+		// NaN components end up in cell 0, where every distance to the vector is NaN anyway.
+		if position := math.Floor(Component(v, d) / index.cellSize); !math.IsNaN(position) {
+			cell[d] = int(math.Max(-gridLimit, math.Min(gridLimit, position)))
+		}
+	}
+	return cell
+}
+
+// gridLimit bounds the cells of a GridIndex, such that offsets between cells fit in an int on every platform.
+const gridLimit = 1 << 29
+
+// gap returns the ring distance from the centre cell to the nearest cell in the box bounding the cells holding vectors, all smaller rings are empty.
+func (index *GridIndex) gap(centre gridCell) int {
+	gap := 0
+	for d := 0; d < index.dimension; d++ {
+		gap = max(gap, index.lowest[d]-centre[d], centre[d]-index.highest[d])
+	}
+	return gap
+}
+
+// ring calls visit for every vector in the cells whose largest offset in any component from the centre cell is exactly `distance`.
+func (index *GridIndex) ring(centre gridCell, distance int, visit func(i int)) {
+	var from, to gridCell
+	for d := 0; d < index.dimension; d++ {
+		from[d], to[d] = max(centre[d]-distance, index.lowest[d]), min(centre[d]+distance, index.highest[d])
+		if from[d] > to[d] {
+			return
+		}
+	}
+	for x := from[0]; x <= to[0]; x++ {
+		for y := from[1]; y <= to[1]; y++ {
+			for z := from[2]; z <= to[2]; z++ {
+				cell := gridCell{x, y, z}
+				offset := 0
+				for d := range cell {
+					offset = max(offset, abs(cell[d]-centre[d]))
+				}
+				if offset != distance {
+					continue
+				}
+				for _, i := range index.cells[cell] {
+					visit(i)
+				}
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Nearest returns the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+func (index *GridIndex) Nearest(query Vector, k int) []Neighbour {
+	nearest := &nearestNeighbours{k: max(k, 0)}
+	if len(index.data) == 0 || k <= 0 {
+		return nearest.sorted()
+	}
+	centre := index.cell(query)
+	// Vectors in cells beyond the ring are further from the query than the ring distance times the cell size.
+	for distance := index.gap(centre); ; distance++ {
+		index.ring(centre, distance, func(i int) {
+			nearest.offer(i, Euclidean(query, index.data[i]))
+		})
+		if nearest.bound() <= float64(distance)*index.cellSize || index.beyond(centre, distance) {
+			return nearest.sorted()
+		}
+	}
+}
+
+// beyond reports whether the ring at the distance from the centre cell encloses all cells holding vectors.
+func (index *GridIndex) beyond(centre gridCell, distance int) bool {
+	for d := 0; d < index.dimension; d++ {
+		if centre[d]-distance > index.lowest[d] || centre[d]+distance < index.highest[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithinRadius returns the vectors within distance `radius` of the query in increasing order of distance.
+func (index *GridIndex) WithinRadius(query Vector, radius float64) []Neighbour {
+	var within []Neighbour
+	if len(index.data) == 0 || radius < 0 || math.IsNaN(radius) {
+		return within
+	}
+	centre := index.cell(query)
+	// The rings stop growing once they cover the radius or enclose all cells holding vectors, such that a huge or infinite radius visits every cell once.
+	for distance := index.gap(centre); ; distance++ {
+		index.ring(centre, distance, func(i int) {
+			if d := Euclidean(query, index.data[i]); d <= radius {
+				within = append(within, Neighbour{Index: i, Distance: d})
+			}
+		})
+		if float64(distance) >= radius/index.cellSize || index.beyond(centre, distance) {
+			break
+		}
+	}
+	sortNeighbours(within)
+	return within
+}
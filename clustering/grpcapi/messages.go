@@ -0,0 +1,168 @@
+package grpcapi
+
+import (
+	"fmt"
+
+	"github.com/frederikdesmedt/clustering/internal/wire"
+)
+
+// Point holds the components of a single point, see clustering.proto.
+type Point struct {
+	Components []float64
+}
+
+// IngestSummary holds the number of points ingested by a stream and by all streams so far, see clustering.proto.
+type IngestSummary struct {
+	Ingested, Total int64
+}
+
+// PredictRequest holds the points to assign to clusters, see clustering.proto.
+type PredictRequest struct {
+	Points []Point
+}
+
+// PredictResponse holds the cluster of every point of a PredictRequest, in order, see clustering.proto.
+type PredictResponse struct {
+	Clusters []int64
+}
+
+// The messages implement the Marshal and Unmarshal methods that the protobuf runtime calls on messages that encode themselves,
+// such that gRPC encodes them with its default codec like generated messages.
+
+// Reset will clear the point.
+func (point *Point) Reset() { *point = Point{} }
+
+// String returns the point in a readable form.
+func (point *Point) String() string { return fmt.Sprintf("%+v", *point) }
+
+// ProtoMessage marks the point as a protobuf message.
+func (*Point) ProtoMessage() {}
+
+// Marshal will encode the point as a Point message.
+func (point *Point) Marshal() ([]byte, error) {
+	return point.write().Buffer, nil
+}
+
+func (point *Point) write() wire.Writer {
+	var writer wire.Writer
+	writer.Doubles(1, point.Components)
+	return writer
+}
+
+// Unmarshal will decode a Point message.
+func (point *Point) Unmarshal(data []byte) error {
+	reader := wire.NewReader(data)
+	point.read(&reader)
+	return reader.Err()
+}
+
+func (point *Point) read(reader *wire.Reader) {
+	*point = Point{}
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		if field == 1 {
+			point.Components = reader.Doubles(wireType, point.Components)
+		} else {
+			reader.Skip(wireType)
+		}
+	}
+}
+
+// Reset will clear the summary.
+func (summary *IngestSummary) Reset() { *summary = IngestSummary{} }
+
+// String returns the summary in a readable form.
+func (summary *IngestSummary) String() string { return fmt.Sprintf("%+v", *summary) }
+
+// ProtoMessage marks the summary as a protobuf message.
+func (*IngestSummary) ProtoMessage() {}
+
+// Marshal will encode the summary as an IngestSummary message.
+func (summary *IngestSummary) Marshal() ([]byte, error) {
+	var writer wire.Writer
+	writer.Varint(1, summary.Ingested)
+	writer.Varint(2, summary.Total)
+	return writer.Buffer, nil
+}
+
+// Unmarshal will decode an IngestSummary message.
+func (summary *IngestSummary) Unmarshal(data []byte) error {
+	*summary = IngestSummary{}
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		switch field {
+		case 1:
+			summary.Ingested = reader.Varint(wireType)
+		case 2:
+			summary.Total = reader.Varint(wireType)
+		default:
+			reader.Skip(wireType)
+		}
+	}
+	return reader.Err()
+}
+
+// Reset will clear the request.
+func (request *PredictRequest) Reset() { *request = PredictRequest{} }
+
+// String returns the request in a readable form.
+func (request *PredictRequest) String() string { return fmt.Sprintf("%+v", *request) }
+
+// ProtoMessage marks the request as a protobuf message.
+func (*PredictRequest) ProtoMessage() {}
+
+// Marshal will encode the request as a PredictRequest message.
+func (request *PredictRequest) Marshal() ([]byte, error) {
+	var writer wire.Writer
+	for i := range request.Points {
+		writer.Message(1, request.Points[i].write())
+	}
+	return writer.Buffer, nil
+}
+
+// Unmarshal will decode a PredictRequest message.
+func (request *PredictRequest) Unmarshal(data []byte) error {
+	*request = PredictRequest{}
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		if field != 1 {
+			reader.Skip(wireType)
+			continue
+		}
+		var point Point
+		message := reader.Message(wireType)
+		point.read(&message)
+		reader.Adopt(message)
+		request.Points = append(request.Points, point)
+	}
+	return reader.Err()
+}
+
+// Reset will clear the response.
+func (response *PredictResponse) Reset() { *response = PredictResponse{} }
+
+// String returns the response in a readable form.
+func (response *PredictResponse) String() string { return fmt.Sprintf("%+v", *response) }
+
+// ProtoMessage marks the response as a protobuf message.
+func (*PredictResponse) ProtoMessage() {}
+
+// Marshal will encode the response as a PredictResponse message.
+func (response *PredictResponse) Marshal() ([]byte, error) {
+	var writer wire.Writer
+	writer.Ints(1, response.Clusters...)
+	return writer.Buffer, nil
+}
+
+// Unmarshal will decode a PredictResponse message.
+func (response *PredictResponse) Unmarshal(data []byte) error {
+	*response = PredictResponse{}
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		if field == 1 {
+			response.Clusters = reader.Ints(wireType, response.Clusters)
+		} else {
+			reader.Skip(wireType)
+		}
+	}
+	return reader.Err()
+}
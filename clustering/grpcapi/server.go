@@ -0,0 +1,227 @@
+// Package grpcapi serves a streaming clusterer over gRPC: clients stream points into a sequential K-Means clusterer
+// and assign points to its clusters with unary calls, see clustering.proto for the service definition.
+//
+// The messages encode themselves in the protobuf wire format, so the service interoperates with clients generated from clustering.proto
+// and is served by any gRPC server with its default codec.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/frederikdesmedt/clustering"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server is a sequential (MacQueen) K-Means clusterer fed by streamed points, see clustering.IncrementalCentroids.Update:
+// every ingested point moves its nearest centroid towards it by a fraction inversely proportional to the number of points assigned to that centroid so far.
+// A Server is safe for concurrent use, such that several streams can ingest points at once.
+type Server struct {
+	mutex sync.RWMutex
+	k     int
+	model clustering.IncrementalCentroids
+	total int64
+}
+
+// NewServer will create a Server of `k` clusters whose centroids are the first `k` ingested points. NewServer panics if `k` is not positive.
+func NewServer(k int) *Server {
+	if k <= 0 {
+		panic(fmt.Sprintf("Expected a positive number of clusters but got %d", k))
+	}
+	return &Server{k: k}
+}
+
+// NewServerWithCentroids will create a Server starting from the centroids, e.g., as fitted by K-Means on a sample, which must be VectorNs.
+func NewServerWithCentroids(centroids ...clustering.VectorN) (*Server, error) {
+	if len(centroids) == 0 {
+		return nil, errors.New("Expected at least one initial centroid")
+	}
+	if len(centroids[0]) == 0 {
+		return nil, errors.New("Expected centroids of a positive dimension")
+	}
+	server := &Server{k: len(centroids)}
+	for _, centroid := range centroids {
+		if len(centroid) != len(centroids[0]) {
+			return nil, fmt.Errorf("Expected centroids of dimension %d but got dimension %d", len(centroids[0]), len(centroid))
+		}
+		server.model.Centroids = append(server.model.Centroids, append(clustering.VectorN(nil), centroid...))
+	}
+	return server, nil
+}
+
+// Centroids will return a copy of the current centroids.
+func (server *Server) Centroids() clustering.CentroidClusterer {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+	return append(clustering.CentroidClusterer(nil), server.model.Centroids...)
+}
+
+// Ingest will move the nearest centroid towards the point, which must have at least one component.
+func (server *Server) Ingest(point clustering.VectorN) error {
+	if len(point) == 0 {
+		return errors.New("Expected a point of a positive dimension")
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	centroids := server.model.Centroids
+	if len(centroids) > 0 && len(point) != clustering.Dimension(centroids[0]) {
+		return fmt.Errorf("Expected points of dimension %d but got dimension %d", clustering.Dimension(centroids[0]), len(point))
+	}
+	server.total++
+	if len(centroids) < server.k {
+		server.model.Centroids = append(centroids, append(clustering.VectorN(nil), point...))
+		server.model.Counts = append(server.model.Counts, 1)
+		return nil
+	}
+	return server.model.Update(point)
+}
+
+var errNotIngested = errors.New("No points have been ingested yet")
+
+// Predict will return the cluster of the nearest centroid of every point.
+func (server *Server) Predict(points []clustering.VectorN) ([]clustering.Cluster, error) {
+	centroids := server.Centroids()
+	if len(centroids) == 0 {
+		return nil, errNotIngested
+	}
+	vectors := make([]clustering.Vector, len(points))
+	for i, point := range points {
+		if len(point) != clustering.Dimension(centroids[0]) {
+			return nil, fmt.Errorf("Expected points of dimension %d but point %d has dimension %d", clustering.Dimension(centroids[0]), i, len(point))
+		}
+		vectors[i] = point
+	}
+	return centroids.FindClusters(vectors)
+}
+
+// Register will register the server as the Clustering service on the gRPC server.
+func Register(registrar grpc.ServiceRegistrar, server *Server) {
+	registrar.RegisterService(&serviceDesc, server)
+}
+
+// clusteringService is implemented by Server, it serves as the handler type of the service description.
+type clusteringService interface {
+	Ingest(point clustering.VectorN) error
+	Predict(points []clustering.VectorN) ([]clustering.Cluster, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "clustering.grpcapi.Clustering",
+	HandlerType: (*clusteringService)(nil),
+	Methods:     []grpc.MethodDesc{{MethodName: "Predict", Handler: predictHandler}},
+	Streams:     []grpc.StreamDesc{{StreamName: "Ingest", Handler: ingestHandler, ClientStreams: true}},
+	Metadata:    "clustering.proto",
+}
+
+func ingestHandler(service interface{}, stream grpc.ServerStream) error {
+	server := service.(*Server)
+	var summary IngestSummary
+	for {
+		var point Point
+		if err := stream.RecvMsg(&point); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := server.Ingest(point.Components); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		summary.Ingested++
+	}
+	server.mutex.RLock()
+	summary.Total = server.total
+	server.mutex.RUnlock()
+	return stream.SendMsg(&summary)
+}
+
+func predictHandler(service interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := new(PredictRequest)
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	predict := func(ctx context.Context, request interface{}) (interface{}, error) {
+		points := request.(*PredictRequest).Points
+		vectors := make([]clustering.VectorN, len(points))
+		for i, point := range points {
+			vectors[i] = point.Components
+		}
+		clusters, err := service.(*Server).Predict(vectors)
+		if err == errNotIngested {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		} else if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		response := &PredictResponse{Clusters: make([]int64, len(clusters))}
+		for i, cluster := range clusters {
+			response.Clusters[i] = int64(cluster)
+		}
+		return response, nil
+	}
+	if interceptor == nil {
+		return predict(ctx, request)
+	}
+	return interceptor(ctx, request, &grpc.UnaryServerInfo{Server: service, FullMethod: "/clustering.grpcapi.Clustering/Predict"}, predict)
+}
+
+// Client calls the Clustering service.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient will create a Client calling the Clustering service over the connection.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// Ingest will stream every vector of the source to the service and return the summary of the service once the source is exhausted.
+func (client *Client) Ingest(ctx context.Context, source clustering.DataSource) (IngestSummary, error) {
+	stream, err := client.conn.NewStream(ctx, &serviceDesc.Streams[0], "/clustering.grpcapi.Clustering/Ingest")
+	if err != nil {
+		return IngestSummary{}, err
+	}
+	for vec, ok := source.Next(); ok; vec, ok = source.Next() {
+		components := make([]float64, clustering.Dimension(vec))
+		for i := range components {
+			components[i] = clustering.Component(vec, i)
+		}
+		if err := stream.SendMsg(&Point{Components: components}); err == io.EOF {
+			// The service ended the stream, its status is returned by RecvMsg.
+			break
+		} else if err != nil {
+			return IngestSummary{}, err
+		}
+	}
+	if failing, ok := source.(interface{ Err() error }); ok && failing.Err() != nil {
+		return IngestSummary{}, failing.Err()
+	}
+	if err := stream.CloseSend(); err != nil {
+		return IngestSummary{}, err
+	}
+	var summary IngestSummary
+	return summary, stream.RecvMsg(&summary)
+}
+
+// Predict will return the cluster the service assigns to every vector.
+func (client *Client) Predict(ctx context.Context, vecs []clustering.Vector) ([]clustering.Cluster, error) {
+	request := &PredictRequest{Points: make([]Point, len(vecs))}
+	for i, vec := range vecs {
+		request.Points[i].Components = make([]float64, clustering.Dimension(vec))
+		for j := range request.Points[i].Components {
+			request.Points[i].Components[j] = clustering.Component(vec, j)
+		}
+	}
+	var response PredictResponse
+	if err := client.conn.Invoke(ctx, "/clustering.grpcapi.Clustering/Predict", request, &response); err != nil {
+		return nil, err
+	}
+	clusters := make([]clustering.Cluster, len(response.Clusters))
+	for i, cluster := range response.Clusters {
+		clusters[i] = clustering.Cluster(cluster)
+	}
+	return clusters, nil
+}
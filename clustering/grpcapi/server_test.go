@@ -0,0 +1,179 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/frederikdesmedt/clustering"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// serve starts a gRPC server created without any options serving the server, and returns a client connected to it.
+func serve(t *testing.T, server *Server) *Client {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	Register(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+func TestIngestAndPredict(t *testing.T) {
+	client := serve(t, NewServer(2))
+	ctx := context.Background()
+	if _, err := client.Predict(ctx, []clustering.Vector{clustering.VectorN{0, 0}}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition before ingesting but got %v", err)
+	}
+	points := []clustering.Vector{clustering.VectorN{0, 0}, clustering.VectorN{10, 10}, clustering.VectorN{1, 0}, clustering.VectorN{9, 10}}
+	dataset := clustering.CreateNonEmptyDataset(points)
+	summary, err := client.Ingest(ctx, dataset.Source())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Ingested != 4 || summary.Total != 4 {
+		t.Errorf("Expected 4 ingested points but got %+v", summary)
+	}
+	clusters, err := client.Predict(ctx, []clustering.Vector{clustering.VectorN{0.5, 0}, clustering.VectorN{9.5, 10}, clustering.VectorN{0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 3 || clusters[0] == clusters[1] || clusters[0] != clusters[2] {
+		t.Errorf("Expected the near points in one cluster and the far point in another but got %v", clusters)
+	}
+	if _, err := client.Predict(ctx, []clustering.Vector{clustering.VectorN{1, 2, 3}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a point of the wrong dimension but got %v", err)
+	}
+}
+
+func TestIngestRejectsEmptyPoints(t *testing.T) {
+	server := NewServer(2)
+	client := serve(t, server)
+	dataset := clustering.CreateDataset([]clustering.Vector{clustering.VectorN{}}, clustering.VectorN{}.Creator())
+	if _, err := client.Ingest(context.Background(), dataset.Source()); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty point but got %v", err)
+	}
+	if centroids := server.Centroids(); len(centroids) != 0 {
+		t.Errorf("Expected no centroids after rejecting the point but got %v", centroids)
+	}
+	if _, err := NewServerWithCentroids(clustering.VectorN{}); err == nil {
+		t.Error("Expected an error for centroids without components")
+	}
+}
+
+// dynamicMessages describes the messages of clustering.proto, such that the messages of this package can be compared with those of a generated client.
+func dynamicMessages(t *testing.T) protoreflect.FileDescriptor {
+	repeated, optional := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	field := func(name string, number int32, label *descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, message string) *descriptorpb.FieldDescriptorProto {
+		descriptor := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Label: label, Type: typ.Enum()}
+		if message != "" {
+			descriptor.TypeName = proto.String(message)
+		}
+		return descriptor
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("clustering.proto"),
+		Package: proto.String("clustering.grpcapi"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Point"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("components", 1, repeated, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+			}},
+			{Name: proto.String("IngestSummary"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("ingested", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				field("total", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+			}},
+			{Name: proto.String("PredictRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("points", 1, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".clustering.grpcapi.Point"),
+			}},
+			{Name: proto.String("PredictResponse"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("clusters", 1, repeated, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+			}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestMessagesMatchGeneratedMessages(t *testing.T) {
+	file := dynamicMessages(t)
+	messages := []struct {
+		name    string
+		message interface {
+			Marshal() ([]byte, error)
+			Unmarshal([]byte) error
+		}
+		decoded interface {
+			Marshal() ([]byte, error)
+			Unmarshal([]byte) error
+		}
+	}{
+		{"Point", &Point{Components: []float64{1.5, -2, 0}}, &Point{}},
+		{"IngestSummary", &IngestSummary{Ingested: 3, Total: 1 << 40}, &IngestSummary{}},
+		{"PredictRequest", &PredictRequest{Points: []Point{{Components: []float64{1, 2}}, {}, {Components: []float64{3}}}}, &PredictRequest{}},
+		{"PredictResponse", &PredictResponse{Clusters: []int64{0, 2, 1, 300}}, &PredictResponse{}},
+	}
+	for _, test := range messages {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.message.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated := dynamicpb.NewMessage(file.Messages().ByName(protoreflect.Name(test.name)))
+			if err := proto.Unmarshal(data, generated); err != nil {
+				t.Fatalf("A generated message cannot decode the encoding: %v", err)
+			}
+			if len(generated.GetUnknown()) > 0 {
+				t.Errorf("A generated message does not know the fields %v", generated.GetUnknown())
+			}
+			encoded, err := proto.Marshal(generated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := test.decoded.Unmarshal(encoded); err != nil {
+				t.Fatalf("The encoding of a generated message cannot be decoded: %v", err)
+			}
+			original, _ := test.message.Marshal()
+			decoded, _ := test.decoded.Marshal()
+			if string(original) != string(decoded) {
+				t.Errorf("Expected %v after decoding a generated message but got %v", test.message, test.decoded)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRejectsCorruptMessages(t *testing.T) {
+	for _, data := range [][]byte{{0x0a, 0x05, 1, 2}, {0x0a, 0x03, 1, 2, 3}, {0x09, 1, 2}, {0x00}, {0x0f}, {0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}} {
+		for _, message := range []interface{ Unmarshal([]byte) error }{&Point{}, &IngestSummary{}, &PredictRequest{}, &PredictResponse{}} {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("Decoding %v into a %T panicked: %v", data, message, r)
+					}
+				}()
+				message.Unmarshal(data)
+			}()
+		}
+		if err := (&Point{}).Unmarshal(data); err == nil {
+			t.Errorf("Expected an error decoding %v into a Point", data)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+)
+
+// Linkage determines how the distance between two clusters is derived from the distances between their elements.
+type Linkage int
+
+const (
+	// SingleLinkage uses the smallest distance between an element of one cluster and an element of the other.
+	SingleLinkage Linkage = iota
+	// CompleteLinkage uses the largest distance between an element of one cluster and an element of the other.
+	CompleteLinkage
+	// AverageLinkage uses the average distance between the elements of one cluster and the elements of the other (UPGMA).
+	AverageLinkage
+)
+
+// Merge describes a single step of agglomerative clustering in which two clusters were merged into a new cluster.
+// Every element on its own is one of the clusters 0 up to `n-1`, the cluster created by the `i`th merge is cluster `n+i`.
+type Merge struct {
+	// A and B are the merged clusters, with A < B.
+	A, B Cluster
+	// Height is the linkage distance between A and B at the time they were merged.
+	Height float64
+	// Size is the number of elements in the merged cluster.
+	Size int
+}
+
+// AgglomerativeFromDistances will perform agglomerative hierarchical clustering on the elements of the distance matrix,
+// repeatedly merging the two closest clusters according to the linkage until one cluster remains, and return the `n-1` merges in order.
+func AgglomerativeFromDistances(matrix DistanceMatrix, linkage Linkage) ([]Merge, error) {
+	if err := checkDistanceMatrix(matrix); err != nil {
+		return nil, err
+	}
+	if linkage < SingleLinkage || linkage > AverageLinkage {
+		return nil, fmt.Errorf("Unknown linkage %d", linkage)
+	}
+	n := matrix.Size()
+	distances := make([][]float64, n)
+	for i := range distances {
+		distances[i] = make([]float64, n)
+		for j := range distances[i] {
+			distances[i][j] = matrix.Distance(i, j)
+		}
+	}
+	if err := distanceMatrixErr(matrix); err != nil {
+		return nil, err
+	}
+	return agglomerate(distances, linkage), nil
+}
+
+// agglomerate merges clusters given their initial distance matrix, which is overwritten in the process.
+func agglomerate(distances [][]float64, linkage Linkage) []Merge {
+	n := len(distances)
+	// Slot `i` of the distance matrix holds the cluster ids[i] of sizes[i] elements as long as it is active.
+	ids, sizes, active := make([]Cluster, n), make([]int, n), make([]bool, n)
+	for i := range ids {
+		ids[i], sizes[i], active[i] = Cluster(i), 1, true
+	}
+
+	merges := make([]Merge, 0, n-1)
+	for len(merges) < n-1 {
+		a, b, height := -1, -1, math.Inf(1)
+		for i := 0; i < n; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if active[j] && (a < 0 || distances[i][j] < height) {
+					a, b, height = i, j, distances[i][j]
+				}
+			}
+		}
+
+		merge := Merge{A: ids[a], B: ids[b], Height: height, Size: sizes[a] + sizes[b]}
+		if merge.A > merge.B {
+			merge.A, merge.B = merge.B, merge.A
+		}
+		merges = append(merges, merge)
+
+		// The merged cluster takes over slot `a`, the distances to it follow from the Lance-Williams update formulas.
+		for k := 0; k < n; k++ {
+			if !active[k] || k == a || k == b {
+				continue
+			}
+			var distance float64
+			switch linkage {
+			case SingleLinkage:
+				distance = math.Min(distances[a][k], distances[b][k])
+			case CompleteLinkage:
+				distance = math.Max(distances[a][k], distances[b][k])
+			case AverageLinkage:
+				distance = (float64(sizes[a])*distances[a][k] + float64(sizes[b])*distances[b][k]) / float64(sizes[a]+sizes[b])
+			}
+			distances[a][k], distances[k][a] = distance, distance
+		}
+		ids[a], sizes[a], active[b] = Cluster(n+len(merges)-1), merge.Size, false
+	}
+	return merges
+}
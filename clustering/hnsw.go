@@ -0,0 +1,214 @@
+package clustering
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// HNSWOptions configures a HNSW index, every option that is not set falls back to its default.
+type HNSWOptions struct {
+	// Links is the number of neighbours every vector is linked to on every layer but the bottom one, which allows twice as many, 16 by default and at least 2.
+	Links int
+	// ConstructionBreadth is the number of candidate neighbours considered when inserting a vector, 200 by default.
+	ConstructionBreadth int
+	// SearchBreadth is the minimal number of candidates considered when searching, larger values are slower but more accurate, 50 by default.
+	SearchBreadth int
+}
+
+func (opts HNSWOptions) withDefaults() HNSWOptions {
+	if opts.Links <= 0 {
+		opts.Links = 16
+	} else if opts.Links < 2 {
+		// The layers of the vectors are drawn with a decay of `1/Links`, which only thins out the layers for at least 2 links.
+		opts.Links = 2
+	}
+	if opts.ConstructionBreadth <= 0 {
+		opts.ConstructionBreadth = 200
+	}
+	if opts.SearchBreadth <= 0 {
+		opts.SearchBreadth = 50
+	}
+	return opts
+}
+
+// HNSW is a hierarchical navigable small world graph (Malkov and Yashunin), an approximate NeighbourIndex under any metric that grows by inserting vectors one at a time.
+// Every vector is linked to its nearest neighbours on the bottom layer and on a random number of sparser layers above it, and searches descend greedily from the top layer.
+// Queries may miss some of the true neighbours in exchange for being fast in high dimensions. An HNSW is safe for concurrent use.
+type HNSW struct {
+	mutex   sync.RWMutex
+	metric  Metric
+	opts    HNSWOptions
+	rng     *rand.Rand
+	vectors []Vector
+	// links holds the neighbours of every vector on every layer it is part of.
+	links [][][]int
+	entry int
+}
+
+// NewHNSW will create an empty HNSW index with distances measured by the metric, choosing the layers of the vectors with the source.
+func NewHNSW(metric Metric, opts HNSWOptions, src rand.Source) *HNSW {
+	return &HNSW{metric: metric, opts: opts.withDefaults(), rng: rand.New(src)}
+}
+
+// BuildHNSW will create an HNSW index and insert all vectors of the dataset, such that the indices of the neighbours are the indices in the dataset.
+func BuildHNSW(dataset *Dataset, metric Metric, opts HNSWOptions, src rand.Source) *HNSW {
+	index := NewHNSW(metric, opts, src)
+	for _, vec := range dataset.data {
+		index.Insert(vec)
+	}
+	return index
+}
+
+// Count returns the number of vectors in the index.
+func (index *HNSW) Count() int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return len(index.vectors)
+}
+
+// Insert will add the vector to the index and return its index, which is the number of vectors inserted before it.
+func (index *HNSW) Insert(v Vector) int {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	inserted := len(index.vectors)
+	level := int(-math.Log(1-index.rng.Float64()) / math.Log(float64(index.opts.Links)))
+	index.vectors = append(index.vectors, v)
+	index.links = append(index.links, make([][]int, level+1))
+	if inserted == 0 {
+		return inserted
+	}
+
+	top := len(index.links[index.entry]) - 1
+	entries := []Neighbour{{Index: index.entry, Distance: index.metric(v, index.vectors[index.entry])}}
+	for layer := top; layer > level; layer-- {
+		entries = index.searchLayer(v, entries, 1, layer)
+	}
+	for layer := min(level, top); layer >= 0; layer-- {
+		entries = index.searchLayer(v, entries, index.opts.ConstructionBreadth, layer)
+		for _, neighbour := range entries[:min(len(entries), index.limit(layer))] {
+			index.links[inserted][layer] = append(index.links[inserted][layer], neighbour.Index)
+			index.link(neighbour.Index, inserted, layer)
+		}
+	}
+	if level > top {
+		index.entry = inserted
+	}
+	return inserted
+}
+
+// limit returns the number of neighbours a vector is linked to on the layer.
+func (index *HNSW) limit(layer int) int {
+	if layer == 0 {
+		return 2 * index.opts.Links
+	}
+	return index.opts.Links
+}
+
+// link adds a link from the `i`th to the `j`th vector on the layer, dropping the furthest link when the `i`th vector has too many links.
+func (index *HNSW) link(i, j, layer int) {
+	links := append(index.links[i][layer], j)
+	limit := index.limit(layer)
+	if len(links) > limit {
+		nearest := &nearestNeighbours{k: limit}
+		for _, other := range links {
+			nearest.offer(other, index.metric(index.vectors[i], index.vectors[other]))
+		}
+		links = links[:0]
+		for _, neighbour := range nearest.sorted() {
+			links = append(links, neighbour.Index)
+		}
+	}
+	index.links[i][layer] = links
+}
+
+// searchLayer returns the `breadth` vectors closest to the query that are found on the layer by a best-first search from the entries, in increasing order of distance.
+func (index *HNSW) searchLayer(query Vector, entries []Neighbour, breadth, layer int) []Neighbour {
+	visited := make(map[int]bool)
+	candidates := &closestNeighbours{}
+	results := &nearestNeighbours{k: breadth}
+	for _, entry := range entries {
+		visited[entry.Index] = true
+		heap.Push(candidates, entry)
+		results.offer(entry.Index, entry.Distance)
+	}
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(Neighbour)
+		if current.Distance > results.bound() {
+			break
+		}
+		for _, j := range index.links[current.Index][layer] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if distance := index.metric(query, index.vectors[j]); distance < results.bound() {
+				heap.Push(candidates, Neighbour{Index: j, Distance: distance})
+				results.offer(j, distance)
+			}
+		}
+	}
+	return results.sorted()
+}
+
+// search returns the `breadth` vectors closest to the query that are found on the bottom layer, in increasing order of distance.
+func (index *HNSW) search(query Vector, breadth int) []Neighbour {
+	if len(index.vectors) == 0 {
+		return nil
+	}
+	entries := []Neighbour{{Index: index.entry, Distance: index.metric(query, index.vectors[index.entry])}}
+	for layer := len(index.links[index.entry]) - 1; layer > 0; layer-- {
+		entries = index.searchLayer(query, entries, 1, layer)
+	}
+	return index.searchLayer(query, entries, breadth, 0)
+}
+
+// Nearest returns approximately the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+func (index *HNSW) Nearest(query Vector, k int) []Neighbour {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	if k <= 0 {
+		return nil
+	}
+	found := index.search(query, max(k, index.opts.SearchBreadth))
+	return found[:min(k, len(found))]
+}
+
+// WithinRadius returns approximately the vectors within distance `radius` of the query in increasing order of distance,
+// by widening the search until it finds a vector outside of the radius.
+func (index *HNSW) WithinRadius(query Vector, radius float64) []Neighbour {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	for breadth := index.opts.SearchBreadth; ; breadth *= 2 {
+		found := index.search(query, breadth)
+		if len(found) < breadth || found[len(found)-1].Distance > radius {
+			within := 0
+			for within < len(found) && found[within].Distance <= radius {
+				within++
+			}
+			return found[:within]
+		}
+	}
+}
+
+// closestNeighbours is a min-heap on the distance of the neighbours.
+type closestNeighbours []Neighbour
+
+func (closest closestNeighbours) Len() int { return len(closest) }
+
+func (closest closestNeighbours) Less(a, b int) bool {
+	return closest[a].Distance < closest[b].Distance
+}
+
+func (closest closestNeighbours) Swap(a, b int) { closest[a], closest[b] = closest[b], closest[a] }
+
+func (closest *closestNeighbours) Push(x interface{}) {
+	*closest = append(*closest, x.(Neighbour))
+}
+
+func (closest *closestNeighbours) Pop() interface{} {
+	last := (*closest)[len(*closest)-1]
+	*closest = (*closest)[:len(*closest)-1]
+	return last
+}
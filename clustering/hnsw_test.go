@@ -0,0 +1,74 @@
+package clustering
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHNSWRecall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var dataset Dataset
+	for i := 0; i < 1000; i++ {
+		dataset.Append(VectorN{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()})
+	}
+	for _, links := range []int{4, 16} {
+		index := BuildHNSW(&dataset, Euclidean, HNSWOptions{Links: links}, rand.NewSource(2))
+		found, total := 0, 0
+		for q := 0; q < 50; q++ {
+			query := VectorN{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}
+			exact := make(map[int]bool)
+			for _, neighbour := range dataset.KNN(query, 10, Euclidean) {
+				exact[neighbour.Index] = true
+			}
+			for _, neighbour := range index.Nearest(query, 10) {
+				if exact[neighbour.Index] {
+					found++
+				}
+			}
+			total += len(exact)
+		}
+		if recall := float64(found) / float64(total); recall < 0.9 {
+			t.Errorf("Expected a recall of at least 0.9 with %d links but got %v", links, recall)
+		}
+	}
+}
+
+func TestHNSWLinkLimits(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var dataset Dataset
+	for i := 0; i < 500; i++ {
+		dataset.Append(VectorN{rng.NormFloat64(), rng.NormFloat64()})
+	}
+	const links = 4
+	index := BuildHNSW(&dataset, Euclidean, HNSWOptions{Links: links}, rand.NewSource(2))
+	bottom := 0
+	for i, layers := range index.links {
+		for layer, neighbours := range layers {
+			limit := links
+			if layer == 0 {
+				limit = 2 * links
+			}
+			if len(neighbours) > limit {
+				t.Errorf("Vector %d has %d links on layer %d, expected at most %d", i, len(neighbours), layer, limit)
+			}
+		}
+		bottom = max(bottom, len(layers[0]))
+	}
+	if bottom <= links {
+		t.Errorf("Expected vectors with more than %d links on the bottom layer but the most is %d", links, bottom)
+	}
+}
+
+func TestHNSWWithFewLinks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var dataset Dataset
+	for i := 0; i < 200; i++ {
+		dataset.Append(VectorN{rng.NormFloat64(), rng.NormFloat64()})
+	}
+	for _, links := range []int{-1, 1, 2} {
+		index := BuildHNSW(&dataset, Euclidean, HNSWOptions{Links: links}, rand.NewSource(2))
+		if found := index.Nearest(VectorN{0, 0}, 5); len(found) != 5 {
+			t.Errorf("Expected 5 neighbours with %d links but got %d", links, len(found))
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Package httpapi serves fitted clustering models over HTTP with JSON payloads, such that a model can be served behind a load balancer.
+//
+// A Server is an http.Handler with the following endpoints:
+//
+//	GET    /models                 lists the models
+//	GET    /models/{name}          returns the JSON encoding of a model
+//	PUT    /models/{name}          fits a model on the points of a FitRequest and stores it under the name
+//	DELETE /models/{name}          removes a model
+//	POST   /models/{name}/predict  returns the cluster of every point of a PredictRequest
+//
+// Failed requests are answered with an Error.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/frederikdesmedt/clustering"
+)
+
+// MaxBodySize is the largest request body a Server accepts, in bytes.
+const MaxBodySize = 64 << 20
+
+// FitRequest is the body of a request to fit a model.
+type FitRequest struct {
+	// Algorithm is `kmeans`, `fuzzy` or `gmm`, it defaults to `kmeans`.
+	Algorithm string `json:"algorithm"`
+	// K is the number of clusters.
+	K int `json:"k"`
+	// Seed seeds the random initialization.
+	Seed int64 `json:"seed"`
+	// Points holds the components of the points to fit the model on, which must all have the same dimension.
+	Points [][]float64 `json:"points"`
+}
+
+// PredictRequest is the body of a request to assign points to the clusters of a model.
+type PredictRequest struct {
+	// Points holds the components of the points to assign.
+	Points [][]float64 `json:"points"`
+}
+
+// PredictResponse holds the cluster of every point of a PredictRequest, in order.
+type PredictResponse struct {
+	Clusters []clustering.Cluster `json:"clusters"`
+}
+
+// ModelInfo describes a model served by a Server.
+type ModelInfo struct {
+	Name      string `json:"name"`
+	Algorithm string `json:"algorithm"`
+	Clusters  int    `json:"clusters"`
+	Dimension int    `json:"dimension"`
+}
+
+// Error is the body of the response to a failed request.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Server serves fitted models by name, it is safe for concurrent use.
+type Server struct {
+	mux    *http.ServeMux
+	mutex  sync.RWMutex
+	models map[string]model
+}
+
+type model struct {
+	info      ModelInfo
+	creator   clustering.VectorCreator
+	clusterer clustering.SimpleFlatClusterer
+}
+
+// NewServer will create a Server without models.
+func NewServer() *Server {
+	server := &Server{mux: http.NewServeMux(), models: make(map[string]model)}
+	server.mux.HandleFunc("GET /models", server.list)
+	server.mux.HandleFunc("GET /models/{name}", server.get)
+	server.mux.HandleFunc("PUT /models/{name}", server.fit)
+	server.mux.HandleFunc("DELETE /models/{name}", server.remove)
+	server.mux.HandleFunc("POST /models/{name}/predict", server.predict)
+	return server
+}
+
+// Add will serve the fitted model under the name, replacing the model with that name if there is one.
+// The algorithm describes the model in the listing, the creator creates the points the model assigns from the components of a PredictRequest,
+// e.g., `clustering.Vector2{}.Creator()` for a model fitted on Vector2s, and the dimension of its vectors is the dimension of the points.
+func (server *Server) Add(name, algorithm string, creator clustering.VectorCreator, clusterer clustering.SimpleFlatClusterer) {
+	server.add(name, algorithm, creator, clusterer)
+}
+
+func (server *Server) add(name, algorithm string, creator clustering.VectorCreator, clusterer clustering.SimpleFlatClusterer) ModelInfo {
+	info := ModelInfo{Name: name, Algorithm: algorithm, Clusters: len(clusterer.Clusters()), Dimension: clustering.Dimension(creator.Null())}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.models[name] = model{info: info, creator: creator, clusterer: clusterer}
+	return info
+}
+
+// Model will return the model served under the name, if there is one.
+func (server *Server) Model(name string) (clustering.SimpleFlatClusterer, bool) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+	model, ok := server.models[name]
+	return model.clusterer, ok
+}
+
+// ServeHTTP will answer a request to one of the endpoints.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mux.ServeHTTP(w, r)
+}
+
+func (server *Server) list(w http.ResponseWriter, r *http.Request) {
+	server.mutex.RLock()
+	infos := make([]ModelInfo, 0, len(server.models))
+	for _, model := range server.models {
+		infos = append(infos, model.info)
+	}
+	server.mutex.RUnlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	respond(w, http.StatusOK, infos)
+}
+
+func (server *Server) get(w http.ResponseWriter, r *http.Request) {
+	model, ok := server.lookup(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := model.clusterer.(json.Marshaler); !ok {
+		fail(w, http.StatusNotImplemented, fmt.Errorf("Model %q of type %T has no JSON encoding", model.info.Name, model.clusterer))
+		return
+	}
+	encoded, err := json.Marshal(model.clusterer)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(encoded)
+}
+
+func (server *Server) remove(w http.ResponseWriter, r *http.Request) {
+	if _, ok := server.lookup(w, r); !ok {
+		return
+	}
+	server.mutex.Lock()
+	delete(server.models, r.PathValue("name"))
+	server.mutex.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) fit(w http.ResponseWriter, r *http.Request) {
+	var request FitRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	dataset, err := points(request.Points)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err)
+		return
+	}
+	if dataset.Count() == 0 {
+		fail(w, http.StatusBadRequest, errors.New("Expected points to fit the model on"))
+		return
+	}
+	if request.K <= 0 || request.K > dataset.Count() {
+		fail(w, http.StatusBadRequest, fmt.Errorf("Expected k between 1 and %d but got %d", dataset.Count(), request.K))
+		return
+	}
+	if request.Algorithm == "" {
+		request.Algorithm = "kmeans"
+	}
+	var clusterer clustering.SimpleFlatClusterer
+	src := rand.NewSource(request.Seed)
+	switch request.Algorithm {
+	case "kmeans":
+		centroids := dataset.KMeansPlusPlus(request.K, src)
+		clusterer = &centroids
+	case "fuzzy":
+		clusterer, err = dataset.FuzzyCMeans(request.K, clustering.FuzzyCMeansOptions{}, src)
+	case "gmm":
+		clusterer, err = dataset.GMM(request.K, clustering.GMMOptions{}, src)
+	default:
+		err = fmt.Errorf("Unknown algorithm %q, expected kmeans, fuzzy or gmm", request.Algorithm)
+	}
+	if err != nil {
+		fail(w, http.StatusBadRequest, err)
+		return
+	}
+	respond(w, http.StatusCreated, server.add(r.PathValue("name"), request.Algorithm, make(clustering.VectorN, len(request.Points[0])).Creator(), clusterer))
+}
+
+func (server *Server) predict(w http.ResponseWriter, r *http.Request) {
+	model, ok := server.lookup(w, r)
+	if !ok {
+		return
+	}
+	var request PredictRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	for i, point := range request.Points {
+		if len(point) != model.info.Dimension {
+			fail(w, http.StatusBadRequest, fmt.Errorf("Expected points of dimension %d but point %d has dimension %d", model.info.Dimension, i, len(point)))
+			return
+		}
+	}
+	vectors := make([]clustering.Vector, len(request.Points))
+	for i, point := range request.Points {
+		vectors[i] = model.creator.New(func(d int) float64 { return point[d] })
+	}
+	clusters, err := clustering.FindClusters(model.clusterer, vectors)
+	if err != nil {
+		fail(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	respond(w, http.StatusOK, PredictResponse{Clusters: clusters})
+}
+
+// lookup returns the model named in the path of the request, answering the request if there is no such model.
+func (server *Server) lookup(w http.ResponseWriter, r *http.Request) (model, bool) {
+	name := r.PathValue("name")
+	server.mutex.RLock()
+	model, ok := server.models[name]
+	server.mutex.RUnlock()
+	if !ok {
+		fail(w, http.StatusNotFound, fmt.Errorf("There is no model %q", name))
+	}
+	return model, ok
+}
+
+// points creates a dataset of VectorNs from the points.
+func points(points [][]float64) (clustering.Dataset, error) {
+	vectors := make([]clustering.Vector, len(points))
+	for i, point := range points {
+		if len(point) != len(points[0]) {
+			return clustering.Dataset{}, fmt.Errorf("Expected points of dimension %d but point %d has dimension %d", len(points[0]), i, len(point))
+		}
+		vectors[i] = clustering.VectorN(point)
+	}
+	if len(vectors) == 0 {
+		return clustering.Dataset{}, nil
+	}
+	return clustering.CreateNonEmptyDataset(vectors), nil
+}
+
+func decode(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		fail(w, http.StatusBadRequest, fmt.Errorf("Invalid request: %v", err))
+		return false
+	}
+	return true
+}
+
+func respond(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func fail(w http.ResponseWriter, status int, err error) {
+	respond(w, status, Error{Error: err.Error()})
+}
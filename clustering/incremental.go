@@ -0,0 +1,78 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IncrementalCentroids is a centroid model that keeps the number of vectors every centroid is the mean of,
+// such that Update can adapt a deployed model to new data between full retrains. Its encodings hold the counts as well as the centroids.
+type IncrementalCentroids struct {
+	// Centroids holds the centroid of every cluster.
+	Centroids CentroidClusterer
+	// Counts holds the number of vectors every centroid is the mean of, or their total weight.
+	// A centroid without a count, e.g., because the model was created from its centroids alone, counts as 0.
+	Counts []float64
+}
+
+// NewIncrementalCentroids will create an IncrementalCentroids from a copy of the centroids fitted on the dataset,
+// counting the vectors of the dataset assigned to every centroid, or their total weight when the dataset is weighted.
+func NewIncrementalCentroids(centroids CentroidClusterer, dataset *Dataset) (*IncrementalCentroids, error) {
+	model := &IncrementalCentroids{Centroids: append(CentroidClusterer(nil), centroids...), Counts: make([]float64, len(centroids))}
+	if dataset.IsEmpty() {
+		return model, nil
+	}
+	clusters, err := dataset.FindClusters(&model.Centroids)
+	if err != nil {
+		return nil, err
+	}
+	for i, cluster := range clusters {
+		model.Counts[cluster] += dataset.Weight(i)
+	}
+	return model, nil
+}
+
+// Update will assign every point to its nearest centroid in turn and move that centroid to the mean of the vectors it is the mean of and the point (MacQueen),
+// i.e., towards the point by a fraction inversely proportional to the number of vectors assigned to the centroid so far, incrementing its count.
+// A centroid with a count of 0 moves onto the point. No centroid moves if one of the points does not match the centroids.
+func (model *IncrementalCentroids) Update(points ...Vector) error {
+	centroids := []Vector(model.Centroids)
+	if len(centroids) == 0 {
+		return errors.New("There are no centroids in the IncrementalCentroids")
+	}
+	if len(model.Counts) > len(centroids) {
+		return fmt.Errorf("Expected at most a count for all %d centroids but got %d", len(centroids), len(model.Counts))
+	}
+	for i, point := range points {
+		if err := checkCentroidQuery(centroids, point); err != nil {
+			return fmt.Errorf("Point %d: %v", i, err)
+		}
+	}
+	model.Counts = append(model.Counts, make([]float64, len(centroids)-len(model.Counts))...)
+	for _, point := range points {
+		cluster := nearestCentroid(centroids, point)
+		model.Counts[cluster]++
+		centroids[cluster] = centroids[cluster].Add(point.Subtract(centroids[cluster]).MulScalar(1 / model.Counts[cluster]))
+	}
+	return nil
+}
+
+// FindCluster returns the cluster of the nearest centroid of the vector.
+func (model *IncrementalCentroids) FindCluster(v Vector) (Cluster, error) {
+	return model.Centroids.FindCluster(v)
+}
+
+// FindClusters returns the cluster of the nearest centroid of every vector, in order.
+func (model *IncrementalCentroids) FindClusters(vs []Vector) ([]Cluster, error) {
+	return model.Centroids.FindClusters(vs)
+}
+
+// Clusters returns the cluster of every centroid.
+func (model *IncrementalCentroids) Clusters() []Cluster {
+	return model.Centroids.Clusters()
+}
+
+// ClusteredPartition will split the dataset according to the nearest centroid of every element.
+func (model *IncrementalCentroids) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	return model.Centroids.ClusteredPartition(dataset)
+}
@@ -0,0 +1,141 @@
+package clustering
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// Neighbour is a vector of an indexed dataset together with its distance to a query.
+type Neighbour struct {
+	// Index is the index of the vector in the indexed dataset.
+	Index int
+	// Distance is the distance between the vector and the query.
+	Distance float64
+}
+
+// NeighbourIndex answers nearest neighbour and range queries over the vectors of a dataset, e.g., a KDTree.
+// Implementations must be safe for concurrent queries.
+type NeighbourIndex interface {
+	// Nearest returns the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+	Nearest(query Vector, k int) []Neighbour
+	// WithinRadius returns the vectors within distance `radius` of the query in increasing order of distance.
+	WithinRadius(query Vector, radius float64) []Neighbour
+}
+
+// IndexBuilder builds a NeighbourIndex over the vectors of a dataset.
+type IndexBuilder func(dataset *Dataset) NeighbourIndex
+
+// KDTreeIndex is the IndexBuilder of NewKDTree.
+func KDTreeIndex(dataset *Dataset) NeighbourIndex {
+	return NewKDTree(dataset)
+}
+
+// BallTreeIndex will return the IndexBuilder of NewBallTree with the metric.
+func BallTreeIndex(metric Metric) IndexBuilder {
+	return func(dataset *Dataset) NeighbourIndex {
+		return NewBallTree(dataset, metric)
+	}
+}
+
+// SetNeighbourIndex will attach the index to this dataset such that KNN queries are answered by the index, which must have been built over this dataset.
+// Append, Remove and Replace detach the index as it no longer matches the dataset, a nil index detaches it as well.
+func (dataset *Dataset) SetNeighbourIndex(index NeighbourIndex) {
+	dataset.index = index
+}
+
+// NeighbourIndex returns the index attached to this dataset, or nil if there is none.
+func (dataset *Dataset) NeighbourIndex() NeighbourIndex {
+	return dataset.index
+}
+
+// KNN will return the `k` vectors of this dataset closest to the query in increasing order of distance, or all vectors if there are fewer.
+// The attached NeighbourIndex answers the query if there is one, in which case the metric is ignored, otherwise the distances to all vectors are measured by the metric.
+func (dataset *Dataset) KNN(query Vector, k int, metric Metric) []Neighbour {
+	if dataset.index != nil {
+		return dataset.index.Nearest(query, k)
+	}
+	nearest := &nearestNeighbours{k: max(k, 0)}
+	for i, vec := range dataset.data {
+		nearest.offer(i, metric(query, vec))
+	}
+	return nearest.sorted()
+}
+
+// WithinRadius will return the vectors of this dataset within distance `radius` of the query in increasing order of distance.
+// The attached NeighbourIndex answers the query if there is one, otherwise the Euclidean distances to all vectors are measured.
+func (dataset *Dataset) WithinRadius(query Vector, radius float64) []Neighbour {
+	if dataset.index != nil {
+		return dataset.index.WithinRadius(query, radius)
+	}
+	var within []Neighbour
+	for i, vec := range dataset.data {
+		if distance := Euclidean(query, vec); distance <= radius {
+			within = append(within, Neighbour{Index: i, Distance: distance})
+		}
+	}
+	sortNeighbours(within)
+	return within
+}
+
+// nearestNeighbours collects the `k` closest neighbours offered so far as a max-heap on the distance, such that the furthest neighbour is replaced first.
+type nearestNeighbours struct {
+	k          int
+	neighbours []Neighbour
+}
+
+func (nearest *nearestNeighbours) Len() int { return len(nearest.neighbours) }
+
+func (nearest *nearestNeighbours) Less(a, b int) bool {
+	return nearest.neighbours[a].Distance > nearest.neighbours[b].Distance
+}
+
+func (nearest *nearestNeighbours) Swap(a, b int) {
+	nearest.neighbours[a], nearest.neighbours[b] = nearest.neighbours[b], nearest.neighbours[a]
+}
+
+func (nearest *nearestNeighbours) Push(x interface{}) {
+	nearest.neighbours = append(nearest.neighbours, x.(Neighbour))
+}
+
+func (nearest *nearestNeighbours) Pop() interface{} {
+	last := nearest.neighbours[len(nearest.neighbours)-1]
+	nearest.neighbours = nearest.neighbours[:len(nearest.neighbours)-1]
+	return last
+}
+
+// offer keeps the neighbour if it is closer than the furthest of the `k` closest neighbours so far.
+func (nearest *nearestNeighbours) offer(index int, distance float64) {
+	if len(nearest.neighbours) < nearest.k {
+		heap.Push(nearest, Neighbour{Index: index, Distance: distance})
+	} else if nearest.k > 0 && distance < nearest.neighbours[0].Distance {
+		nearest.neighbours[0] = Neighbour{Index: index, Distance: distance}
+		heap.Fix(nearest, 0)
+	}
+}
+
+// bound returns the distance a neighbour has to beat to be kept.
+func (nearest *nearestNeighbours) bound() float64 {
+	if len(nearest.neighbours) < nearest.k {
+		return math.Inf(1)
+	}
+	if nearest.k == 0 {
+		return math.Inf(-1)
+	}
+	return nearest.neighbours[0].Distance
+}
+
+func (nearest *nearestNeighbours) sorted() []Neighbour {
+	sortNeighbours(nearest.neighbours)
+	return nearest.neighbours
+}
+
+// sortNeighbours sorts the neighbours in increasing order of distance, ties broken by index such that queries are reproducible.
+func sortNeighbours(neighbours []Neighbour) {
+	sort.Slice(neighbours, func(a, b int) bool {
+		if neighbours[a].Distance != neighbours[b].Distance {
+			return neighbours[a].Distance < neighbours[b].Distance
+		}
+		return neighbours[a].Index < neighbours[b].Index
+	})
+}
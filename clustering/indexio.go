@@ -0,0 +1,807 @@
+package clustering
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"unsafe"
+)
+
+// The magic numbers that start a persisted index, the fourth byte is the version of the layout and the padding aligns every later word to 8 bytes for memory-mapping.
+var (
+	kdTreeMagic    = [8]byte{'K', 'D', 'T', 2}
+	ballTreeMagic  = [8]byte{'B', 'L', 'T', 2}
+	hnswMagic      = [8]byte{'H', 'N', 'W', 2}
+	rpForestMagic  = [8]byte{'R', 'P', 'F', 2}
+	vpTreeMagic    = [8]byte{'V', 'P', 'T', 2}
+	coverTreeMagic = [8]byte{'C', 'V', 'T', 2}
+	gridMagic      = [8]byte{'G', 'R', 'D', 2}
+	rtreeMagic     = [8]byte{'R', 'T', 'R', 2}
+)
+
+// WriteIndex will write the structure of a KDTree, BallTree, HNSW, RPForest, VPTree of vectors, CoverTree, GridIndex or RTree as little-endian 8-byte integers and floats,
+// such that the index can be read back by the matching Read function or memory-mapped by OpenIndex instead of being rebuilt. The vectors themselves are not written:
+// an index is read back together with the dataset it was built over, e.g., as stored by WriteDense, and the metric it was built with. An RTree stores its rectangles.
+func WriteIndex(w io.Writer, index NeighbourIndex) error {
+	writer := &indexWriter{w: w}
+	switch index := index.(type) {
+	case *KDTree:
+		writer.magic(kdTreeMagic)
+		writer.ints(len(index.order))
+		writer.ints(index.order...)
+		writer.ints(index.splits...)
+	case *BallTree:
+		writer.magic(ballTreeMagic)
+		writer.ints(len(index.order), len(index.nodes))
+		writer.ints(index.order...)
+		for _, node := range index.nodes {
+			writer.ints(node.lo, node.hi, node.left, node.right)
+			writer.floats(node.radius)
+			writer.floats(components(node.centre)...)
+		}
+	case *HNSW:
+		index.mutex.RLock()
+		defer index.mutex.RUnlock()
+		writer.magic(hnswMagic)
+		writer.ints(len(index.vectors), index.opts.Links, index.opts.ConstructionBreadth, index.opts.SearchBreadth, index.entry)
+		for _, layers := range index.links {
+			writer.ints(len(layers))
+			for _, links := range layers {
+				writer.ints(len(links))
+				writer.ints(links...)
+			}
+		}
+	case *RPForest:
+		writer.magic(rpForestMagic)
+		writer.ints(len(index.points), index.opts.Trees, index.opts.LeafSize, index.opts.Candidates, len(index.nodes))
+		writer.ints(index.roots...)
+		for _, node := range index.nodes {
+			writer.ints(node.left, node.right, len(node.normal), len(node.vectors))
+			writer.floats(node.offset)
+			writer.floats(node.normal...)
+			writer.ints(node.vectors...)
+		}
+	case *VPTree[Vector]:
+		writer.magic(vpTreeMagic)
+		writer.ints(len(index.order), len(index.nodes))
+		writer.ints(index.order...)
+		for _, node := range index.nodes {
+			writer.ints(node.lo, node.hi, node.inside, node.outside)
+			writer.floats(node.threshold)
+		}
+	case *CoverTree:
+		writer.magic(coverTreeMagic)
+		nodes := 0
+		for stack := []*coverNode{index.root}; index.root != nil && len(stack) > 0; nodes++ {
+			node := stack[len(stack)-1]
+			stack = append(stack[:len(stack)-1], node.children...)
+		}
+		writer.ints(len(index.data), nodes)
+		// The nodes are written in preorder, every node followed by its children.
+		for stack := []*coverNode{index.root}; index.root != nil && len(stack) > 0; {
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			writer.ints(node.index, node.level, len(node.children), len(node.duplicates))
+			writer.floats(node.maxDistance)
+			writer.ints(node.duplicates...)
+			for c := len(node.children) - 1; c >= 0; c-- {
+				stack = append(stack, node.children[c])
+			}
+		}
+	case *GridIndex:
+		writer.magic(gridMagic)
+		writer.ints(len(index.data), index.dimension, len(index.cells))
+		writer.floats(index.cellSize)
+		for cell, vectors := range index.cells {
+			writer.ints(cell[:]...)
+			writer.ints(len(vectors))
+			writer.ints(vectors...)
+		}
+	case *RTree:
+		writer.magic(rtreeMagic)
+		writer.ints(len(index.rects), len(index.nodes), index.root)
+		for _, rect := range index.rects {
+			writer.floats(rect.MinX, rect.MinY, rect.MaxX, rect.MaxY)
+		}
+		for _, node := range index.nodes {
+			leaf := 0
+			if node.leaf {
+				leaf = 1
+			}
+			writer.ints(leaf, len(node.children))
+			writer.floats(node.bounds.MinX, node.bounds.MinY, node.bounds.MaxX, node.bounds.MaxY)
+			writer.ints(node.children...)
+		}
+	default:
+		return fmt.Errorf("Persisting an index of type %T is not supported", index)
+	}
+	return writer.err
+}
+
+// MappedIndex is a NeighbourIndex memory-mapped from a file written by WriteIndex, whose index arrays refer to the mapped file instead of being copied,
+// such that serving processes open large indexes without decoding them. Memory-mapping is only available on little-endian Unix systems, elsewhere the file is read into memory.
+type MappedIndex struct {
+	NeighbourIndex
+	unmap func() error
+}
+
+// OpenIndex will memory-map the index at the path, which must have been written by WriteIndex and built over the dataset with the metric,
+// validating it as thoroughly as the Read functions do. The source chooses the layers of vectors inserted into an HNSW later on, which copies the links it changes.
+// The index must be closed once it is no longer in use.
+func OpenIndex(path string, dataset *Dataset, metric Metric, src rand.Source) (*MappedIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data, unmap, err := mapFile(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	reader := &indexReader{data: data, mapped: true}
+	var index NeighbourIndex
+	switch magic := reader.magic(); magic {
+	case kdTreeMagic:
+		index = decodeKDTree(reader, dataset)
+	case ballTreeMagic:
+		index = decodeBallTree(reader, dataset, metric)
+	case hnswMagic:
+		index = decodeHNSW(reader, dataset, metric, src)
+	case rpForestMagic:
+		index = decodeRPForest(reader, dataset, metric)
+	case vpTreeMagic:
+		index = decodeVPTree(reader, dataset, metric)
+	case coverTreeMagic:
+		index = decodeCoverTree(reader, dataset, metric)
+	case gridMagic:
+		index = decodeGridIndex(reader, dataset)
+	case rtreeMagic:
+		index = decodeRTree(reader)
+	default:
+		if reader.err == nil {
+			reader.err = fmt.Errorf("%s is not an index written by WriteIndex", path)
+		}
+	}
+	if reader.err == nil && len(reader.data) > 0 {
+		reader.err = fmt.Errorf("%s has %d bytes after the index", path, len(reader.data))
+	}
+	if reader.err != nil {
+		unmap()
+		return nil, reader.err
+	}
+	return &MappedIndex{NeighbourIndex: index, unmap: unmap}, nil
+}
+
+// Close will unmap the file, after which the index can no longer be used.
+func (index *MappedIndex) Close() error {
+	index.NeighbourIndex = nil
+	return index.unmap()
+}
+
+// ReadKDTree will read a KDTree written by WriteIndex, which must have been built over the dataset.
+func ReadKDTree(r io.Reader, dataset *Dataset) (*KDTree, error) {
+	reader := &indexReader{r: r}
+	reader.expect(kdTreeMagic)
+	tree := decodeKDTree(reader, dataset)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return tree, nil
+}
+
+// ReadBallTree will read a BallTree written by WriteIndex, which must have been built over the dataset with the metric.
+func ReadBallTree(r io.Reader, dataset *Dataset, metric Metric) (*BallTree, error) {
+	reader := &indexReader{r: r}
+	reader.expect(ballTreeMagic)
+	tree := decodeBallTree(reader, dataset, metric)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return tree, nil
+}
+
+// ReadHNSW will read an HNSW written by WriteIndex, which must have been built over the dataset with the metric.
+// The source chooses the layers of vectors inserted later on.
+func ReadHNSW(r io.Reader, dataset *Dataset, metric Metric, src rand.Source) (*HNSW, error) {
+	reader := &indexReader{r: r}
+	reader.expect(hnswMagic)
+	index := decodeHNSW(reader, dataset, metric, src)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return index, nil
+}
+
+// ReadRPForest will read an RPForest written by WriteIndex, which must have been built over the dataset with the metric.
+func ReadRPForest(r io.Reader, dataset *Dataset, metric Metric) (*RPForest, error) {
+	reader := &indexReader{r: r}
+	reader.expect(rpForestMagic)
+	forest := decodeRPForest(reader, dataset, metric)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return forest, nil
+}
+
+// ReadVPTree will read a VPTree of vectors written by WriteIndex, which must have been built over the vectors of the dataset with the metric.
+func ReadVPTree(r io.Reader, dataset *Dataset, metric Metric) (*VPTree[Vector], error) {
+	reader := &indexReader{r: r}
+	reader.expect(vpTreeMagic)
+	tree := decodeVPTree(reader, dataset, metric)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return tree, nil
+}
+
+// ReadCoverTree will read a CoverTree written by WriteIndex, which must have been built over the dataset with the metric.
+func ReadCoverTree(r io.Reader, dataset *Dataset, metric Metric) (*CoverTree, error) {
+	reader := &indexReader{r: r}
+	reader.expect(coverTreeMagic)
+	tree := decodeCoverTree(reader, dataset, metric)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return tree, nil
+}
+
+// ReadGridIndex will read a GridIndex written by WriteIndex, which must have been built over the dataset.
+func ReadGridIndex(r io.Reader, dataset *Dataset) (*GridIndex, error) {
+	reader := &indexReader{r: r}
+	reader.expect(gridMagic)
+	index := decodeGridIndex(reader, dataset)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return index, nil
+}
+
+// ReadRTree will read an RTree written by WriteIndex together with its rectangles.
+func ReadRTree(r io.Reader) (*RTree, error) {
+	reader := &indexReader{r: r}
+	reader.expect(rtreeMagic)
+	tree := decodeRTree(reader)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return tree, nil
+}
+
+func decodeKDTree(reader *indexReader, dataset *Dataset) *KDTree {
+	n := reader.size(dataset)
+	tree := &KDTree{points: make([][]float64, n)}
+	tree.order, tree.splits = reader.permutation(n), reader.ints(n)
+	if reader.err != nil {
+		return nil
+	}
+	for i, vec := range dataset.data {
+		tree.points[i] = components(vec)
+	}
+	for _, split := range tree.splits {
+		if split < 0 || split >= len(tree.points[0]) {
+			reader.fail(errors.New("The KD-tree splits on a component the vectors of the dataset do not have"))
+			return nil
+		}
+	}
+	return tree
+}
+
+func decodeBallTree(reader *indexReader, dataset *Dataset, metric Metric) *BallTree {
+	n := reader.size(dataset)
+	nodes := reader.count(2*n, "nodes")
+	if reader.err == nil && n > 0 && nodes == 0 {
+		reader.fail(errors.New("The ball tree has no nodes"))
+	}
+	tree := &BallTree{data: dataset.data, metric: metric, order: reader.permutation(n), nodes: make([]ballNode, nodes)}
+	for i := range tree.nodes {
+		bounds := reader.ints(4)
+		radius, centre := reader.floats(1), reader.floats(Dimension(dataset.data[0]))
+		if reader.err != nil {
+			return nil
+		}
+		node := ballNode{lo: bounds[0], hi: bounds[1], left: bounds[2], right: bounds[3], radius: radius[0]}
+		if node.lo < 0 || node.hi > n || node.lo >= node.hi || !children(i, nodes, node.left, node.right) || !(node.radius >= 0) {
+			reader.fail(fmt.Errorf("Node %d of the ball tree is corrupt", i))
+			return nil
+		}
+		node.centre = dataset.data[0].Creator().New(func(d int) float64 { return centre[d] })
+		tree.nodes[i] = node
+	}
+	return tree
+}
+
+func decodeHNSW(reader *indexReader, dataset *Dataset, metric Metric, src rand.Source) *HNSW {
+	n := reader.size(dataset)
+	settings := reader.ints(4)
+	if reader.err != nil {
+		return nil
+	}
+	index := &HNSW{metric: metric, rng: rand.New(src), vectors: dataset.Vectors(), links: make([][][]int, n), entry: settings[3]}
+	index.opts = HNSWOptions{Links: settings[0], ConstructionBreadth: settings[1], SearchBreadth: settings[2]}.withDefaults()
+	for i := range index.links {
+		index.links[i] = make([][]int, reader.count(64, "layers"))
+		if reader.err == nil && len(index.links[i]) == 0 {
+			reader.fail(fmt.Errorf("Vector %d of the HNSW is on no layer", i))
+		}
+		for layer := range index.links[i] {
+			index.links[i][layer] = reader.indices(reader.count(n, "links"), n)
+		}
+		if reader.err != nil {
+			return nil
+		}
+	}
+	top := 0
+	for i, layers := range index.links {
+		top = max(top, len(layers))
+		for layer, links := range layers {
+			for _, j := range links {
+				if len(index.links[j]) <= layer {
+					reader.fail(fmt.Errorf("Vector %d of the HNSW links to vector %d on layer %d, which is not on that layer", i, j, layer))
+					return nil
+				}
+			}
+		}
+	}
+	if (n == 0 && index.entry != 0) || (n > 0 && (index.entry < 0 || index.entry >= n || len(index.links[index.entry]) != top)) {
+		reader.fail(errors.New("The entry point of the HNSW is corrupt"))
+		return nil
+	}
+	return index
+}
+
+func decodeRPForest(reader *indexReader, dataset *Dataset, metric Metric) *RPForest {
+	n := reader.size(dataset)
+	settings := reader.ints(3)
+	if reader.err != nil {
+		return nil
+	}
+	if settings[0] > 1<<16 {
+		reader.fail(fmt.Errorf("Expected at most %d trees but the index has %d", 1<<16, settings[0]))
+		return nil
+	}
+	dimension := 0
+	if n > 0 {
+		dimension = Dimension(dataset.data[0])
+	}
+	forest := &RPForest{data: dataset.data, points: make([][]float64, n), metric: metric}
+	forest.opts = RPForestOptions{Trees: settings[0], LeafSize: settings[1], Candidates: settings[2]}.withDefaults()
+	nodes := reader.count(forest.opts.Trees*2*max(n, 1), "nodes")
+	forest.roots, forest.nodes = reader.indices(forest.opts.Trees, nodes), make([]rpNode, nodes)
+	for i := range forest.nodes {
+		fields := reader.ints(4)
+		if reader.err != nil {
+			return nil
+		}
+		node := rpNode{left: fields[0], right: fields[1]}
+		leaf := node.left == -1 && node.right == -1
+		if !children(i, nodes, node.left, node.right) || (fields[2] != 0 && (leaf || fields[2] != dimension)) || fields[3] < 0 || fields[3] > n || (fields[3] > 0 && !leaf) {
+			reader.fail(fmt.Errorf("Node %d of the random projection forest is corrupt", i))
+			return nil
+		}
+		node.offset = reader.floats(1)[0]
+		if fields[2] > 0 {
+			node.normal = reader.floats(fields[2])
+		}
+		if fields[3] > 0 {
+			node.vectors = reader.indices(fields[3], n)
+		}
+		forest.nodes[i] = node
+	}
+	for i, vec := range dataset.data {
+		forest.points[i] = components(vec)
+	}
+	return forest
+}
+
+func decodeVPTree(reader *indexReader, dataset *Dataset, metric Metric) *VPTree[Vector] {
+	n := reader.size(dataset)
+	nodes := reader.count(2*n, "nodes")
+	if reader.err == nil && n > 0 && nodes == 0 {
+		reader.fail(errors.New("The vantage-point tree has no nodes"))
+	}
+	tree := &VPTree[Vector]{items: dataset.data, metric: metric, order: reader.permutation(n), nodes: make([]vpNode, nodes)}
+	for i := range tree.nodes {
+		bounds, threshold := reader.ints(4), reader.floats(1)
+		if reader.err != nil {
+			return nil
+		}
+		node := vpNode{lo: bounds[0], hi: bounds[1], inside: bounds[2], outside: bounds[3], threshold: threshold[0]}
+		if node.lo < 0 || node.hi > n || node.lo > node.hi || !children(i, nodes, node.inside, node.outside) || (node.inside >= 0 && node.lo == node.hi) || math.IsNaN(node.threshold) {
+			reader.fail(fmt.Errorf("Node %d of the vantage-point tree is corrupt", i))
+			return nil
+		}
+		tree.nodes[i] = node
+	}
+	return tree
+}
+
+func decodeCoverTree(reader *indexReader, dataset *Dataset, metric Metric) *CoverTree {
+	n := reader.size(dataset)
+	nodes := reader.count(n, "nodes")
+	if reader.err == nil && n > 0 && nodes == 0 {
+		reader.fail(errors.New("The cover tree has no nodes"))
+	}
+	tree := &CoverTree{data: dataset.data, metric: metric}
+	covered := make([]bool, n)
+	cover := func(i int) {
+		if reader.err == nil && covered[i] {
+			reader.fail(fmt.Errorf("The cover tree holds vector %d more than once", i))
+		}
+		covered[i] = true
+	}
+	// The stack holds the nodes whose children have not all been read yet, together with the number of children still to read.
+	type pending struct {
+		node     *coverNode
+		children int
+	}
+	var stack []pending
+	for read := 0; read < nodes && reader.err == nil; read++ {
+		fields, maxDistance := reader.ints(4), reader.floats(1)
+		node := &coverNode{index: fields[0], level: fields[1], maxDistance: maxDistance[0]}
+		if reader.err != nil {
+			return nil
+		}
+		if node.index < 0 || node.index >= n || fields[2] < 0 || fields[2] > nodes-read-1 || fields[3] < 0 || fields[3] >= n || !(node.maxDistance >= 0) {
+			reader.fail(fmt.Errorf("Node %d of the cover tree is corrupt", read))
+			return nil
+		}
+		cover(node.index)
+		if fields[3] > 0 {
+			node.duplicates = reader.indices(fields[3], n)
+			if reader.err != nil {
+				return nil
+			}
+			for _, i := range node.duplicates {
+				cover(i)
+			}
+		}
+		if read == 0 {
+			tree.root = node
+		} else {
+			if len(stack) == 0 {
+				reader.fail(errors.New("The cover tree has more than one root"))
+				return nil
+			}
+			parent := stack[len(stack)-1].node
+			if node.level >= parent.level {
+				reader.fail(fmt.Errorf("Node %d of the cover tree is not below its parent", read))
+				return nil
+			}
+			parent.children = append(parent.children, node)
+			if stack[len(stack)-1].children--; stack[len(stack)-1].children == 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if fields[2] > 0 {
+			stack = append(stack, pending{node: node, children: fields[2]})
+		}
+	}
+	if reader.err != nil {
+		return nil
+	}
+	if len(stack) > 0 {
+		reader.fail(errors.New("The cover tree is missing children"))
+		return nil
+	}
+	for i := range covered {
+		if !covered[i] {
+			reader.fail(fmt.Errorf("The cover tree does not hold vector %d", i))
+			return nil
+		}
+	}
+	return tree
+}
+
+func decodeGridIndex(reader *indexReader, dataset *Dataset) *GridIndex {
+	n := reader.size(dataset)
+	dimension, cells := reader.ints(1)[0], reader.count(n, "cells")
+	cellSize := reader.floats(1)[0]
+	if reader.err != nil {
+		return nil
+	}
+	index := &GridIndex{data: dataset.data, dimension: dimension, cellSize: cellSize, cells: make(map[gridCell][]int, cells)}
+	if !(cellSize > 0) || math.IsInf(cellSize, 1) || (n > 0 && (index.dimension != Dimension(dataset.data[0]) || index.dimension > len(gridCell{}))) || (n == 0 && index.dimension != 0) {
+		reader.fail(errors.New("The settings of the grid index are corrupt"))
+		return nil
+	}
+	covered := 0
+	for c := 0; c < cells; c++ {
+		var cell gridCell
+		copy(cell[:], reader.ints(len(cell)))
+		vectors := reader.indices(reader.count(n-covered, "vectors"), n)
+		if reader.err != nil {
+			return nil
+		}
+		if _, ok := index.cells[cell]; ok || len(vectors) == 0 {
+			reader.fail(fmt.Errorf("Cell %v of the grid index is corrupt", cell))
+			return nil
+		}
+		for _, i := range vectors {
+			if index.cell(dataset.data[i]) != cell {
+				reader.fail(fmt.Errorf("Vector %d is not in cell %v of the grid index", i, cell))
+				return nil
+			}
+		}
+		if c == 0 {
+			index.lowest, index.highest = cell, cell
+		}
+		for d := range cell {
+			index.lowest[d], index.highest[d] = min(index.lowest[d], cell[d]), max(index.highest[d], cell[d])
+		}
+		index.cells[cell] = vectors
+		covered += len(vectors)
+	}
+	if covered != n {
+		reader.fail(fmt.Errorf("The grid index holds %d of the %d vectors", covered, n))
+		return nil
+	}
+	return index
+}
+
+func decodeRTree(reader *indexReader) *RTree {
+	fields := reader.ints(3)
+	if reader.err != nil {
+		return nil
+	}
+	n, nodes, root := fields[0], fields[1], fields[2]
+	// Every node has at least one child, so there are at most as many nodes as rectangles.
+	if n < 0 || n > reader.remaining(32) || nodes < 0 || nodes > n || (n == 0) != (root == -1) || root < -1 || root >= nodes {
+		reader.fail(errors.New("The settings of the R-tree are corrupt"))
+		return nil
+	}
+	coordinates := reader.floats(4 * n)
+	if reader.err != nil {
+		return nil
+	}
+	tree := &RTree{rects: make([]Rect, n), nodes: make([]rtreeNode, nodes), root: root}
+	for i := range tree.rects {
+		tree.rects[i] = Rect{coordinates[4*i], coordinates[4*i+1], coordinates[4*i+2], coordinates[4*i+3]}
+	}
+	for i := range tree.nodes {
+		fields, bounds := reader.ints(2), reader.floats(4)
+		if reader.err != nil {
+			return nil
+		}
+		node := rtreeNode{leaf: fields[0] == 1, bounds: Rect{bounds[0], bounds[1], bounds[2], bounds[3]}}
+		// Nodes are packed bottom-up, so the children of a node precede it.
+		size := i
+		if node.leaf {
+			size = n
+		}
+		if fields[0] < 0 || fields[0] > 1 || fields[1] <= 0 || fields[1] > rtreeFanout {
+			reader.fail(fmt.Errorf("Node %d of the R-tree is corrupt", i))
+			return nil
+		}
+		node.children = reader.indices(fields[1], size)
+		if reader.err != nil {
+			return nil
+		}
+		for _, child := range node.children {
+			if bounds := tree.bounds(child, node.leaf); node.bounds.union(bounds) != node.bounds {
+				reader.fail(fmt.Errorf("Node %d of the R-tree does not bound its children", i))
+				return nil
+			}
+		}
+		tree.nodes[i] = node
+	}
+	return tree
+}
+
+// children reports whether the children of the `i`th of the nodes are both absent, or both follow it such that the nodes cannot form a cycle.
+func children(i, nodes, a, b int) bool {
+	if a == -1 && b == -1 {
+		return true
+	}
+	return a > i && a < nodes && b > i && b < nodes
+}
+
+type indexWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (writer *indexWriter) magic(magic [8]byte) {
+	if writer.err == nil {
+		_, writer.err = writer.w.Write(magic[:])
+	}
+}
+
+func (writer *indexWriter) ints(values ...int) {
+	if writer.err != nil || len(values) == 0 {
+		return
+	}
+	buffer := make([]byte, 8*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint64(buffer[8*i:], uint64(int64(value)))
+	}
+	_, writer.err = writer.w.Write(buffer)
+}
+
+func (writer *indexWriter) floats(values ...float64) {
+	if writer.err != nil || len(values) == 0 {
+		return
+	}
+	buffer := make([]byte, 8*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint64(buffer[8*i:], math.Float64bits(value))
+	}
+	_, writer.err = writer.w.Write(buffer)
+}
+
+// indexReader reads what an indexWriter wrote, either from a reader or from the mapped bytes of a file,
+// retaining the first error such that a corrupt or truncated index is detected once after reading.
+type indexReader struct {
+	r io.Reader
+	// data holds the mapped bytes that have not been read yet, which integers and floats refer to without copying when mapped is set.
+	data   []byte
+	mapped bool
+	err    error
+}
+
+// failedFields is the number of zeros a failed read returns at most, which covers the few fields a decoder reads at once before checking the error.
+const failedFields = 8
+
+// readChunk is the largest number of words read from a reader at once, such that a corrupt count fails at the end of the input instead of allocating up front.
+const readChunk = 1 << 16
+
+func (reader *indexReader) fail(err error) {
+	if reader.err == nil {
+		reader.err = err
+	}
+}
+
+// magic reads the magic number of the index.
+func (reader *indexReader) magic() [8]byte {
+	var magic [8]byte
+	if reader.mapped {
+		if len(reader.data) < len(magic) {
+			reader.fail(io.ErrUnexpectedEOF)
+			return magic
+		}
+		copy(magic[:], reader.data)
+		reader.data = reader.data[len(magic):]
+	} else if _, err := io.ReadFull(reader.r, magic[:]); err != nil {
+		reader.fail(err)
+	}
+	return magic
+}
+
+// expect checks the magic number of the index.
+func (reader *indexReader) expect(magic [8]byte) {
+	if read := reader.magic(); reader.err == nil && read != magic {
+		reader.fail(fmt.Errorf("Expected an index starting with %q but got %q", magic[:4], read[:4]))
+	}
+}
+
+// size checks that the index covers as many vectors as the dataset, returning the number of vectors.
+func (reader *indexReader) size(dataset *Dataset) int {
+	n := reader.ints(1)
+	if reader.err == nil && n[0] != dataset.Count() {
+		reader.fail(fmt.Errorf("The index covers %d vectors but the dataset has %d vectors", n[0], dataset.Count()))
+	}
+	if reader.err != nil {
+		return 0
+	}
+	return n[0]
+}
+
+// remaining returns the largest number of items of the size in bytes that the rest of the index can hold,
+// such that a count read from a mapped file is bounded before allocating, while a reader fails at the end of its input instead.
+func (reader *indexReader) remaining(size int) int {
+	if reader.mapped {
+		return len(reader.data) / size
+	}
+	return math.MaxInt / size
+}
+
+// read returns the next `n` words.
+func (reader *indexReader) read(n int) []byte {
+	if reader.err != nil {
+		return nil
+	}
+	if n < 0 || n > math.MaxInt/8 {
+		reader.fail(fmt.Errorf("Expected a number of words between 0 and %d but got %d", math.MaxInt/8, n))
+		return nil
+	}
+	if reader.mapped {
+		if n > len(reader.data)/8 {
+			reader.fail(io.ErrUnexpectedEOF)
+			return nil
+		}
+		words := reader.data[: 8*n : 8*n]
+		reader.data = reader.data[8*n:]
+		return words
+	}
+	var buffer []byte
+	for len(buffer) < 8*n {
+		chunk := make([]byte, 8*min(n-len(buffer)/8, readChunk))
+		if _, err := io.ReadFull(reader.r, chunk); err != nil {
+			reader.fail(err)
+			return nil
+		}
+		buffer = append(buffer, chunk...)
+	}
+	return buffer
+}
+
+// aligned reports whether the words can be used as integers and floats without copying them.
+func aligned(words []byte) bool {
+	return len(words) > 0 && strconv.IntSize == 64 && littleEndian() && uintptr(unsafe.Pointer(&words[0]))%8 == 0
+}
+
+func (reader *indexReader) ints(n int) []int {
+	words := reader.read(n)
+	if reader.mapped && aligned(words) {
+		return unsafe.Slice((*int)(unsafe.Pointer(&words[0])), n)
+	}
+	if reader.err != nil {
+		return make([]int, min(max(n, 0), failedFields))
+	}
+	values := make([]int, n)
+	for i := range values {
+		values[i] = int(int64(binary.LittleEndian.Uint64(words[8*i:])))
+	}
+	return values
+}
+
+func (reader *indexReader) floats(n int) []float64 {
+	words := reader.read(n)
+	if reader.mapped && aligned(words) {
+		return unsafe.Slice((*float64)(unsafe.Pointer(&words[0])), n)
+	}
+	if reader.err != nil {
+		return make([]float64, min(max(n, 0), failedFields))
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(words[8*i:]))
+	}
+	return values
+}
+
+// count reads a number of things, which must be at most the limit.
+func (reader *indexReader) count(limit int, what string) int {
+	n := reader.ints(1)[0]
+	if reader.err == nil && (n < 0 || n > limit) {
+		reader.fail(fmt.Errorf("Expected at most %d %s but the index has %d", limit, what, n))
+	}
+	if reader.err != nil {
+		return 0
+	}
+	return n
+}
+
+// indices reads `n` indices, which must all be smaller than the size.
+func (reader *indexReader) indices(n, size int) []int {
+	indices := reader.ints(n)
+	for _, i := range indices {
+		if reader.err == nil && (i < 0 || i >= size) {
+			reader.fail(fmt.Errorf("Expected indices below %d but the index has %d", size, i))
+		}
+	}
+	return indices
+}
+
+// permutation reads `n` indices, which must hold every index below `n` exactly once.
+func (reader *indexReader) permutation(n int) []int {
+	indices, seen := reader.indices(n, n), make([]bool, n)
+	for _, i := range indices {
+		if reader.err != nil {
+			break
+		}
+		if seen[i] {
+			reader.fail(fmt.Errorf("The index holds vector %d more than once", i))
+		}
+		seen[i] = true
+	}
+	return indices
+}
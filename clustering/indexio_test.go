@@ -0,0 +1,221 @@
+package clustering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// indexTestCase builds an index over a dataset and reads it back from a reader.
+type indexTestCase struct {
+	name  string
+	build func(dataset *Dataset) NeighbourIndex
+	read  func(r io.Reader, dataset *Dataset) (NeighbourIndex, error)
+}
+
+var indexTestCases = []indexTestCase{
+	{
+		name: "KDTree",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewKDTree(dataset)
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadKDTree(r, dataset)
+		},
+	},
+	{
+		name: "BallTree",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewBallTree(dataset, Euclidean)
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadBallTree(r, dataset, Euclidean)
+		},
+	},
+	{
+		name: "HNSW",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return BuildHNSW(dataset, Euclidean, HNSWOptions{}, rand.NewSource(1))
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadHNSW(r, dataset, Euclidean, rand.NewSource(1))
+		},
+	},
+	{
+		name: "RPForest",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewRPForest(dataset, Euclidean, RPForestOptions{}, rand.NewSource(1))
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadRPForest(r, dataset, Euclidean)
+		},
+	},
+	{
+		name: "VPTree",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewVPTree(dataset.Vectors(), Euclidean, rand.NewSource(1))
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadVPTree(r, dataset, Euclidean)
+		},
+	},
+	{
+		name: "CoverTree",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewCoverTree(dataset, Euclidean)
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadCoverTree(r, dataset, Euclidean)
+		},
+	},
+	{
+		name: "GridIndex",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewGridIndex(dataset, 2)
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadGridIndex(r, dataset)
+		},
+	},
+	{
+		name: "RTree",
+		build: func(dataset *Dataset) NeighbourIndex {
+			return NewPointRTree(dataset)
+		},
+		read: func(r io.Reader, dataset *Dataset) (NeighbourIndex, error) {
+			return ReadRTree(r)
+		},
+	},
+}
+
+func indexTestDataset() *Dataset {
+	rng := rand.New(rand.NewSource(1))
+	var dataset Dataset
+	for i := 0; i < 200; i++ {
+		dataset.Append(VectorN{rng.NormFloat64() * 10, rng.NormFloat64() * 10})
+	}
+	// A duplicate ends up among the duplicates of a node of a cover tree.
+	dataset.Append(dataset.data[3])
+	return &dataset
+}
+
+func queryResults(index NeighbourIndex) string {
+	query := VectorN{1, 2}
+	return fmt.Sprint(index.Nearest(query, 7), index.WithinRadius(query, 5))
+}
+
+func TestIndexRoundTrip(t *testing.T) {
+	dataset := indexTestDataset()
+	for _, test := range indexTestCases {
+		t.Run(test.name, func(t *testing.T) {
+			index := test.build(dataset)
+			var buffer bytes.Buffer
+			if err := WriteIndex(&buffer, index); err != nil {
+				t.Fatal(err)
+			}
+			read, err := test.read(bytes.NewReader(buffer.Bytes()), dataset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := queryResults(read), queryResults(index); got != want {
+				t.Errorf("Read index answers %s, expected %s", got, want)
+			}
+
+			path := filepath.Join(t.TempDir(), "index")
+			if err := os.WriteFile(path, buffer.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			mapped, err := OpenIndex(path, dataset, Euclidean, rand.NewSource(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mapped.Close()
+			if got, want := queryResults(mapped), queryResults(index); got != want {
+				t.Errorf("Mapped index answers %s, expected %s", got, want)
+			}
+		})
+	}
+}
+
+func TestIndexRejectsCorruptInput(t *testing.T) {
+	dataset := indexTestDataset()
+	hostile := []int64{-1, 0, 1, 2, 1 << 40, 1 << 61, 150, -1 << 62}
+	for _, test := range indexTestCases {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := WriteIndex(&buffer, test.build(dataset)); err != nil {
+				t.Fatal(err)
+			}
+			written := buffer.Bytes()
+			path := filepath.Join(t.TempDir(), "index")
+			for _, length := range []int{0, 3, 8, 12, 16, len(written) / 2, len(written) - 1} {
+				if _, err := test.read(bytes.NewReader(written[:length]), dataset); err == nil {
+					t.Errorf("Reading the first %d of %d bytes succeeded", length, len(written))
+				}
+			}
+			// Every word is overwritten with hostile values, which must be rejected or yield an index that answers queries.
+			rng := rand.New(rand.NewSource(1))
+			for trial := 0; trial < 500; trial++ {
+				corrupt := append([]byte(nil), written...)
+				word := 1 + rng.Intn(len(corrupt)/8-1)
+				binary.LittleEndian.PutUint64(corrupt[8*word:], uint64(hostile[rng.Intn(len(hostile))]))
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Fatalf("Reading an index with word %d corrupted panicked: %v", word, r)
+						}
+					}()
+					if index, err := test.read(bytes.NewReader(corrupt), dataset); err == nil {
+						queryResults(index)
+					}
+					if err := os.WriteFile(path, corrupt, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					if index, err := OpenIndex(path, dataset, Euclidean, rand.NewSource(1)); err == nil {
+						queryResults(index)
+						index.Close()
+					}
+				}()
+			}
+		})
+	}
+}
+
+func TestReadRTreeRejectsHostileHeader(t *testing.T) {
+	header := func(fields ...int64) []byte {
+		data := append([]byte(nil), rtreeMagic[:]...)
+		for _, field := range fields {
+			data = binary.LittleEndian.AppendUint64(data, uint64(field))
+		}
+		return data
+	}
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"huge rectangle count", header(1<<61, 1, 0)},
+		{"overflowing rectangle count", header(1<<62+1, 1, 0)},
+		{"negative rectangle count", header(-1, 0, -1)},
+		{"more nodes than rectangles", header(1, 2, 0)},
+		{"missing root", header(1, 1, -1)},
+		{"truncated rectangles", header(4, 1, 0)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ReadRTree(bytes.NewReader(test.data)); err == nil {
+				t.Error("Reading the R-tree succeeded")
+			}
+			path := filepath.Join(t.TempDir(), "index")
+			if err := os.WriteFile(path, test.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := OpenIndex(path, &Dataset{}, Euclidean, rand.NewSource(1)); err == nil {
+				t.Error("Opening the R-tree succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package clustering
+
+import "time"
+
+// Instrumentation receives measurements of the clustering algorithms run on the dataset it is attached to, e.g., to export them as metrics of a service, see SetInstrumentation.
+// Every measurement names the algorithm taking it: "kmeans", "kmedoids", "fuzzy-c-means", "gaussian-mixture" or, for Dataset.FindClusters, "predict".
+// An Instrumentation attached to several datasets may be called concurrently.
+type Instrumentation interface {
+	// Iteration is called after every iteration of an iterative algorithm with the number of the iteration, counting from 1.
+	Iteration(algorithm string, iteration int)
+	// Distances is called with the number of distances an algorithm computed since it last reported, e.g., once every iteration.
+	Distances(algorithm string, count int)
+	// Assignment is called with the number of vectors an algorithm assigned to clusters at once, e.g., once every iteration, and the time that took.
+	Assignment(algorithm string, vectors int, elapsed time.Duration)
+}
+
+// SetInstrumentation will attach the instrumentation to this dataset such that the clustering algorithms run on the dataset report their measurements to it.
+// Datasets derived from this dataset, e.g., by Filter or Sample, are not instrumented and a nil instrumentation detaches it.
+func (dataset *Dataset) SetInstrumentation(instrumentation Instrumentation) {
+	dataset.instrumentation = instrumentation
+}
+
+// Instrumentation returns the instrumentation attached to this dataset, or nil if there is none.
+func (dataset *Dataset) Instrumentation() Instrumentation {
+	return dataset.instrumentation
+}
+
+// instrument returns the attached instrumentation, or one that discards all measurements if there is none.
+func (dataset *Dataset) instrument() Instrumentation {
+	if dataset.instrumentation == nil {
+		return discardInstrumentation{}
+	}
+	return dataset.instrumentation
+}
+
+type discardInstrumentation struct{}
+
+func (discardInstrumentation) Iteration(string, int)                 {}
+func (discardInstrumentation) Distances(string, int)                 {}
+func (discardInstrumentation) Assignment(string, int, time.Duration) {}
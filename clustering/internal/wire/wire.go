@@ -0,0 +1,262 @@
+// Package wire reads and writes the protobuf wire format of the messages of the clustering packages,
+// such that they interoperate with code generated from their .proto files without depending on generated code.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The wire types of the protobuf encoding.
+const (
+	Varint  = 0
+	Fixed64 = 1
+	Bytes   = 2
+	Fixed32 = 5
+)
+
+// Writer appends fields in the protobuf wire format, omitting scalar fields holding their default value like proto3 does.
+type Writer struct {
+	// Buffer holds the encoded fields.
+	Buffer []byte
+}
+
+// Tag will write the tag of a field of the wire type, the value must be written right after it.
+func (writer *Writer) Tag(field, wireType int) {
+	writer.Buffer = binary.AppendUvarint(writer.Buffer, uint64(field<<3|wireType))
+}
+
+// Varint will write an integer field.
+func (writer *Writer) Varint(field int, value int64) {
+	if value != 0 {
+		writer.Tag(field, Varint)
+		writer.Buffer = binary.AppendUvarint(writer.Buffer, uint64(value))
+	}
+}
+
+// Double will write a double field.
+func (writer *Writer) Double(field int, value float64) {
+	if value != 0 || math.Signbit(value) {
+		writer.Tag(field, Fixed64)
+		writer.Buffer = binary.LittleEndian.AppendUint64(writer.Buffer, math.Float64bits(value))
+	}
+}
+
+// Doubles will write the values as a packed repeated field.
+func (writer *Writer) Doubles(field int, values []float64) {
+	if len(values) == 0 {
+		return
+	}
+	writer.Tag(field, Bytes)
+	writer.Buffer = binary.AppendUvarint(writer.Buffer, uint64(8*len(values)))
+	for _, value := range values {
+		writer.Buffer = binary.LittleEndian.AppendUint64(writer.Buffer, math.Float64bits(value))
+	}
+}
+
+// Float will write a float field.
+func (writer *Writer) Float(field int, value float32) {
+	if value != 0 || math.Signbit(float64(value)) {
+		writer.Tag(field, Fixed32)
+		writer.Buffer = binary.LittleEndian.AppendUint32(writer.Buffer, math.Float32bits(value))
+	}
+}
+
+// Ints will write the values as a packed repeated field.
+func (writer *Writer) Ints(field int, values ...int64) {
+	if len(values) == 0 {
+		return
+	}
+	var packed []byte
+	for _, value := range values {
+		packed = binary.AppendUvarint(packed, uint64(value))
+	}
+	writer.Bytes(field, packed)
+}
+
+// Bytes will write a length-delimited field.
+func (writer *Writer) Bytes(field int, value []byte) {
+	if len(value) > 0 {
+		writer.Tag(field, Bytes)
+		writer.Buffer = binary.AppendUvarint(writer.Buffer, uint64(len(value)))
+		writer.Buffer = append(writer.Buffer, value...)
+	}
+}
+
+// String will write a string field.
+func (writer *Writer) String(field int, value string) {
+	writer.Bytes(field, []byte(value))
+}
+
+// Message will write an embedded message, which unlike a scalar field is also written when it is empty.
+func (writer *Writer) Message(field int, message Writer) {
+	writer.Tag(field, Bytes)
+	writer.Buffer = binary.AppendUvarint(writer.Buffer, uint64(len(message.Buffer)))
+	writer.Buffer = append(writer.Buffer, message.Buffer...)
+}
+
+// Reader reads the fields of a message in the protobuf wire format, skipping unknown fields and retaining the first error.
+type Reader struct {
+	data []byte
+	err  error
+}
+
+// NewReader will create a Reader over the fields of the encoded message.
+func NewReader(data []byte) Reader {
+	return Reader{data: data}
+}
+
+var errTruncated = errors.New("The protobuf message is truncated")
+
+// Err returns the first error the reader ran into, if any.
+func (reader *Reader) Err() error {
+	return reader.err
+}
+
+// Fail will record the error, unless the reader already ran into an error, and end the message.
+func (reader *Reader) Fail(err error) {
+	if reader.err == nil {
+		reader.err = err
+	}
+}
+
+// Next returns the number and wire type of the next field, or false at the end of the message or after an error.
+func (reader *Reader) Next() (int, int, bool) {
+	if reader.err != nil || len(reader.data) == 0 {
+		return 0, 0, false
+	}
+	tag := reader.uvarint()
+	if reader.err != nil {
+		return 0, 0, false
+	}
+	if tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+		reader.err = fmt.Errorf("Invalid protobuf field number %d", tag>>3)
+		return 0, 0, false
+	}
+	return int(tag >> 3), int(tag & 7), true
+}
+
+func (reader *Reader) uvarint() uint64 {
+	value, n := binary.Uvarint(reader.data)
+	if n <= 0 {
+		reader.err = errTruncated
+		return 0
+	}
+	reader.data = reader.data[n:]
+	return value
+}
+
+// expect checks the wire type of a field.
+func (reader *Reader) expect(wireType, expected int) bool {
+	if reader.err == nil && wireType != expected {
+		reader.err = fmt.Errorf("Expected wire type %d but got wire type %d", expected, wireType)
+	}
+	return reader.err == nil
+}
+
+// Varint returns the value of an integer field.
+func (reader *Reader) Varint(wireType int) int64 {
+	if !reader.expect(wireType, Varint) {
+		return 0
+	}
+	return int64(reader.uvarint())
+}
+
+func (reader *Reader) fixed(n int) []byte {
+	if len(reader.data) < n {
+		reader.err = errTruncated
+		return nil
+	}
+	value := reader.data[:n]
+	reader.data = reader.data[n:]
+	return value
+}
+
+// Double returns the value of a double field.
+func (reader *Reader) Double(wireType int) float64 {
+	if !reader.expect(wireType, Fixed64) {
+		return 0
+	}
+	if value := reader.fixed(8); value != nil {
+		return math.Float64frombits(binary.LittleEndian.Uint64(value))
+	}
+	return 0
+}
+
+func (reader *Reader) bytes() []byte {
+	n := reader.uvarint()
+	if reader.err != nil {
+		return nil
+	}
+	if n > uint64(len(reader.data)) {
+		reader.err = errTruncated
+		return nil
+	}
+	return reader.fixed(int(n))
+}
+
+// Doubles appends the values of a repeated double field, which may be packed or not.
+func (reader *Reader) Doubles(wireType int, values []float64) []float64 {
+	if wireType == Fixed64 {
+		return append(values, reader.Double(wireType))
+	}
+	if !reader.expect(wireType, Bytes) {
+		return values
+	}
+	packed := reader.bytes()
+	if len(packed)%8 != 0 {
+		reader.err = errors.New("The packed doubles of the protobuf message are truncated")
+		return values
+	}
+	for i := 0; i < len(packed); i += 8 {
+		values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(packed[i:])))
+	}
+	return values
+}
+
+// Ints appends the values of a repeated integer field, which may be packed or not.
+func (reader *Reader) Ints(wireType int, values []int64) []int64 {
+	if wireType == Varint {
+		return append(values, reader.Varint(wireType))
+	}
+	if !reader.expect(wireType, Bytes) {
+		return values
+	}
+	packed := Reader{data: reader.bytes(), err: reader.err}
+	for packed.err == nil && len(packed.data) > 0 {
+		values = append(values, int64(packed.uvarint()))
+	}
+	reader.Adopt(packed)
+	return values
+}
+
+// Message returns a reader over an embedded message, whose errors are taken over by Adopt.
+func (reader *Reader) Message(wireType int) Reader {
+	if !reader.expect(wireType, Bytes) {
+		return Reader{err: reader.err}
+	}
+	return Reader{data: reader.bytes(), err: reader.err}
+}
+
+// Adopt will take over the error of a reader over an embedded message.
+func (reader *Reader) Adopt(message Reader) {
+	reader.Fail(message.err)
+}
+
+// Skip will skip the value of an unknown field.
+func (reader *Reader) Skip(wireType int) {
+	switch wireType {
+	case Varint:
+		reader.uvarint()
+	case Fixed64:
+		reader.fixed(8)
+	case Bytes:
+		reader.bytes()
+	case Fixed32:
+		reader.fixed(4)
+	default:
+		reader.err = fmt.Errorf("Unsupported protobuf wire type %d", wireType)
+	}
+}
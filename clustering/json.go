@@ -0,0 +1,119 @@
+package clustering
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSONOptions configures how datasets are read from JSON.
+type JSONOptions struct {
+	// Fields are the names of the numeric fields of a record that make up the components of its vector, in order.
+	Fields []string
+}
+
+// Metadata holds the fields of a record that did not become a component of its vector.
+type Metadata map[string]interface{}
+
+// LoadJSON will parse a JSON array of objects into a dataset of VectorNs made up of the selected fields of every object.
+// The remaining fields of the `i`th object are returned as the `i`th Metadata.
+func LoadJSON(r io.Reader, opts JSONOptions) (Dataset, []Metadata, error) {
+	if len(opts.Fields) == 0 {
+		return Dataset{}, nil, errors.New("Expected at least one field to load")
+	}
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil {
+		return Dataset{}, nil, err
+	} else if token != json.Delim('[') {
+		return Dataset{}, nil, fmt.Errorf("Expected a JSON array but got %v", token)
+	}
+	var data []Vector
+	var metadata []Metadata
+	for decoder.More() {
+		vec, meta, err := decodeRecord(decoder, opts.Fields, len(data))
+		if err != nil {
+			return Dataset{}, nil, err
+		}
+		data, metadata = append(data, vec), append(metadata, meta)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return Dataset{}, nil, err
+	}
+	return CreateDataset(data, vectorNCreator(len(opts.Fields))), metadata, nil
+}
+
+// LoadNDJSON will parse newline-delimited JSON objects into a dataset of VectorNs made up of the selected fields of every object.
+// The remaining fields of the `i`th object are returned as the `i`th Metadata.
+func LoadNDJSON(r io.Reader, opts JSONOptions) (Dataset, []Metadata, error) {
+	source := NewNDJSONSource(r, opts)
+	var data []Vector
+	var metadata []Metadata
+	for vec, ok := source.Next(); ok; vec, ok = source.Next() {
+		data, metadata = append(data, vec), append(metadata, source.Metadata())
+	}
+	if err := source.Err(); err != nil {
+		return Dataset{}, nil, err
+	}
+	return CreateDataset(data, vectorNCreator(len(opts.Fields))), metadata, nil
+}
+
+// NDJSONSource is a DataSource parsing newline-delimited JSON objects into VectorNs one object at a time.
+type NDJSONSource struct {
+	decoder  *json.Decoder
+	fields   []string
+	count    int
+	metadata Metadata
+	err      error
+}
+
+// NewNDJSONSource will create a DataSource reading VectorNs made up of the selected fields of the newline-delimited JSON objects read from `r`.
+func NewNDJSONSource(r io.Reader, opts JSONOptions) *NDJSONSource {
+	source := &NDJSONSource{decoder: json.NewDecoder(r), fields: opts.Fields}
+	if len(opts.Fields) == 0 {
+		source.err = errors.New("Expected at least one field to load")
+	}
+	return source
+}
+
+// Next returns the vector parsed from the next object, or false when all objects have been read or an error occurred.
+func (source *NDJSONSource) Next() (Vector, bool) {
+	if source.err != nil || !source.decoder.More() {
+		return nil, false
+	}
+	vec, metadata, err := decodeRecord(source.decoder, source.fields, source.count)
+	if err != nil {
+		source.err = err
+		return nil, false
+	}
+	source.count++
+	source.metadata = metadata
+	return vec, true
+}
+
+// Metadata returns the remaining fields of the object of the vector last returned by Next.
+func (source *NDJSONSource) Metadata() Metadata {
+	return source.metadata
+}
+
+// Err returns the error that ended reading, if any.
+func (source *NDJSONSource) Err() error {
+	return source.err
+}
+
+func decodeRecord(decoder *json.Decoder, fields []string, index int) (VectorN, Metadata, error) {
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil {
+		return nil, nil, fmt.Errorf("Could not decode record %d: %v", index, err)
+	}
+	vec := make(VectorN, len(fields))
+	for i, field := range fields {
+		value, ok := record[field].(float64)
+		if !ok {
+			return nil, nil, fmt.Errorf("Field %q of record %d is missing or not numeric", field, index)
+		}
+		vec[i] = value
+		delete(record, field)
+	}
+	return vec, Metadata(record), nil
+}
@@ -0,0 +1,123 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// kdLeafSize is the number of vectors below which a subtree of a KDTree is scanned instead of split further.
+const kdLeafSize = 8
+
+// KDTree is a NeighbourIndex under Euclidean distances that recursively splits the vectors of a dataset at the median of the component with the largest spread,
+// which prunes most of the dataset from a query in low to medium dimensions. The tree does not follow later changes to the dataset.
+type KDTree struct {
+	points [][]float64
+	// order holds the indices of the vectors such that every subtree covers a contiguous range, which is split at its middle vector by the component in splits.
+	order  []int
+	splits []int
+}
+
+// NewKDTree will build a KDTree over the vectors of the dataset.
+func NewKDTree(dataset *Dataset) *KDTree {
+	tree := &KDTree{points: make([][]float64, dataset.Count()), order: make([]int, dataset.Count()), splits: make([]int, dataset.Count())}
+	for i, vec := range dataset.data {
+		tree.points[i], tree.order[i] = components(vec), i
+	}
+	tree.build(0, len(tree.order))
+	return tree
+}
+
+func (tree *KDTree) build(lo, hi int) {
+	if hi-lo <= kdLeafSize {
+		return
+	}
+	split, spread := 0, -1.0
+	for d := range tree.points[tree.order[lo]] {
+		smallest, largest := math.Inf(1), math.Inf(-1)
+		for _, i := range tree.order[lo:hi] {
+			smallest, largest = math.Min(smallest, tree.points[i][d]), math.Max(largest, tree.points[i][d])
+		}
+		if largest-smallest > spread {
+			split, spread = d, largest-smallest
+		}
+	}
+	vectors := tree.order[lo:hi]
+	sort.Slice(vectors, func(a, b int) bool { return tree.points[vectors[a]][split] < tree.points[vectors[b]][split] })
+	middle := (lo + hi) / 2
+	tree.splits[middle] = split
+	tree.build(lo, middle)
+	tree.build(middle+1, hi)
+}
+
+// search visits every vector that may be within the bound of the query, squared distances are passed to visit and compared with the squared bound.
+func (tree *KDTree) search(lo, hi int, query []float64, visit func(i int, distance float64), bound func() float64) {
+	if hi-lo <= kdLeafSize {
+		for _, i := range tree.order[lo:hi] {
+			visit(i, squaredDistance(query, tree.points[i]))
+		}
+		return
+	}
+	middle := (lo + hi) / 2
+	i, split := tree.order[middle], tree.splits[middle]
+	visit(i, squaredDistance(query, tree.points[i]))
+	diff := query[split] - tree.points[i][split]
+	if diff < 0 {
+		tree.search(lo, middle, query, visit, bound)
+		if diff*diff <= bound() {
+			tree.search(middle+1, hi, query, visit, bound)
+		}
+	} else {
+		tree.search(middle+1, hi, query, visit, bound)
+		if diff*diff <= bound() {
+			tree.search(lo, middle, query, visit, bound)
+		}
+	}
+}
+
+func (tree *KDTree) query(v Vector) []float64 {
+	if len(tree.points) > 0 && Dimension(v) != len(tree.points[0]) {
+		panic(fmt.Sprintf("Expected a query of dimension %d but got %d", len(tree.points[0]), Dimension(v)))
+	}
+	return components(v)
+}
+
+// Nearest returns the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+func (tree *KDTree) Nearest(query Vector, k int) []Neighbour {
+	nearest := &nearestNeighbours{k: max(k, 0)}
+	tree.search(0, len(tree.order), tree.query(query), nearest.offer, nearest.bound)
+	return rootDistances(nearest.sorted())
+}
+
+// WithinRadius returns the vectors within distance `radius` of the query in increasing order of distance.
+func (tree *KDTree) WithinRadius(query Vector, radius float64) []Neighbour {
+	var within []Neighbour
+	if radius < 0 {
+		return within
+	}
+	squaredRadius := radius * radius
+	tree.search(0, len(tree.order), tree.query(query), func(i int, distance float64) {
+		if distance <= squaredRadius {
+			within = append(within, Neighbour{Index: i, Distance: distance})
+		}
+	}, func() float64 { return squaredRadius })
+	sortNeighbours(within)
+	return rootDistances(within)
+}
+
+func squaredDistance(x, y []float64) float64 {
+	result := 0.0
+	for i := range x {
+		diff := x[i] - y[i]
+		result += diff * diff
+	}
+	return result
+}
+
+// rootDistances turns the squared distances of the neighbours into distances.
+func rootDistances(neighbours []Neighbour) []Neighbour {
+	for i := range neighbours {
+		neighbours[i].Distance = math.Sqrt(neighbours[i].Distance)
+	}
+	return neighbours
+}
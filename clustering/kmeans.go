@@ -1,118 +1,196 @@
-package clustering
-
-import (
-	"math/rand"
-)
-
-// Sampler samples a `k`th vector from a vector space with the vector having a maximum length of `max`.
-type Sampler func(k int, max float64) Vector
-
-func uniformSampler(creator VectorCreator) func(int, float64) Vector {
-	return func(k int, max float64) Vector {
-		uniformComponentGenerator := func(_ int) float64 {
-			return rand.Float64()
-		}
-		// Uniformly generates a Vector in a unit cube and checks whether the resulting vector also fits in the unit sphere.
-		// Decent for 3D, but watch out for high dimensions as `P(l > 1)` will increase.
-		var vec Vector
-		for vec = creator.Null(); vec.Length() == 0 || vec.Length() > 1; vec = creator.New(uniformComponentGenerator) {
-		}
-		return vec.MulScalar(max)
-	}
-}
-
-// KMeans will perform K-Means clustering on this dataset with the initial centroids sampled from a uniform sampler.
-func (dataset *Dataset) KMeans(k int) CentroidClusterer {
-	if dataset.IsEmpty() {
-		return []Vector{}
-	}
-	return dataset.KMeansWithSampler(k, uniformSampler(dataset.AsSlice()[0].Creator()))
-}
-
-// KMeansWithCentroids will perform K-Means clustering on this dataset with the initial centroids provided.
-func (dataset *Dataset) KMeansWithCentroids(centroids ...Vector) CentroidClusterer {
-	if dataset.IsEmpty() {
-		return []Vector{}
-	}
-	for maxDelta := 1.0; maxDelta > 0.1; {
-		buckets := collectClusters(dataset, centroids)
-		deltas := createNewCentroids(&centroids, buckets)
-		maxDelta = 0
-		for _, delta := range deltas {
-			if delta > maxDelta {
-				maxDelta = delta
-			}
-		}
-	}
-	return centroids
-}
-
-// KMeansWithSampler will perform K-Means clustering on this dataset with the initial centroids sampled from the provided sampler.
-func (dataset *Dataset) KMeansWithSampler(k int, sampler Sampler) CentroidClusterer {
-	if dataset.IsEmpty() {
-		return []Vector{}
-	}
-	centroids := makeCentroids(k, dataset, sampler)
-	return dataset.KMeansWithCentroids(centroids...)
-}
-
-func makeCentroids(k int, dataset *Dataset, sampler Sampler) []Vector {
-	centroids := make([]Vector, k)
-	maxLen := dataset.Max().Length()
-	for i := 0; i < k; i++ {
-		centroids[i] = sampler(i, maxLen)
-	}
-	return centroids
-}
-
-func collectClusters(dataset *Dataset, centroids []Vector) []bucketCollector {
-	k := len(centroids)
-	buckets := make([]bucketCollector, k)
-	for _, record := range dataset.AsSlice() {
-		cluster := 0
-		distToCluster := centroids[cluster].DistanceTo(record)
-		for k, centroid := range centroids {
-			distToCentroid := record.DistanceTo(centroid)
-			if distToCentroid < distToCluster {
-				cluster = k
-				distToCluster = distToCentroid
-			}
-		}
-		buckets[cluster].Collect(record)
-	}
-	return buckets
-}
-
-func createNewCentroids(centroids *[]Vector, buckets []bucketCollector) []float64 {
-	k := len(*centroids)
-	deltas := make([]float64, k)
-	for i := 0; i < k; i++ {
-		if newCentroid := buckets[i].Average(); newCentroid != nil {
-			deltas[i] = (*centroids)[i].DistanceTo(newCentroid)
-			(*centroids)[i] = newCentroid
-		}
-	}
-	return deltas
-}
-
-type bucketCollector struct {
-	average       Vector
-	normalization int
-}
-
-func (collector *bucketCollector) Collect(vec Vector) {
-	if collector == nil || collector.average == nil {
-		collector.average = vec
-	} else {
-		collector.average = collector.average.Add(vec)
-	}
-	collector.normalization++
-}
-
-func (collector *bucketCollector) Average() Vector {
-	if collector == nil || collector.average == nil {
-		return nil
-	}
-
-	return collector.average.MulScalar(1 / float64(collector.normalization))
-}
+package clustering
+
+import (
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// Sampler samples a `k`th vector from a vector space with the vector having a maximum length of `max`.
+type Sampler func(k int, max float64) Vector
+
+func uniformSampler(creator VectorCreator) func(int, float64) Vector {
+	return func(k int, max float64) Vector {
+		uniformComponentGenerator := func(_ int) float64 {
+			return rand.Float64()
+		}
+		// Uniformly generates a Vector in a unit cube and checks whether the resulting vector also fits in the unit sphere.
+		// Decent for 3D, but watch out for high dimensions as `P(l > 1)` will increase.
+		var vec Vector
+		for vec = creator.Null(); vec.Length() == 0 || vec.Length() > 1; vec = creator.New(uniformComponentGenerator) {
+		}
+		return vec.MulScalar(max)
+	}
+}
+
+// KMeans will perform K-Means clustering on this dataset with the initial centroids sampled from a uniform sampler.
+func (dataset *Dataset) KMeans(k int) CentroidClusterer {
+	if dataset.IsEmpty() {
+		return []Vector{}
+	}
+	return dataset.KMeansWithSampler(k, uniformSampler(dataset.data[0].Creator()))
+}
+
+// KMeansWithCentroids will perform K-Means clustering on this dataset with the initial centroids provided.
+// Every centroid is the weighted average of the vectors assigned to it when the dataset is weighted.
+func (dataset *Dataset) KMeansWithCentroids(centroids ...Vector) CentroidClusterer {
+	return dataset.kMeans(centroids, len(centroids), func(centroids []Vector) func(Vector) int {
+		return func(vec Vector) int { return nearestCentroid(centroids, vec) }
+	})
+}
+
+// KMeansWithIndex will perform K-Means clustering on this dataset with the initial centroids provided, like KMeansWithCentroids,
+// but every iteration builds an index over the centroids, e.g., KDTreeIndex, and assigns every vector to its nearest centroid with a query.
+// This is much faster when there are many centroids, provided the index measures Euclidean distances.
+func (dataset *Dataset) KMeansWithIndex(index IndexBuilder, centroids ...Vector) CentroidClusterer {
+	return dataset.kMeans(centroids, 0, func(centroids []Vector) func(Vector) int {
+		built := index(&Dataset{data: centroids, creator: dataset.creator})
+		return func(vec Vector) int { return built.Nearest(vec, 1)[0].Index }
+	})
+}
+
+// kMeans performs K-Means clustering from the initial centroids, where assigner returns the function assigning a vector to its nearest centroid
+// and distances is the number of distances that function computes, reported to the instrumentation unless it is 0.
+func (dataset *Dataset) kMeans(centroids []Vector, distances int, assigner func(centroids []Vector) func(Vector) int) CentroidClusterer {
+	if dataset.IsEmpty() {
+		return []Vector{}
+	}
+	instrumentation, logger := dataset.instrument(), dataset.log()
+	for iteration, maxDelta := 1, 1.0; maxDelta > 0.1; iteration++ {
+		start := time.Now()
+		buckets := collectClusters(dataset, len(centroids), assigner(centroids))
+		instrumentation.Assignment("kmeans", dataset.Count(), time.Since(start))
+		if distances > 0 {
+			instrumentation.Distances("kmeans", distances*dataset.Count())
+		}
+		logEmptyClusters(logger, "kmeans", iteration, buckets)
+		deltas := createNewCentroids(&centroids, buckets)
+		maxDelta = 0
+		for _, delta := range deltas {
+			if delta > maxDelta {
+				maxDelta = delta
+			}
+		}
+		instrumentation.Iteration("kmeans", iteration)
+		logger.Debug("Moved the centroids", "algorithm", "kmeans", "iteration", iteration, "max_delta", maxDelta)
+	}
+	return centroids
+}
+
+// KMeansWithSampler will perform K-Means clustering on this dataset with the initial centroids sampled from the provided sampler.
+func (dataset *Dataset) KMeansWithSampler(k int, sampler Sampler) CentroidClusterer {
+	if dataset.IsEmpty() {
+		return []Vector{}
+	}
+	centroids := makeCentroids(k, dataset, sampler)
+	dataset.log().Debug("Sampled the initial centroids", "algorithm", "kmeans", "centroids", centroids)
+	return dataset.KMeansWithCentroids(centroids...)
+}
+
+// KMeansPlusPlus will perform K-Means clustering on this dataset with the initial centroids chosen by k-means++ seeding:
+// the first centroid is a random vector of the dataset and every next centroid is a vector chosen with probability proportional to its weight times its squared distance to the nearest centroid so far.
+func (dataset *Dataset) KMeansPlusPlus(k int, src rand.Source) CentroidClusterer {
+	if dataset.IsEmpty() || k <= 0 {
+		return []Vector{}
+	}
+	rng := rand.New(src)
+	centroids := make([]Vector, 0, k)
+	nearest := make([]float64, dataset.Count())
+	for i := range nearest {
+		nearest[i] = dataset.Weight(i)
+	}
+	for len(centroids) < k {
+		total := sum(nearest)
+		if total == 0 {
+			// Every vector coincides with a centroid, so no further centroids can be spread out.
+			break
+		}
+		chosen, target := 0, rng.Float64()*total
+		for chosen < len(nearest)-1 && target >= nearest[chosen] {
+			target -= nearest[chosen]
+			chosen++
+		}
+		centroids = append(centroids, dataset.data[chosen])
+		dataset.log().Debug("Chose an initial centroid", "algorithm", "kmeans", "centroid", len(centroids)-1, "index", chosen, "vector", dataset.data[chosen])
+		for i, vec := range dataset.data {
+			distance := Euclidean(vec, dataset.data[chosen])
+			if len(centroids) == 1 || dataset.Weight(i)*distance*distance < nearest[i] {
+				nearest[i] = dataset.Weight(i) * distance * distance
+			}
+		}
+	}
+	return dataset.KMeansWithCentroids(centroids...)
+}
+
+// logEmptyClusters logs every centroid no vector was assigned to, which stays where it is.
+func logEmptyClusters(logger *slog.Logger, algorithm string, iteration int, buckets []bucketCollector) {
+	for i := range buckets {
+		if buckets[i].average == nil {
+			logger.Debug("A cluster lost all its vectors, its centroid stays in place", "algorithm", algorithm, "iteration", iteration, "cluster", i)
+		}
+	}
+}
+
+func makeCentroids(k int, dataset *Dataset, sampler Sampler) []Vector {
+	centroids := make([]Vector, k)
+	maxLen := dataset.Max().Length()
+	for i := 0; i < k; i++ {
+		centroids[i] = sampler(i, maxLen)
+	}
+	return centroids
+}
+
+func collectClusters(dataset *Dataset, k int, nearest func(Vector) int) []bucketCollector {
+	buckets := make([]bucketCollector, k)
+	for i, record := range dataset.data {
+		buckets[nearest(record)].Collect(record, dataset.Weight(i))
+	}
+	return buckets
+}
+
+func nearestCentroid(centroids []Vector, record Vector) int {
+	cluster := 0
+	distToCluster := centroids[cluster].DistanceTo(record)
+	for k, centroid := range centroids {
+		distToCentroid := record.DistanceTo(centroid)
+		if distToCentroid < distToCluster {
+			cluster = k
+			distToCluster = distToCentroid
+		}
+	}
+	return cluster
+}
+
+func createNewCentroids(centroids *[]Vector, buckets []bucketCollector) []float64 {
+	k := len(*centroids)
+	deltas := make([]float64, k)
+	for i := 0; i < k; i++ {
+		if newCentroid := buckets[i].Average(); newCentroid != nil {
+			deltas[i] = (*centroids)[i].DistanceTo(newCentroid)
+			(*centroids)[i] = newCentroid
+		}
+	}
+	return deltas
+}
+
+type bucketCollector struct {
+	average       Vector
+	normalization float64
+}
+
+func (collector *bucketCollector) Collect(vec Vector, weight float64) {
+	if collector == nil || collector.average == nil {
+		collector.average = vec.MulScalar(weight)
+	} else {
+		collector.average = collector.average.Add(vec.MulScalar(weight))
+	}
+	collector.normalization += weight
+}
+
+func (collector *bucketCollector) Average() Vector {
+	if collector == nil || collector.average == nil || collector.normalization == 0 {
+		return nil
+	}
+
+	return collector.average.MulScalar(1 / collector.normalization)
+}
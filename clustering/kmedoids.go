@@ -0,0 +1,175 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+)
+
+// MedoidClustering is the result of clustering a collection of elements around `k` of those elements, the medoids.
+type MedoidClustering struct {
+	// Medoids contains the index of the medoid of every cluster, i.e., cluster `c` has medoid `Medoids[c]`.
+	Medoids []int
+	// Assignments contains the cluster every element of the collection was assigned to.
+	Assignments []Cluster
+}
+
+// KMedoidsFromDistances will perform k-medoids clustering (PAM) on the elements of the distance matrix,
+// greedily choosing the initial medoids and subsequently swapping medoids with other elements as long as that lowers the total distance to the medoids.
+func KMedoidsFromDistances(matrix DistanceMatrix, k int) (MedoidClustering, error) {
+	if err := checkDistanceMatrix(matrix); err != nil {
+		return MedoidClustering{}, err
+	}
+	n := matrix.Size()
+	if k <= 0 || k > n {
+		return MedoidClustering{}, fmt.Errorf("Expected between 1 and %d medoids but got %d", n, k)
+	}
+
+	return kMedoids(matrix, k, nil, func(int, []int) {})
+}
+
+// KMedoids will perform k-medoids clustering (PAM) on the vectors of this dataset with distances measured by the metric,
+// the medoids are indices into this dataset and minimize the total weighted distance when the dataset is weighted.
+// Distances are computed lazily, so only the ones PAM actually needs are evaluated.
+func (dataset *Dataset) KMedoids(k int, metric Metric) (MedoidClustering, error) {
+	n := dataset.Count()
+	if k <= 0 || k > n {
+		return MedoidClustering{}, fmt.Errorf("Expected between 1 and %d medoids but got %d", n, k)
+	}
+	instrumentation, computed, reported := dataset.instrument(), 0, 0
+	counted := func(a, b Vector) float64 {
+		computed++
+		return metric(a, b)
+	}
+	report := func() {
+		instrumentation.Distances("kmedoids", computed-reported)
+		reported = computed
+	}
+	logger := dataset.log()
+	medoids, err := kMedoids(CachedDistances(dataset, counted), k, dataset.weights, func(swaps int, medoids []int) {
+		if swaps == 0 {
+			logger.Debug("Chose the initial medoids", "algorithm", "kmedoids", "medoids", medoids)
+			return
+		}
+		report()
+		instrumentation.Iteration("kmedoids", swaps)
+		logger.Debug("Swapped a medoid", "algorithm", "kmedoids", "iteration", swaps, "medoids", medoids)
+	})
+	report()
+	return medoids, err
+}
+
+// kMedoids performs PAM on the elements of the matrix, where `weights[i]` is the weight of the `i`th element or every element has weight 1 if `weights` is nil.
+// The observe function is called with the medoids right after they are chosen and after every swap, together with the number of swaps so far.
+func kMedoids(matrix DistanceMatrix, k int, weights []float64, observe func(swaps int, medoids []int)) (MedoidClustering, error) {
+	medoids := buildMedoids(matrix, k, weights)
+	observe(0, medoids)
+	for swaps := 1; swapMedoids(matrix, medoids, weights); swaps++ {
+		observe(swaps, medoids)
+	}
+
+	nearest, _, _ := nearestMedoids(matrix, medoids)
+	return MedoidClustering{Medoids: medoids, Assignments: nearest}, distanceMatrixErr(matrix)
+}
+
+// buildMedoids greedily selects `k` medoids, each time choosing the element that reduces the total distance to the medoids the most.
+func buildMedoids(matrix DistanceMatrix, k int, weights []float64) []int {
+	n := matrix.Size()
+	medoids := make([]int, 0, k)
+	nearestDist := make([]float64, n)
+	for i := range nearestDist {
+		nearestDist[i] = math.Inf(1)
+	}
+	isMedoid := make([]bool, n)
+	for len(medoids) < k {
+		bestCandidate, bestGain := -1, math.Inf(-1)
+		for candidate := 0; candidate < n; candidate++ {
+			if isMedoid[candidate] {
+				continue
+			}
+			gain := 0.0
+			for i := 0; i < n; i++ {
+				if d := matrix.Distance(i, candidate); d < nearestDist[i] {
+					if math.IsInf(nearestDist[i], 1) {
+						// The first medoid is the element with the smallest total distance to all other elements.
+						gain -= elementWeight(weights, i) * d
+					} else {
+						gain += elementWeight(weights, i) * (nearestDist[i] - d)
+					}
+				}
+			}
+			if bestCandidate < 0 || gain > bestGain {
+				bestCandidate, bestGain = candidate, gain
+			}
+		}
+		medoids = append(medoids, bestCandidate)
+		isMedoid[bestCandidate] = true
+		for i := 0; i < n; i++ {
+			nearestDist[i] = math.Min(nearestDist[i], matrix.Distance(i, bestCandidate))
+		}
+	}
+	return medoids
+}
+
+// swapMedoids performs the swap of a medoid with a non-medoid that lowers the total distance to the medoids the most,
+// it returns false if no swap could lower the total distance.
+func swapMedoids(matrix DistanceMatrix, medoids []int, weights []float64) bool {
+	n := matrix.Size()
+	nearest, nearestDist, secondDist := nearestMedoids(matrix, medoids)
+	isMedoid := make([]bool, n)
+	for _, medoid := range medoids {
+		isMedoid[medoid] = true
+	}
+
+	bestMedoid, bestCandidate, bestDelta := -1, -1, 0.0
+	for m := range medoids {
+		for candidate := 0; candidate < n; candidate++ {
+			if isMedoid[candidate] {
+				continue
+			}
+			delta := 0.0
+			for i := 0; i < n; i++ {
+				d := matrix.Distance(i, candidate)
+				if nearest[i] == Cluster(m) {
+					delta += elementWeight(weights, i) * (math.Min(d, secondDist[i]) - nearestDist[i])
+				} else if d < nearestDist[i] {
+					delta += elementWeight(weights, i) * (d - nearestDist[i])
+				}
+			}
+			if delta < bestDelta-1e-12 {
+				bestMedoid, bestCandidate, bestDelta = m, candidate, delta
+			}
+		}
+	}
+	if bestMedoid < 0 {
+		return false
+	}
+	medoids[bestMedoid] = bestCandidate
+	return true
+}
+
+// elementWeight returns the weight of the `i`th element, which is 1 when there are no weights.
+func elementWeight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1
+	}
+	return weights[i]
+}
+
+// nearestMedoids will return for every element the cluster of its nearest medoid, the distance to that medoid and the distance to the second nearest medoid.
+func nearestMedoids(matrix DistanceMatrix, medoids []int) ([]Cluster, []float64, []float64) {
+	n := matrix.Size()
+	nearest := make([]Cluster, n)
+	nearestDist, secondDist := make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		nearestDist[i], secondDist[i] = math.Inf(1), math.Inf(1)
+		for m, medoid := range medoids {
+			d := matrix.Distance(i, medoid)
+			if d < nearestDist[i] {
+				nearest[i], nearestDist[i], secondDist[i] = Cluster(m), d, nearestDist[i]
+			} else if d < secondDist[i] {
+				secondDist[i] = d
+			}
+		}
+	}
+	return nearest, nearestDist, secondDist
+}
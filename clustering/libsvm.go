@@ -0,0 +1,80 @@
+package clustering
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadLIBSVM will parse every line of the sparse LIBSVM (SVMLight) format, i.e., `label index:value index:value ...` with one-based increasing indices,
+// into a SparseVector and return the resulting dataset together with the label of every vector. Text after a `#` is ignored, as are `qid:` pairs.
+// The vectors have the provided dimension, or the largest index in the data if the dimension is 0.
+func LoadLIBSVM(r io.Reader, dimension int) (Dataset, []float64, error) {
+	if dimension < 0 {
+		return Dataset{}, nil, fmt.Errorf("Expected a non-negative dimension but got %d", dimension)
+	}
+	type entry struct {
+		indices []int
+		values  []float64
+	}
+	var entries []entry
+	var labels []float64
+	largest := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if comment := strings.IndexByte(text, '#'); comment >= 0 {
+			text = text[:comment]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		label, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return Dataset{}, nil, fmt.Errorf("Label on line %d is not numeric: %v", line, err)
+		}
+		var current entry
+		for _, field := range fields[1:] {
+			separator := strings.IndexByte(field, ':')
+			if separator < 0 {
+				return Dataset{}, nil, fmt.Errorf("Expected an index:value pair on line %d but got %q", line, field)
+			}
+			if field[:separator] == "qid" {
+				continue
+			}
+			index, err := strconv.Atoi(field[:separator])
+			if err != nil || index < 1 {
+				return Dataset{}, nil, fmt.Errorf("Index %q on line %d is not a positive integer", field[:separator], line)
+			}
+			if n := len(current.indices); n > 0 && index <= current.indices[n-1] {
+				return Dataset{}, nil, fmt.Errorf("Indices on line %d are not increasing", line)
+			}
+			value, err := strconv.ParseFloat(field[separator+1:], 64)
+			if err != nil {
+				return Dataset{}, nil, fmt.Errorf("Value of index %d on line %d is not numeric: %v", index, line, err)
+			}
+			if dimension > 0 && index > dimension {
+				return Dataset{}, nil, fmt.Errorf("Index %d on line %d exceeds the dimension %d", index, line, dimension)
+			}
+			largest = max(largest, index)
+			current.indices, current.values = append(current.indices, index-1), append(current.values, value)
+		}
+		entries, labels = append(entries, current), append(labels, label)
+	}
+	if err := scanner.Err(); err != nil {
+		return Dataset{}, nil, err
+	}
+
+	if dimension == 0 {
+		dimension = largest
+	}
+	data := make([]Vector, len(entries))
+	for i, current := range entries {
+		data[i] = SparseVectord(dimension, current.indices, current.values)
+	}
+	return CreateDataset(data, sparseVectorCreator(dimension)), labels, nil
+}
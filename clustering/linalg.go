@@ -0,0 +1,129 @@
+package clustering
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// matrix is a dense, row-major real matrix used by the algorithms needing some linear algebra.
+type matrix [][]float64
+
+func newMatrix(rows, columns int) matrix {
+	m := make(matrix, rows)
+	for i := range m {
+		m[i] = make([]float64, columns)
+	}
+	return m
+}
+
+// addOuter adds `scale * x * x^T` to this matrix.
+func (m matrix) addOuter(x []float64, scale float64) {
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] += scale * x[i] * x[j]
+		}
+	}
+}
+
+// mulVec multiplies this matrix with the vector `x`.
+func (m matrix) mulVec(x []float64) []float64 {
+	result := make([]float64, len(m))
+	for i, row := range m {
+		for j, value := range row {
+			result[i] += value * x[j]
+		}
+	}
+	return result
+}
+
+// symmetric returns this symmetric matrix as a gonum matrix, of which only the lower triangle is read.
+func (m matrix) symmetric() *mat.SymDense {
+	n := len(m)
+	sym := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sym.SetSym(i, j, m[i][j])
+		}
+	}
+	return sym
+}
+
+// symmetricEigen computes the eigenvalues and eigenvectors of a symmetric matrix, it returns false if the decomposition fails, e.g., for a matrix holding NaN.
+// The `k`th column of the returned matrix is the eigenvector of the `k`th eigenvalue.
+func (m matrix) symmetricEigen() ([]float64, matrix, bool) {
+	n := len(m)
+	if n == 0 {
+		return nil, matrix{}, true
+	}
+	var eigen mat.EigenSym
+	if !eigen.Factorize(m.symmetric(), true) {
+		return nil, nil, false
+	}
+	var vectors mat.Dense
+	eigen.VectorsTo(&vectors)
+	result := newMatrix(n, n)
+	for i := range result {
+		mat.Row(result[i], i, &vectors)
+	}
+	return eigen.Values(nil), result, true
+}
+
+// spectralMap applies `f` to the eigenvalues of a symmetric matrix, i.e., it computes `V f(Λ) V^T`.
+// The result holds NaN if the eigendecomposition fails.
+func (m matrix) spectralMap(f func(float64) float64) matrix {
+	n := len(m)
+	result := newMatrix(n, n)
+	values, vectors, ok := m.symmetricEigen()
+	if !ok {
+		for i := range result {
+			for j := range result[i] {
+				result[i][j] = math.NaN()
+			}
+		}
+		return result
+	}
+	for k, value := range values {
+		mapped := f(value)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				result[i][j] += mapped * vectors[i][k] * vectors[j][k]
+			}
+		}
+	}
+	return result
+}
+
+// cholesky computes the lower triangular matrix `L` such that `L L^T` is this symmetric matrix, it returns false if this matrix is not positive definite.
+func (m matrix) cholesky() (matrix, bool) {
+	n := len(m)
+	if n == 0 {
+		return matrix{}, true
+	}
+	var factorization mat.Cholesky
+	if !factorization.Factorize(m.symmetric()) {
+		return nil, false
+	}
+	var lower mat.TriDense
+	factorization.LTo(&lower)
+	l := newMatrix(n, n)
+	for i := range l {
+		for j := 0; j <= i; j++ {
+			l[i][j] = lower.At(i, j)
+		}
+	}
+	return l, true
+}
+
+// solveLower solves `L y = b` for `y` by forward substitution, where this matrix is the lower triangular `L`.
+func (m matrix) solveLower(b []float64) []float64 {
+	y := make([]float64, len(b))
+	for i := range y {
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= m[i][k] * y[k]
+		}
+		y[i] = s / m[i][i]
+	}
+	return y
+}
@@ -0,0 +1,82 @@
+package clustering
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomCovariance returns a random symmetric positive definite matrix.
+func randomCovariance(rng *rand.Rand, n int) matrix {
+	m := newMatrix(n, n)
+	for i := 0; i < 2*n; i++ {
+		x := make([]float64, n)
+		for j := range x {
+			x[j] = rng.NormFloat64()
+		}
+		m.addOuter(x, 1)
+	}
+	return m
+}
+
+func column(m matrix, j int) []float64 {
+	result := make([]float64, len(m))
+	for i := range m {
+		result[i] = m[i][j]
+	}
+	return result
+}
+
+func expectMatrix(t *testing.T, what string, got, want matrix) {
+	t.Helper()
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(got[i][j]-want[i][j]) > 1e-9 {
+				t.Fatalf("Expected %s to be %v but got %v", what, want, got)
+			}
+		}
+	}
+}
+
+func TestCholesky(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := randomCovariance(rng, 4)
+	l, ok := m.cholesky()
+	if !ok {
+		t.Fatal("Expected a positive definite matrix to have a Cholesky factor")
+	}
+	product := newMatrix(4, 4)
+	for i := range l {
+		if l[i][i] <= 0 {
+			t.Errorf("Expected a positive diagonal but got %v", l)
+		}
+		for j := i + 1; j < 4; j++ {
+			if l[i][j] != 0 {
+				t.Errorf("Expected a lower triangular factor but got %v", l)
+			}
+		}
+		product.addOuter(column(l, i), 1)
+	}
+	expectMatrix(t, "L L^T", product, m)
+	if _, ok := (matrix{{1, 2}, {2, 1}}).cholesky(); ok {
+		t.Error("Expected an indefinite matrix to have no Cholesky factor")
+	}
+	if _, ok := (matrix{{math.NaN(), 0}, {0, 1}}).cholesky(); ok {
+		t.Error("Expected a matrix holding NaN to have no Cholesky factor")
+	}
+}
+
+func TestSpectralMap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := randomCovariance(rng, 5)
+	expectMatrix(t, "the identity map", m.spectralMap(func(value float64) float64 { return value }), m)
+	root := m.spectralMap(math.Sqrt)
+	square := newMatrix(5, 5)
+	for i := range root {
+		square.addOuter(column(root, i), 1)
+	}
+	expectMatrix(t, "the square of the square root", square, m)
+	if nan := (matrix{{math.NaN(), 0}, {0, 1}}).spectralMap(math.Sqrt); !math.IsNaN(nan[0][0]) {
+		t.Errorf("Expected NaN for a matrix holding NaN but got %v", nan)
+	}
+}
@@ -0,0 +1,33 @@
+package clustering
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger will attach the logger to this dataset such that the clustering algorithms run on the dataset log their internals to it at the debug level,
+// e.g., their initial centroids, the largest move of a centroid in every iteration and clusters that lost all their vectors, to find out why a clustering went wrong.
+// Every record has the name of the algorithm as the attribute `algorithm`, like the measurements of an Instrumentation. A nil logger detaches it.
+func (dataset *Dataset) SetLogger(logger *slog.Logger) {
+	dataset.logger = logger
+}
+
+// Logger returns the logger attached to this dataset, or nil if there is none.
+func (dataset *Dataset) Logger() *slog.Logger {
+	return dataset.logger
+}
+
+// log returns the attached logger, or one that discards all records if there is none.
+func (dataset *Dataset) log() *slog.Logger {
+	if dataset.logger == nil {
+		return slog.New(discardHandler{})
+	}
+	return dataset.logger
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool   { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error  { return nil }
+func (handler discardHandler) WithAttrs([]slog.Attr) slog.Handler { return handler }
+func (handler discardHandler) WithGroup(string) slog.Handler      { return handler }
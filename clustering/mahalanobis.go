@@ -0,0 +1,100 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// IndexPair identifies two elements of a dataset by their index.
+type IndexPair struct {
+	I, J int
+}
+
+// MahalanobisMatrix is a symmetric positive semi-definite matrix `M` defining the distance `sqrt((v - w)^T M (v - w))`.
+type MahalanobisMatrix [][]float64
+
+// Metric will return the Metric measuring distances according to this matrix.
+// The resulting Metric panics when comparing vectors whose dimension differs from the size of the matrix.
+func (m MahalanobisMatrix) Metric() Metric {
+	return func(v, other Vector) float64 {
+		if Dimension(v) != len(m) || Dimension(other) != len(m) {
+			panic(fmt.Sprintf("Expected vectors of dimension %d but got %d and %d", len(m), Dimension(v), Dimension(other)))
+		}
+		diff := components(v.Subtract(other))
+		return math.Sqrt(math.Max(0, dotProduct(diff, matrix(m).mulVec(diff))))
+	}
+}
+
+// LearnMahalanobis will fit a MahalanobisMatrix such that the pairs of elements of the dataset deemed similar end up close to each other
+// while the pairs deemed dissimilar end up far apart, so subsequent clustering respects those notions of similarity.
+// The matrix is the KISSME estimate `Σs^-1 - Σd^-1`, with `Σs` and `Σd` the covariances of the differences within similar and dissimilar pairs,
+// projected onto the positive semi-definite matrices. The regularization is added to the diagonal of both covariances to keep them invertible.
+// Without dissimilar pairs the matrix reduces to `Σs^-1`.
+func LearnMahalanobis(dataset *Dataset, similar, dissimilar []IndexPair, regularization float64) (MahalanobisMatrix, error) {
+	if dataset.IsEmpty() {
+		return nil, errors.New("Cannot learn a metric from an empty dataset")
+	}
+	if len(similar) == 0 {
+		return nil, errors.New("Expected at least one pair of similar elements")
+	}
+	if regularization < 0 {
+		return nil, fmt.Errorf("Expected a non-negative regularization but got %v", regularization)
+	}
+
+	similarCovariance, err := pairCovariance(dataset, similar, regularization)
+	if err != nil {
+		return nil, err
+	}
+	learned := similarCovariance.spectralMap(pseudoInverse)
+	if len(dissimilar) > 0 {
+		dissimilarCovariance, err := pairCovariance(dataset, dissimilar, regularization)
+		if err != nil {
+			return nil, err
+		}
+		dissimilarInverse := dissimilarCovariance.spectralMap(pseudoInverse)
+		for i := range learned {
+			for j := range learned[i] {
+				learned[i][j] -= dissimilarInverse[i][j]
+			}
+		}
+	}
+
+	projected := learned.spectralMap(func(value float64) float64 {
+		return math.Max(0, value)
+	})
+	return MahalanobisMatrix(projected), nil
+}
+
+// pairCovariance computes the regularized covariance of the differences between the elements of every pair.
+func pairCovariance(dataset *Dataset, pairs []IndexPair, regularization float64) (matrix, error) {
+	data := dataset.data
+	dimension := Dimension(data[0])
+	covariance := newMatrix(dimension, dimension)
+	for _, pair := range pairs {
+		if pair.I < 0 || pair.I >= len(data) || pair.J < 0 || pair.J >= len(data) {
+			return nil, fmt.Errorf("Pair (%d, %d) refers to elements outside of the dataset of %d elements", pair.I, pair.J, len(data))
+		}
+		covariance.addOuter(components(data[pair.I].Subtract(data[pair.J])), 1/float64(len(pairs)))
+	}
+	for i := range covariance {
+		covariance[i][i] += regularization
+	}
+	return covariance, nil
+}
+
+// pseudoInverse inverts an eigenvalue, eigenvalues that are numerically zero are left at zero as in the Moore-Penrose pseudo-inverse.
+func pseudoInverse(value float64) float64 {
+	if value <= 1e-12 {
+		return 0
+	}
+	return 1 / value
+}
+
+func dotProduct(x, y []float64) float64 {
+	result := 0.0
+	for i := range x {
+		result += x[i] * y[i]
+	}
+	return result
+}
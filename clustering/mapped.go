@@ -0,0 +1,147 @@
+package clustering
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"unsafe"
+)
+
+// DenseFormat is the encoding of the components of a vector in a dense binary file.
+type DenseFormat int
+
+const (
+	// Float64 stores every component as a little-endian IEEE 754 double.
+	Float64 DenseFormat = iota
+	// Float32 stores every component as a little-endian IEEE 754 single, halving the size of the file at the cost of precision.
+	Float32
+)
+
+func (format DenseFormat) width() int {
+	if format == Float32 {
+		return 4
+	}
+	return 8
+}
+
+// MappedDataset is a read-only dataset of VectorNs stored as fixed-width rows in a memory-mapped file,
+// such that datasets far larger than the available memory can be scanned by single-pass and mini-batch algorithms.
+// Memory-mapping is only available on Unix systems, elsewhere the file is read into memory.
+type MappedDataset struct {
+	data      []byte
+	dimension int
+	format    DenseFormat
+	unmap     func() error
+}
+
+// OpenMapped will memory-map the file at the provided path, which must consist of rows of `dimension` components encoded in the provided format,
+// e.g., as written by WriteDense. The dataset must be closed once it is no longer in use.
+func OpenMapped(path string, dimension int, format DenseFormat) (*MappedDataset, error) {
+	if dimension <= 0 {
+		return nil, fmt.Errorf("Expected a positive dimension but got %d", dimension)
+	}
+	if format != Float64 && format != Float32 {
+		return nil, fmt.Errorf("Unknown dense format %d", format)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	rowSize := int64(dimension * format.width())
+	if info.Size()%rowSize != 0 {
+		return nil, fmt.Errorf("The size of %s is not a multiple of the row size of %d bytes", path, rowSize)
+	}
+	data, unmap, err := mapFile(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	return &MappedDataset{data: data, dimension: dimension, format: format, unmap: unmap}, nil
+}
+
+// Count will return the number of data points in this dataset.
+func (dataset *MappedDataset) Count() int {
+	return len(dataset.data) / (dataset.dimension * dataset.format.width())
+}
+
+// Dimension will return the number of components of the vectors in this dataset.
+func (dataset *MappedDataset) Dimension() int {
+	return dataset.dimension
+}
+
+// At will return the `i`th vector of this dataset. Float64 rows are not copied on little-endian systems:
+// those vectors refer to the mapped file directly, so they must not be modified nor used after the dataset is closed.
+func (dataset *MappedDataset) At(i int) VectorN {
+	rowSize := dataset.dimension * dataset.format.width()
+	row := dataset.data[i*rowSize : (i+1)*rowSize]
+	if dataset.format == Float64 && littleEndian() {
+		return VectorN(unsafe.Slice((*float64)(unsafe.Pointer(&row[0])), dataset.dimension))
+	}
+	vec := make(VectorN, dataset.dimension)
+	for j := range vec {
+		if dataset.format == Float32 {
+			vec[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(row[4*j:])))
+		} else {
+			vec[j] = math.Float64frombits(binary.LittleEndian.Uint64(row[8*j:]))
+		}
+	}
+	return vec
+}
+
+// Source will return a DataSource producing the vectors of this dataset in order.
+func (dataset *MappedDataset) Source() DataSource {
+	return &mappedSource{dataset: dataset}
+}
+
+// Close will unmap the file, after which neither the dataset nor the vectors it returned can be used.
+// Closing a closed dataset does nothing.
+func (dataset *MappedDataset) Close() error {
+	if dataset.unmap == nil {
+		return nil
+	}
+	unmap := dataset.unmap
+	dataset.data, dataset.unmap = nil, nil
+	return unmap()
+}
+
+type mappedSource struct {
+	dataset *MappedDataset
+	next    int
+}
+
+func (source *mappedSource) Next() (Vector, bool) {
+	if source.next >= source.dataset.Count() {
+		return nil, false
+	}
+	source.next++
+	return source.dataset.At(source.next - 1), true
+}
+
+// WriteDense will write the vectors of the dataset as fixed-width rows encoded in the provided format, the layout read by OpenMapped.
+func WriteDense(w io.Writer, dataset *Dataset, format DenseFormat) error {
+	for _, vec := range dataset.data {
+		row := make([]byte, Dimension(vec)*format.width())
+		for j := 0; j < Dimension(vec); j++ {
+			if format == Float32 {
+				binary.LittleEndian.PutUint32(row[4*j:], math.Float32bits(float32(Component(vec, j))))
+			} else {
+				binary.LittleEndian.PutUint64(row[8*j:], math.Float64bits(Component(vec, j)))
+			}
+		}
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func littleEndian() bool {
+	probe := uint16(1)
+	return *(*byte)(unsafe.Pointer(&probe)) == 1
+}
@@ -0,0 +1,44 @@
+package clustering
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedDataset(t *testing.T) {
+	dataset := CreateNonEmptyDataset([]Vector{VectorN{1, 2, 3}, VectorN{-4, 5.5, 6}})
+	for _, format := range []DenseFormat{Float64, Float32} {
+		var buffer bytes.Buffer
+		if err := WriteDense(&buffer, &dataset, format); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "dense")
+		if err := os.WriteFile(path, buffer.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mapped, err := OpenMapped(path, 3, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mapped.Count() != 2 {
+			t.Fatalf("Expected 2 vectors but got %d", mapped.Count())
+		}
+		for i := 0; i < mapped.Count(); i++ {
+			if got, want := mapped.At(i), dataset.At(i).(VectorN); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("Expected vector %d to be %v but got %v", i, want, got)
+			}
+		}
+		if err := mapped.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapped.Close(); err != nil {
+			t.Errorf("Expected closing a closed dataset to do nothing but got %v", err)
+		}
+		if mapped.Count() != 0 {
+			t.Errorf("Expected no vectors after closing but got %d", mapped.Count())
+		}
+	}
+}
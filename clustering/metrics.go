@@ -0,0 +1,149 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+)
+
+// distributionTolerance is the maximum allowed deviation of the sum of a probability distribution from 1.
+const distributionTolerance = 1e-6
+
+// Metric calculates the distance between two vectors of the same real abstract vector space.
+type Metric func(v, other Vector) float64
+
+// Euclidean will return the Euclidean distance between both vectors.
+func Euclidean(v, other Vector) float64 {
+	return norm(v.Subtract(other))
+}
+
+// WeightedEuclidean will create a Euclidean Metric in which the squared difference of the `i`th components is multiplied by `weights[i]`,
+// such that features can be emphasized or de-emphasized without rescaling the dataset. A weight of 0 ignores the feature entirely.
+// WeightedEuclidean panics when one of the weights is negative, the resulting Metric panics when comparing vectors whose dimension differs from the number of weights.
+func WeightedEuclidean(weights ...float64) Metric {
+	for i, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) {
+			panic(fmt.Sprintf("Expected non-negative weights but weight %d is %v", i, weight))
+		}
+	}
+	weights = append([]float64(nil), weights...)
+	return func(v, other Vector) float64 {
+		if Dimension(v) != len(weights) || Dimension(other) != len(weights) {
+			panic(fmt.Sprintf("Expected vectors of dimension %d but got %d and %d", len(weights), Dimension(v), Dimension(other)))
+		}
+		distance := 0.0
+		for i, weight := range weights {
+			diff := Component(v, i) - Component(other, i)
+			distance += weight * diff * diff
+		}
+		return math.Sqrt(distance)
+	}
+}
+
+// Cosine will return the cosine distance between both vectors, i.e., `1 - cos(θ)` with `θ` the angle between them.
+// The null-vector has no direction: the distance between two null-vectors is 0 and the distance between a null-vector and any other vector is 1.
+func Cosine(v, other Vector) float64 {
+	vLen, otherLen := norm(v), norm(other)
+	if vLen == 0 && otherLen == 0 {
+		return 0
+	} else if vLen == 0 || otherLen == 0 {
+		return 1
+	}
+	// Rounding errors can push the similarity slightly outside of [-1, 1].
+	similarity := math.Max(-1, math.Min(1, v.TransposedMul(other)/(vLen*otherLen)))
+	return 1 - similarity
+}
+
+// Pearson will return the correlation distance between both vectors, i.e., `1 - r` with `r` the Pearson correlation coefficient of their components.
+// The distance only depends on the shape of the component profiles and ranges from 0 (perfectly correlated) to 2 (perfectly anti-correlated).
+// Constant vectors have no correlation with any other vector: the distance between two constant vectors is 0 and the distance between a constant vector and any other vector is 1.
+func Pearson(v, other Vector) float64 {
+	if Dimension(v) != Dimension(other) {
+		panic(fmt.Sprintf("Expected vectors of equal dimension but got %d and %d", Dimension(v), Dimension(other)))
+	}
+	x, y := centered(components(v)), centered(components(other))
+	xy, xx, yy := 0.0, 0.0, 0.0
+	for i := range x {
+		xy += x[i] * y[i]
+		xx += x[i] * x[i]
+		yy += y[i] * y[i]
+	}
+	if xx == 0 && yy == 0 {
+		return 0
+	} else if xx == 0 || yy == 0 {
+		return 1
+	}
+	correlation := math.Max(-1, math.Min(1, xy/math.Sqrt(xx*yy)))
+	return 1 - correlation
+}
+
+// KLDivergence will return the Kullback-Leibler divergence `D(v || other)` between two probability distributions, expressed in nats.
+// Note that the divergence is not symmetric and is infinite when `other` assigns no probability to an outcome `v` deems possible.
+// KLDivergence panics when either vector is not a probability distribution, see CheckDistribution.
+func KLDivergence(v, other Vector) float64 {
+	p, q := checkDistributions(v, other)
+	divergence := 0.0
+	for i := range p {
+		if p[i] == 0 {
+			continue
+		} else if q[i] == 0 {
+			return math.Inf(1)
+		}
+		divergence += p[i] * math.Log(p[i]/q[i])
+	}
+	return math.Max(0, divergence)
+}
+
+// JensenShannon will return the Jensen-Shannon distance between two probability distributions,
+// i.e., the square root of the Jensen-Shannon divergence, which is symmetric and bounded by `sqrt(ln(2))`.
+// JensenShannon panics when either vector is not a probability distribution, see CheckDistribution.
+func JensenShannon(v, other Vector) float64 {
+	checkDistributions(v, other)
+	mixture := v.Add(other).MulScalar(0.5)
+	divergence := (KLDivergence(v, mixture) + KLDivergence(other, mixture)) / 2
+	return math.Sqrt(math.Max(0, divergence))
+}
+
+// CheckDistribution will return an error when the vector is not a discrete probability distribution,
+// i.e., when one of its components is negative or when its components do not sum to 1.
+func CheckDistribution(v Vector) error {
+	sum := 0.0
+	for i := 0; i < Dimension(v); i++ {
+		component := Component(v, i)
+		if component < 0 || math.IsNaN(component) {
+			return fmt.Errorf("Component %d of a probability distribution must be non-negative, but got %v", i, component)
+		}
+		sum += component
+	}
+	if math.Abs(sum-1) > distributionTolerance {
+		return fmt.Errorf("The components of a probability distribution must sum to 1, but got %v", sum)
+	}
+	return nil
+}
+
+func checkDistributions(v, other Vector) ([]float64, []float64) {
+	if Dimension(v) != Dimension(other) {
+		panic(fmt.Sprintf("Expected distributions of equal dimension but got %d and %d", Dimension(v), Dimension(other)))
+	}
+	for _, vec := range []Vector{v, other} {
+		if err := CheckDistribution(vec); err != nil {
+			panic(err.Error())
+		}
+	}
+	return components(v), components(other)
+}
+
+func centered(values []float64) []float64 {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+	for i := range values {
+		values[i] -= mean
+	}
+	return values
+}
+
+func norm(v Vector) float64 {
+	return math.Sqrt(v.TransposedMul(v))
+}
@@ -0,0 +1,158 @@
+package clustering
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// modelVersion is the version of the envelope written by SaveModel, it is raised whenever the layout of a model changes incompatibly.
+const modelVersion = 1
+
+// modelEnvelope is what SaveModel writes: the models of this package are stored in their portable form,
+// any other clusterer is stored by gob itself and must therefore be registered with gob.Register.
+type modelEnvelope struct {
+	Version   int
+	Model     *jsonModel
+	Clusterer SimpleFlatClusterer
+}
+
+// The models of this package are registered such that they can also be saved as part of another clusterer, e.g., a FittedPipeline.
+func init() {
+	gob.Register(&CentroidClusterer{})
+	gob.Register(&IncrementalCentroids{})
+	gob.Register(FuzzyCMeans{})
+	gob.Register(GaussianMixture{})
+}
+
+// portableModel is implemented by the models that have a portable form shared by their JSON and gob encodings.
+type portableModel interface {
+	model() (jsonModel, error)
+}
+
+// SaveModel will write the fitted clusterer as a versioned gob stream, which LoadModel reads back, e.g., to persist a model between runs of a Go program.
+// A *CentroidClusterer, *IncrementalCentroids, FuzzyCMeans or GaussianMixture is stored in the form also used by its JSON encoding,
+// any other clusterer is encoded by gob and must, like the clusterers and transformers it holds, be registered with gob.Register before saving and loading.
+func SaveModel(w io.Writer, clusterer SimpleFlatClusterer) error {
+	envelope := modelEnvelope{Version: modelVersion}
+	if portable, ok := clusterer.(portableModel); ok {
+		encoded, err := portable.model()
+		if err != nil {
+			return err
+		}
+		envelope.Model = &encoded
+	} else {
+		envelope.Clusterer = clusterer
+	}
+	return gob.NewEncoder(w).Encode(envelope)
+}
+
+// LoadModel will read a clusterer written by SaveModel, returning an error if it was written by an incompatible version.
+// Centroid models are returned as a *CentroidClusterer.
+func LoadModel(r io.Reader) (SimpleFlatClusterer, error) {
+	var envelope modelEnvelope
+	if err := gob.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Version != modelVersion {
+		return nil, fmt.Errorf("Expected a model of version %d but got version %d", modelVersion, envelope.Version)
+	}
+	if envelope.Model == nil {
+		if envelope.Clusterer == nil {
+			return nil, errors.New("The model holds no clusterer")
+		}
+		return envelope.Clusterer, nil
+	}
+	return decodeModel(*envelope.Model)
+}
+
+// decodeModel restores a model of this package from its portable form.
+func decodeModel(encoded jsonModel) (SimpleFlatClusterer, error) {
+	switch encoded.Model {
+	case "centroids":
+		clusterer := &CentroidClusterer{}
+		if err := clusterer.fromModel(encoded); err != nil {
+			return nil, err
+		}
+		return clusterer, nil
+	case "incremental-centroids":
+		model := &IncrementalCentroids{}
+		if err := model.fromModel(encoded); err != nil {
+			return nil, err
+		}
+		return model, nil
+	case "fuzzy-c-means":
+		var model FuzzyCMeans
+		if err := model.fromModel(encoded); err != nil {
+			return nil, err
+		}
+		return model, nil
+	case "gaussian-mixture":
+		var mixture GaussianMixture
+		if err := mixture.fromModel(encoded); err != nil {
+			return nil, err
+		}
+		return mixture, nil
+	}
+	return nil, fmt.Errorf("Unknown model %q", encoded.Model)
+}
+
+// GobEncode will encode the centroids in the same form as MarshalJSON, such that they can be part of any gob stream.
+func (clusterer CentroidClusterer) GobEncode() ([]byte, error) {
+	return gobEncodeModel(clusterer)
+}
+
+// GobDecode will decode centroids encoded by GobEncode.
+func (clusterer *CentroidClusterer) GobDecode(data []byte) error {
+	return gobDecodeModel(data, clusterer.fromModel)
+}
+
+// GobEncode will encode the centroids and their counts in the same form as MarshalJSON.
+func (model IncrementalCentroids) GobEncode() ([]byte, error) {
+	return gobEncodeModel(model)
+}
+
+// GobDecode will decode centroids and their counts encoded by GobEncode.
+func (model *IncrementalCentroids) GobDecode(data []byte) error {
+	return gobDecodeModel(data, model.fromModel)
+}
+
+// GobEncode will encode the fuzzy clustering in the same form as MarshalJSON.
+func (model FuzzyCMeans) GobEncode() ([]byte, error) {
+	return gobEncodeModel(model)
+}
+
+// GobDecode will decode a fuzzy clustering encoded by GobEncode.
+func (model *FuzzyCMeans) GobDecode(data []byte) error {
+	return gobDecodeModel(data, model.fromModel)
+}
+
+// GobEncode will encode the mixture in the same form as MarshalJSON.
+func (mixture GaussianMixture) GobEncode() ([]byte, error) {
+	return gobEncodeModel(mixture)
+}
+
+// GobDecode will decode a mixture encoded by GobEncode, returning an error if one of the covariance matrices is not positive definite.
+func (mixture *GaussianMixture) GobDecode(data []byte) error {
+	return gobDecodeModel(data, mixture.fromModel)
+}
+
+func gobEncodeModel(portable portableModel) ([]byte, error) {
+	encoded, err := portable.model()
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = gob.NewEncoder(&buffer).Encode(encoded)
+	return buffer.Bytes(), err
+}
+
+func gobDecodeModel(data []byte, fromModel func(jsonModel) error) error {
+	var encoded jsonModel
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&encoded); err != nil {
+		return err
+	}
+	return fromModel(encoded)
+}
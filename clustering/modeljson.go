@@ -0,0 +1,277 @@
+package clustering
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// jsonModel is the JSON form of every model, the fields that do not apply to a model are omitted.
+// Vectors are stored as arrays of their components together with their kind, such that they are restored as the same kind of vector.
+type jsonModel struct {
+	Model       string        `json:"model"`
+	Vector      string        `json:"vector"`
+	Dimension   int           `json:"dimension"`
+	Metric      string        `json:"metric,omitempty"`
+	Centroids   [][]float64   `json:"centroids,omitempty"`
+	Counts      []float64     `json:"counts,omitempty"`
+	Fuzziness   float64       `json:"fuzziness,omitempty"`
+	Covariance  string        `json:"covariance,omitempty"`
+	Weights     []float64     `json:"weights,omitempty"`
+	Means       [][]float64   `json:"means,omitempty"`
+	Covariances [][][]float64 `json:"covariances,omitempty"`
+}
+
+var covarianceNames = map[CovarianceType]string{FullCovariance: "full", DiagonalCovariance: "diagonal", SphericalCovariance: "spherical"}
+
+// newJSONModel describes the vectors of a model, which must all be of the same kind and dimension.
+func newJSONModel(model string, vectors []Vector) (jsonModel, [][]float64, error) {
+	encoded := jsonModel{Model: model, Vector: "dense"}
+	if len(vectors) > 0 {
+		encoded.Dimension = Dimension(vectors[0])
+		switch vectors[0].(type) {
+		case Vector2:
+			encoded.Vector = "vector2"
+		case SparseVector:
+			encoded.Vector = "sparse"
+		}
+	}
+	rows := make([][]float64, len(vectors))
+	for i, vec := range vectors {
+		if Dimension(vec) != encoded.Dimension {
+			return jsonModel{}, nil, fmt.Errorf("Expected vectors of dimension %d but vector %d has dimension %d", encoded.Dimension, i, Dimension(vec))
+		}
+		rows[i] = components(vec)
+	}
+	return encoded, rows, nil
+}
+
+// creator checks that the model is of the expected kind and returns the creator of its vectors.
+func (encoded jsonModel) creator(model string) (VectorCreator, error) {
+	if encoded.Model != model {
+		return nil, fmt.Errorf("Expected a %s model but got a %q model", model, encoded.Model)
+	}
+	if encoded.Metric != "" && encoded.Metric != "euclidean" {
+		return nil, fmt.Errorf("Unknown metric %q, only euclidean models are supported", encoded.Metric)
+	}
+	if encoded.Dimension < 0 {
+		return nil, fmt.Errorf("Expected a non-negative dimension but got %d", encoded.Dimension)
+	}
+	switch encoded.Vector {
+	case "vector2":
+		if encoded.Dimension != 2 {
+			return nil, fmt.Errorf("Expected dimension 2 for Vector2s but got %d", encoded.Dimension)
+		}
+		return vector2Creator{}, nil
+	case "sparse":
+		return sparseVectorCreator(encoded.Dimension), nil
+	case "dense":
+		return vectorNCreator(encoded.Dimension), nil
+	}
+	return nil, fmt.Errorf("Unknown vector kind %q", encoded.Vector)
+}
+
+// decodeVectors creates the vectors from their components, which must have the dimension of the model.
+func decodeVectors(rows [][]float64, dimension int, creator VectorCreator) ([]Vector, error) {
+	vectors := make([]Vector, len(rows))
+	for i, row := range rows {
+		if len(row) != dimension {
+			return nil, fmt.Errorf("Expected vectors of dimension %d but vector %d has dimension %d", dimension, i, len(row))
+		}
+		vectors[i] = creator.New(func(d int) float64 { return row[d] })
+	}
+	return vectors, nil
+}
+
+// MarshalJSON will encode the centroids together with their kind and dimension and the metric the clusterer assigns vectors by.
+func (clusterer CentroidClusterer) MarshalJSON() ([]byte, error) {
+	encoded, err := clusterer.model()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+func (clusterer CentroidClusterer) model() (jsonModel, error) {
+	encoded, centroids, err := newJSONModel("centroids", clusterer)
+	encoded.Metric, encoded.Centroids = "euclidean", centroids
+	return encoded, err
+}
+
+// UnmarshalJSON will decode centroids encoded by MarshalJSON.
+func (clusterer *CentroidClusterer) UnmarshalJSON(data []byte) error {
+	var encoded jsonModel
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	return clusterer.fromModel(encoded)
+}
+
+func (clusterer *CentroidClusterer) fromModel(encoded jsonModel) error {
+	creator, err := encoded.creator("centroids")
+	if err != nil {
+		return err
+	}
+	centroids, err := decodeVectors(encoded.Centroids, encoded.Dimension, creator)
+	if err != nil {
+		return err
+	}
+	*clusterer = centroids
+	return nil
+}
+
+// MarshalJSON will encode the centroids and their counts together with the kind and dimension of the centroids.
+func (model IncrementalCentroids) MarshalJSON() ([]byte, error) {
+	encoded, err := model.model()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+func (model IncrementalCentroids) model() (jsonModel, error) {
+	if len(model.Counts) > len(model.Centroids) {
+		return jsonModel{}, fmt.Errorf("Expected at most a count for all %d centroids but got %d", len(model.Centroids), len(model.Counts))
+	}
+	encoded, err := model.Centroids.model()
+	encoded.Model, encoded.Counts = "incremental-centroids", make([]float64, len(model.Centroids))
+	copy(encoded.Counts, model.Counts)
+	return encoded, err
+}
+
+// UnmarshalJSON will decode centroids and their counts encoded by MarshalJSON.
+func (model *IncrementalCentroids) UnmarshalJSON(data []byte) error {
+	var encoded jsonModel
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	return model.fromModel(encoded)
+}
+
+func (model *IncrementalCentroids) fromModel(encoded jsonModel) error {
+	if encoded.Model != "incremental-centroids" {
+		return fmt.Errorf("Expected an incremental-centroids model but got a %q model", encoded.Model)
+	}
+	if len(encoded.Counts) != len(encoded.Centroids) {
+		return fmt.Errorf("Expected a count for all %d centroids but got %d", len(encoded.Centroids), len(encoded.Counts))
+	}
+	for i, count := range encoded.Counts {
+		if !(count >= 0) || math.IsInf(count, 1) {
+			return fmt.Errorf("Expected a finite non-negative count but centroid %d has count %v", i, count)
+		}
+	}
+	encoded.Model = "centroids"
+	var centroids CentroidClusterer
+	if err := centroids.fromModel(encoded); err != nil {
+		return err
+	}
+	model.Centroids, model.Counts = centroids, encoded.Counts
+	return nil
+}
+
+// MarshalJSON will encode the centroids and the fuzziness together with the kind and dimension of the centroids and the metric the memberships are derived from.
+func (model FuzzyCMeans) MarshalJSON() ([]byte, error) {
+	encoded, err := model.model()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+func (model FuzzyCMeans) model() (jsonModel, error) {
+	encoded, centroids, err := newJSONModel("fuzzy-c-means", model.Centroids)
+	encoded.Metric, encoded.Centroids, encoded.Fuzziness = "euclidean", centroids, model.Fuzziness
+	return encoded, err
+}
+
+// UnmarshalJSON will decode a fuzzy clustering encoded by MarshalJSON.
+func (model *FuzzyCMeans) UnmarshalJSON(data []byte) error {
+	var encoded jsonModel
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	return model.fromModel(encoded)
+}
+
+func (model *FuzzyCMeans) fromModel(encoded jsonModel) error {
+	creator, err := encoded.creator("fuzzy-c-means")
+	if err != nil {
+		return err
+	}
+	if !(encoded.Fuzziness > 1) {
+		return fmt.Errorf("Expected a fuzziness larger than 1 but got %v", encoded.Fuzziness)
+	}
+	centroids, err := decodeVectors(encoded.Centroids, encoded.Dimension, creator)
+	if err != nil {
+		return err
+	}
+	*model = FuzzyCMeans{Centroids: centroids, Fuzziness: encoded.Fuzziness}
+	return nil
+}
+
+// MarshalJSON will encode the weights, means and covariance matrices of the components together with the kind and dimension of the means.
+func (mixture GaussianMixture) MarshalJSON() ([]byte, error) {
+	encoded, err := mixture.model()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+func (mixture GaussianMixture) model() (jsonModel, error) {
+	encoded, means, err := newJSONModel("gaussian-mixture", mixture.Means)
+	encoded.Covariance, encoded.Weights, encoded.Means, encoded.Covariances = covarianceNames[mixture.Covariance], mixture.Weights, means, mixture.Covariances
+	return encoded, err
+}
+
+// UnmarshalJSON will decode a mixture encoded by MarshalJSON, returning an error if one of the covariance matrices is not positive definite.
+func (mixture *GaussianMixture) UnmarshalJSON(data []byte) error {
+	var encoded jsonModel
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	return mixture.fromModel(encoded)
+}
+
+func (mixture *GaussianMixture) fromModel(encoded jsonModel) error {
+	creator, err := encoded.creator("gaussian-mixture")
+	if err != nil {
+		return err
+	}
+	decoded := GaussianMixture{Weights: encoded.Weights, Covariances: encoded.Covariances, Covariance: -1}
+	for covariance, name := range covarianceNames {
+		if name == encoded.Covariance {
+			decoded.Covariance = covariance
+		}
+	}
+	if decoded.Covariance < 0 {
+		return fmt.Errorf("Unknown covariance type %q", encoded.Covariance)
+	}
+	if decoded.Means, err = decodeVectors(encoded.Means, encoded.Dimension, creator); err != nil {
+		return err
+	}
+	if len(decoded.Weights) != len(decoded.Means) || len(decoded.Covariances) != len(decoded.Means) {
+		return errors.New("Expected a weight, mean and covariance matrix for every component")
+	}
+	if len(decoded.Means) > 0 && encoded.Dimension == 0 {
+		return errors.New("Expected components of a positive dimension")
+	}
+	total := 0.0
+	for c, weight := range decoded.Weights {
+		if !(weight >= 0) {
+			return fmt.Errorf("Expected a non-negative weight for component %d but got %v", c, weight)
+		}
+		total += weight
+	}
+	if len(decoded.Weights) > 0 && math.Abs(total-1) > 1e-6 {
+		return fmt.Errorf("Expected weights that sum to 1 but they sum to %v", total)
+	}
+	factors, err := decoded.factorCovariances()
+	if err != nil {
+		return err
+	}
+	decoded.factors = cachedFactors(factors)
+	*mixture = decoded
+	return nil
+}
@@ -0,0 +1,249 @@
+package clustering
+
+import (
+	"fmt"
+
+	"github.com/frederikdesmedt/clustering/internal/wire"
+)
+
+// protoVectorKinds holds the kind of vector of every value of the VectorKind enumeration.
+var protoVectorKinds = []string{"dense", "vector2", "sparse"}
+
+// MarshalProto will encode the centroids as a CentroidModel message of clustering.proto.
+func (clusterer CentroidClusterer) MarshalProto() ([]byte, error) {
+	encoded, err := clusterer.model()
+	if err != nil {
+		return nil, err
+	}
+	var writer wire.Writer
+	writer.Message(1, protoVectors(encoded, encoded.Centroids))
+	return writer.Buffer, nil
+}
+
+// UnmarshalProto will decode a CentroidModel message encoded by MarshalProto.
+func (clusterer *CentroidClusterer) UnmarshalProto(data []byte) error {
+	encoded := jsonModel{Model: "centroids", Vector: "dense"}
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		if field == 1 {
+			encoded.Centroids = readProtoVectors(&reader, wireType, &encoded)
+		} else {
+			reader.Skip(wireType)
+		}
+	}
+	if reader.Err() != nil {
+		return reader.Err()
+	}
+	return clusterer.fromModel(encoded)
+}
+
+// MarshalProto will encode the centroids and their counts as an IncrementalCentroidModel message of clustering.proto.
+func (model IncrementalCentroids) MarshalProto() ([]byte, error) {
+	encoded, err := model.model()
+	if err != nil {
+		return nil, err
+	}
+	var writer wire.Writer
+	writer.Message(1, protoVectors(encoded, encoded.Centroids))
+	writer.Doubles(2, encoded.Counts)
+	return writer.Buffer, nil
+}
+
+// UnmarshalProto will decode an IncrementalCentroidModel message encoded by MarshalProto.
+func (model *IncrementalCentroids) UnmarshalProto(data []byte) error {
+	encoded := jsonModel{Model: "incremental-centroids", Vector: "dense"}
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		switch field {
+		case 1:
+			encoded.Centroids = readProtoVectors(&reader, wireType, &encoded)
+		case 2:
+			encoded.Counts = reader.Doubles(wireType, encoded.Counts)
+		default:
+			reader.Skip(wireType)
+		}
+	}
+	if reader.Err() != nil {
+		return reader.Err()
+	}
+	return model.fromModel(encoded)
+}
+
+// MarshalProto will encode the mixture as a GaussianMixtureModel message of clustering.proto.
+func (mixture GaussianMixture) MarshalProto() ([]byte, error) {
+	encoded, err := mixture.model()
+	if err != nil {
+		return nil, err
+	}
+	var writer wire.Writer
+	writer.Varint(1, int64(mixture.Covariance))
+	writer.Doubles(2, encoded.Weights)
+	writer.Message(3, protoVectors(encoded, encoded.Means))
+	var covariances []float64
+	for c, covariance := range encoded.Covariances {
+		if len(covariance) != encoded.Dimension {
+			return nil, fmt.Errorf("Expected a %dx%d covariance matrix for component %d", encoded.Dimension, encoded.Dimension, c)
+		}
+		for _, row := range covariance {
+			if len(row) != encoded.Dimension {
+				return nil, fmt.Errorf("Expected a %dx%d covariance matrix for component %d", encoded.Dimension, encoded.Dimension, c)
+			}
+			covariances = append(covariances, row...)
+		}
+	}
+	writer.Doubles(4, covariances)
+	return writer.Buffer, nil
+}
+
+// UnmarshalProto will decode a GaussianMixtureModel message encoded by MarshalProto,
+// returning an error if one of the covariance matrices is not positive definite.
+func (mixture *GaussianMixture) UnmarshalProto(data []byte) error {
+	encoded := jsonModel{Model: "gaussian-mixture", Vector: "dense"}
+	covariance, covariances := int64(FullCovariance), []float64(nil)
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		switch field {
+		case 1:
+			covariance = reader.Varint(wireType)
+		case 2:
+			encoded.Weights = reader.Doubles(wireType, encoded.Weights)
+		case 3:
+			encoded.Means = readProtoVectors(&reader, wireType, &encoded)
+		case 4:
+			covariances = reader.Doubles(wireType, covariances)
+		default:
+			reader.Skip(wireType)
+		}
+	}
+	if reader.Err() != nil {
+		return reader.Err()
+	}
+	if name, ok := covarianceNames[CovarianceType(covariance)]; ok {
+		encoded.Covariance = name
+	} else {
+		return fmt.Errorf("Unknown covariance type %d", covariance)
+	}
+	size := encoded.Dimension * encoded.Dimension
+	if len(covariances) != len(encoded.Means)*size {
+		return fmt.Errorf("Expected %d covariances but got %d", len(encoded.Means)*size, len(covariances))
+	}
+	encoded.Covariances = make([][][]float64, len(encoded.Means))
+	for c := range encoded.Covariances {
+		encoded.Covariances[c] = make([][]float64, encoded.Dimension)
+		for i := range encoded.Covariances[c] {
+			from := c*size + i*encoded.Dimension
+			encoded.Covariances[c][i] = covariances[from : from+encoded.Dimension]
+		}
+	}
+	return mixture.fromModel(encoded)
+}
+
+// MarshalProto will encode the merges of the dendrogram as a DendrogramModel message of clustering.proto.
+func (dendrogram Dendrogram) MarshalProto() ([]byte, error) {
+	var writer wire.Writer
+	for _, merge := range dendrogram.merges {
+		var message wire.Writer
+		message.Varint(1, int64(merge.A))
+		message.Varint(2, int64(merge.B))
+		message.Double(3, merge.Height)
+		message.Varint(4, int64(merge.Size))
+		writer.Message(1, message)
+	}
+	return writer.Buffer, nil
+}
+
+// UnmarshalProto will decode a DendrogramModel message encoded by MarshalProto, returning an error if the merges do not form a dendrogram.
+func (dendrogram *Dendrogram) UnmarshalProto(data []byte) error {
+	var merges []Merge
+	reader := wire.NewReader(data)
+	for field, wireType, ok := reader.Next(); ok; field, wireType, ok = reader.Next() {
+		if field != 1 {
+			reader.Skip(wireType)
+			continue
+		}
+		var merge Merge
+		message := reader.Message(wireType)
+		for field, wireType, ok := message.Next(); ok; field, wireType, ok = message.Next() {
+			switch field {
+			case 1:
+				merge.A = Cluster(message.Varint(wireType))
+			case 2:
+				merge.B = Cluster(message.Varint(wireType))
+			case 3:
+				merge.Height = message.Double(wireType)
+			case 4:
+				merge.Size = int(message.Varint(wireType))
+			default:
+				message.Skip(wireType)
+			}
+		}
+		reader.Adopt(message)
+		merges = append(merges, merge)
+	}
+	if reader.Err() != nil {
+		return reader.Err()
+	}
+	decoded, err := NewDendrogram(merges)
+	if err != nil {
+		return err
+	}
+	*dendrogram = decoded
+	return nil
+}
+
+// protoVectors encodes the rows of the model as a Vectors message.
+func protoVectors(encoded jsonModel, rows [][]float64) wire.Writer {
+	var writer wire.Writer
+	for kind, name := range protoVectorKinds {
+		if name == encoded.Vector {
+			writer.Varint(1, int64(kind))
+		}
+	}
+	writer.Varint(2, int64(encoded.Dimension))
+	writer.Varint(3, int64(len(rows)))
+	components := make([]float64, 0, len(rows)*encoded.Dimension)
+	for _, row := range rows {
+		components = append(components, row...)
+	}
+	writer.Doubles(4, components)
+	return writer
+}
+
+// readProtoVectors reads a Vectors message, recording the kind and dimension of the vectors in the model, and returns them row by row.
+func readProtoVectors(reader *wire.Reader, wireType int, encoded *jsonModel) [][]float64 {
+	message := reader.Message(wireType)
+	var kind, dimension, count int64
+	var components []float64
+	for field, wireType, ok := message.Next(); ok; field, wireType, ok = message.Next() {
+		switch field {
+		case 1:
+			kind = message.Varint(wireType)
+		case 2:
+			dimension = message.Varint(wireType)
+		case 3:
+			count = message.Varint(wireType)
+		case 4:
+			components = message.Doubles(wireType, components)
+		default:
+			message.Skip(wireType)
+		}
+	}
+	if reader.Adopt(message); reader.Err() != nil {
+		return nil
+	}
+	if kind < 0 || kind >= int64(len(protoVectorKinds)) {
+		reader.Fail(fmt.Errorf("Unknown vector kind %d", kind))
+		return nil
+	}
+	n := int64(len(components))
+	if !(dimension == 0 && count == 0 && n == 0 || dimension > 0 && count >= 0 && n%dimension == 0 && n/dimension == count) {
+		reader.Fail(fmt.Errorf("Expected %d vectors of dimension %d but got %d components", count, dimension, len(components)))
+		return nil
+	}
+	encoded.Vector, encoded.Dimension = protoVectorKinds[kind], int(dimension)
+	rows := make([][]float64, count)
+	for i := range rows {
+		rows[i] = components[i*int(dimension) : (i+1)*int(dimension)]
+	}
+	return rows
+}
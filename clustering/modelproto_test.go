@@ -0,0 +1,197 @@
+package clustering
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/frederikdesmedt/clustering/internal/wire"
+)
+
+// protoModel is a model with a protobuf encoding.
+type protoModel interface {
+	MarshalProto() ([]byte, error)
+	UnmarshalProto(data []byte) error
+}
+
+// protoTestCase holds a model and creates the empty model to decode its encoding into.
+type protoTestCase struct {
+	name  string
+	model protoModel
+	empty func() protoModel
+}
+
+func protoTestCases(t *testing.T) []protoTestCase {
+	dendrogram, err := NewDendrogram([]Merge{{A: 0, B: 1, Height: 0.5, Size: 2}, {A: 2, B: 3, Height: 1, Size: 2}, {A: 4, B: 5, Height: 4.25, Size: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []protoTestCase{
+		{"CentroidClusterer", &CentroidClusterer{VectorN{0, 1.5, -2}, VectorN{10, 11, 12}}, func() protoModel { return &CentroidClusterer{} }},
+		{"Vector2Centroids", &CentroidClusterer{Vector2{0, 1}, Vector2{-3, 4}}, func() protoModel { return &CentroidClusterer{} }},
+		{"SparseCentroids", &CentroidClusterer{SparseVectord(5, []int{1, 4}, []float64{2, -1}), SparseVectord(5, []int{0}, []float64{3})}, func() protoModel { return &CentroidClusterer{} }},
+		{"NoCentroids", &CentroidClusterer{}, func() protoModel { return &CentroidClusterer{} }},
+		{"IncrementalCentroids", &IncrementalCentroids{Centroids: CentroidClusterer{VectorN{0, 1}, VectorN{5, 5}, VectorN{9, 0}}, Counts: []float64{3, 0, 2.5}}, func() protoModel { return &IncrementalCentroids{} }},
+		{"FullGaussianMixture", &GaussianMixture{
+			Weights:     []float64{0.25, 0.75},
+			Means:       []Vector{VectorN{0, 0}, VectorN{10, -10}},
+			Covariances: [][][]float64{{{1, 0.5}, {0.5, 2}}, {{3, 0}, {0, 4}}},
+		}, func() protoModel { return &GaussianMixture{} }},
+		{"DiagonalGaussianMixture", &GaussianMixture{
+			Weights:     []float64{1},
+			Means:       []Vector{VectorN{1, 2, 3}},
+			Covariances: [][][]float64{{{1, 0, 0}, {0, 2, 0}, {0, 0, 3}}},
+			Covariance:  DiagonalCovariance,
+		}, func() protoModel { return &GaussianMixture{} }},
+		{"Dendrogram", &dendrogram, func() protoModel { return &Dendrogram{} }},
+	}
+}
+
+// describe returns the model in a readable form, leaving out the factors a mixture caches.
+func describe(model protoModel) string {
+	if mixture, ok := model.(*GaussianMixture); ok {
+		return fmt.Sprint(mixture.Weights, mixture.Means, mixture.Covariances, mixture.Covariance)
+	}
+	return fmt.Sprint(model)
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	for _, test := range protoTestCases(t) {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.model.MarshalProto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := test.empty()
+			if err := decoded.UnmarshalProto(data); err != nil {
+				t.Fatal(err)
+			}
+			if describe(decoded) != describe(test.model) {
+				t.Errorf("Expected %s after a round trip but got %s", describe(test.model), describe(decoded))
+			}
+			encoded, err := decoded.MarshalProto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, data) {
+				t.Errorf("Expected the decoded model to encode as %v but got %v", data, encoded)
+			}
+		})
+	}
+}
+
+func TestUnmarshalProtoSurvivesCorruptMessages(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, test := range protoTestCases(t) {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.model.MarshalProto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			unmarshal := func(corrupt []byte) {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("Decoding %v panicked: %v", corrupt, r)
+					}
+				}()
+				test.empty().UnmarshalProto(corrupt)
+			}
+			for length := range data {
+				unmarshal(data[:length])
+			}
+			for trial := 0; trial < 1000; trial++ {
+				corrupt := append([]byte(nil), data...)
+				for i := 0; i < 1+rng.Intn(3); i++ {
+					corrupt[rng.Intn(len(corrupt))] = byte(rng.Intn(256))
+				}
+				unmarshal(corrupt)
+			}
+		})
+	}
+}
+
+// vectors encodes a Vectors message of clustering.proto.
+func vectors(kind, dimension, count int64, components ...float64) wire.Writer {
+	var writer wire.Writer
+	writer.Varint(1, kind)
+	writer.Varint(2, dimension)
+	writer.Varint(3, count)
+	writer.Doubles(4, components)
+	return writer
+}
+
+func TestUnmarshalProtoRejectsInvalidModels(t *testing.T) {
+	message := func(write func(writer *wire.Writer)) []byte {
+		var writer wire.Writer
+		write(&writer)
+		return writer.Buffer
+	}
+	centroids := func(vectors wire.Writer) []byte {
+		return message(func(writer *wire.Writer) { writer.Message(1, vectors) })
+	}
+	incremental := func(counts ...float64) []byte {
+		return message(func(writer *wire.Writer) {
+			writer.Message(1, vectors(0, 1, 2, 0, 1))
+			writer.Doubles(2, counts)
+		})
+	}
+	mixture := func(covariance int64, weights []float64, means wire.Writer, covariances ...float64) []byte {
+		return message(func(writer *wire.Writer) {
+			writer.Varint(1, covariance)
+			writer.Doubles(2, weights)
+			writer.Message(3, means)
+			writer.Doubles(4, covariances)
+		})
+	}
+	dendrogram := func(merges ...Merge) []byte {
+		return message(func(writer *wire.Writer) {
+			for _, merge := range merges {
+				var encoded wire.Writer
+				encoded.Varint(1, int64(merge.A))
+				encoded.Varint(2, int64(merge.B))
+				encoded.Double(3, merge.Height)
+				encoded.Varint(4, int64(merge.Size))
+				writer.Message(1, encoded)
+			}
+		})
+	}
+	tests := []struct {
+		name  string
+		data  []byte
+		model protoModel
+	}{
+		{"truncated field", []byte{0x0a, 0x10, 0x08}, &CentroidClusterer{}},
+		{"huge length", []byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, &CentroidClusterer{}},
+		{"wrong wire type", []byte{0x08, 0x01}, &CentroidClusterer{}},
+		{"field 0", []byte{0x00, 0x01}, &CentroidClusterer{}},
+		{"unknown vector kind", centroids(vectors(7, 1, 1, 1)), &CentroidClusterer{}},
+		{"missing components", centroids(vectors(0, 2, 2, 1, 2, 3)), &CentroidClusterer{}},
+		{"negative dimension", centroids(vectors(0, -1, 1, 1)), &CentroidClusterer{}},
+		{"negative count", centroids(vectors(0, 1, -1)), &CentroidClusterer{}},
+		{"huge count", centroids(vectors(0, 1<<62, 1<<40)), &CentroidClusterer{}},
+		{"vectors without dimension", centroids(vectors(0, 0, 3)), &CentroidClusterer{}},
+		{"wrong Vector2 dimension", centroids(vectors(1, 3, 1, 1, 2, 3)), &CentroidClusterer{}},
+		{"negative count", incremental(1, -1), &IncrementalCentroids{}},
+		{"non-finite count", incremental(math.Inf(1), 1), &IncrementalCentroids{}},
+		{"more counts than centroids", incremental(1, 1, 1), &IncrementalCentroids{}},
+		{"unknown covariance", mixture(9, []float64{1}, vectors(0, 1, 1, 0), 1), &GaussianMixture{}},
+		{"missing covariances", mixture(0, []float64{1}, vectors(0, 2, 1, 0, 0), 1, 0, 0), &GaussianMixture{}},
+		{"not positive definite", mixture(0, []float64{1}, vectors(0, 2, 1, 0, 0), 1, 2, 2, 1), &GaussianMixture{}},
+		{"missing weight", mixture(0, []float64{1}, vectors(0, 1, 2, 0, 1), 1, 1), &GaussianMixture{}},
+		{"negative weight", mixture(0, []float64{2, -1}, vectors(0, 1, 2, 0, 1), 1, 1), &GaussianMixture{}},
+		{"weights not summing to 1", mixture(0, []float64{0.5, 0.25}, vectors(0, 1, 2, 0, 1), 1, 1), &GaussianMixture{}},
+		{"merge with itself", dendrogram(Merge{A: 0, B: 0, Size: 2}), &Dendrogram{}},
+		{"merge of a future node", dendrogram(Merge{A: 0, B: 3, Size: 2}, Merge{A: 1, B: 2, Size: 3}), &Dendrogram{}},
+		{"repeated merge", dendrogram(Merge{A: 0, B: 1, Size: 2}, Merge{A: 0, B: 2, Size: 3}), &Dendrogram{}},
+		{"negative node", dendrogram(Merge{A: -1, B: 1, Size: 2}), &Dendrogram{}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%T/%s", test.model, test.name), func(t *testing.T) {
+			if err := test.model.UnmarshalProto(test.data); err == nil {
+				t.Errorf("Expected an error but decoded %v", test.model)
+			}
+		})
+	}
+}
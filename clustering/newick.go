@@ -0,0 +1,52 @@
+package clustering
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteNewick will write the dendrogram in the Newick format, e.g., `((0:1,1:1):0.5,2:1.5);`, such that it can be inspected in standard tree viewers.
+// The `i`th leaf is named `labels[i]`, or its index if the labels are nil, and the length of every branch is the difference between the heights of its nodes.
+// Labels holding characters with a meaning in the Newick format are quoted.
+func (dendrogram Dendrogram) WriteNewick(w io.Writer, labels []string) error {
+	if labels != nil && len(labels) != dendrogram.Size() {
+		return fmt.Errorf("Expected %d labels but got %d", dendrogram.Size(), len(labels))
+	}
+	var builder strings.Builder
+	var write func(node Cluster)
+	write = func(node Cluster) {
+		if dendrogram.IsLeaf(node) {
+			if labels == nil {
+				builder.WriteString(strconv.Itoa(int(node)))
+			} else {
+				builder.WriteString(newickLabel(labels[node]))
+			}
+			return
+		}
+		a, b := dendrogram.Children(node)
+		builder.WriteByte('(')
+		for i, child := range []Cluster{a, b} {
+			if i > 0 {
+				builder.WriteByte(',')
+			}
+			write(child)
+			builder.WriteByte(':')
+			builder.WriteString(strconv.FormatFloat(dendrogram.Height(node)-dendrogram.Height(child), 'g', -1, 64))
+		}
+		builder.WriteByte(')')
+	}
+	write(dendrogram.Root())
+	builder.WriteString(";\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// newickLabel quotes the label if it is empty or holds whitespace or one of the characters `()[]':;,`, doubling the quotes within it.
+func newickLabel(label string) string {
+	if label != "" && !strings.ContainsAny(label, "()[]':;, \t\r\n") {
+		return label
+	}
+	return "'" + strings.ReplaceAll(label, "'", "''") + "'"
+}
@@ -0,0 +1,153 @@
+package clustering
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/frederikdesmedt/clustering/internal/wire"
+)
+
+// The versions of the ONNX intermediate representation and of the default operator set used by WriteONNX.
+const (
+	onnxIRVersion = 7
+	onnxOpset     = 13
+)
+
+// The element types of ONNX tensors used by WriteONNX.
+const (
+	onnxFloatType = 1
+	onnxInt64Type = 7
+)
+
+// The types of ONNX node attributes used by WriteONNX.
+const (
+	onnxAttributeFloat = 1
+	onnxAttributeInt   = 2
+	onnxAttributeInts  = 7
+)
+
+// WriteONNX will write the centroids as an ONNX model assigning every row of its `input`, a float tensor of shape `[N, d]`, to the nearest centroid by Euclidean distance,
+// e.g., to serve assignments from an ONNX runtime. The model has two outputs: `labels`, the int64 index of the nearest centroid of every row and thus its cluster,
+// and `distances`, the `[N, k]` float tensor of squared Euclidean distances between the rows and the centroids.
+// The graph computes `|x|² - 2x·c + |c|²` with a ReduceSumSquare, a Gemm and an Add, followed by an ArgMin, all from operator set 13.
+// Computations happen in single precision, such that rows almost equally near to two centroids may be assigned differently than by FindCluster.
+func (clusterer CentroidClusterer) WriteONNX(w io.Writer) error {
+	if len(clusterer) == 0 {
+		return errors.New("There are no centroids in the CentroidClusterer")
+	}
+	encoded, centroids, err := newJSONModel("centroids", clusterer)
+	if err != nil {
+		return err
+	}
+	k, d := int64(len(centroids)), int64(encoded.Dimension)
+	flattened, norms := make([]float64, 0, k*d), make([]float64, k)
+	for c, centroid := range centroids {
+		flattened = append(flattened, centroid...)
+		norms[c] = dotProduct(centroid, centroid)
+	}
+
+	var graph wire.Writer
+	graph.Message(1, onnxNode("ReduceSumSquare", []string{"input"}, "norms", onnxIntsAttribute("axes", 1), onnxIntAttribute("keepdims", 1)))
+	graph.Message(1, onnxNode("Gemm", []string{"input", "centroids", "centroid_norms"}, "products", onnxFloatAttribute("alpha", -2), onnxIntAttribute("transB", 1)))
+	graph.Message(1, onnxNode("Add", []string{"products", "norms"}, "distances"))
+	graph.Message(1, onnxNode("ArgMin", []string{"distances"}, "labels", onnxIntAttribute("axis", 1), onnxIntAttribute("keepdims", 0)))
+	graph.String(2, "centroid_clusterer")
+	graph.Message(5, onnxTensor("centroids", flattened, k, d))
+	graph.Message(5, onnxTensor("centroid_norms", norms, k))
+	graph.Message(11, onnxValueInfo("input", onnxFloatType, "N", d))
+	graph.Message(12, onnxValueInfo("labels", onnxInt64Type, "N"))
+	graph.Message(12, onnxValueInfo("distances", onnxFloatType, "N", k))
+
+	var opset, model wire.Writer
+	opset.Varint(2, onnxOpset)
+	model.Varint(1, onnxIRVersion)
+	model.String(2, "go-clustering")
+	model.Message(7, graph)
+	model.Message(8, opset)
+	_, err = w.Write(model.Buffer)
+	return err
+}
+
+// onnxNode encodes a NodeProto applying the operator to the inputs.
+func onnxNode(operator string, inputs []string, output string, attributes ...wire.Writer) wire.Writer {
+	var node wire.Writer
+	for _, input := range inputs {
+		node.String(1, input)
+	}
+	node.String(2, output)
+	node.String(3, output)
+	node.String(4, operator)
+	for _, attribute := range attributes {
+		node.Message(5, attribute)
+	}
+	return node
+}
+
+// onnxIntAttribute encodes an AttributeProto holding an integer.
+func onnxIntAttribute(name string, value int64) wire.Writer {
+	var attribute wire.Writer
+	attribute.String(1, name)
+	attribute.Varint(20, onnxAttributeInt)
+	if value == 0 {
+		// A zero would be omitted, but the attribute relies on being explicitly set.
+		attribute.Tag(3, wire.Varint)
+		attribute.Buffer = append(attribute.Buffer, 0)
+	} else {
+		attribute.Varint(3, value)
+	}
+	return attribute
+}
+
+// onnxIntsAttribute encodes an AttributeProto holding a list of integers.
+func onnxIntsAttribute(name string, values ...int64) wire.Writer {
+	var attribute wire.Writer
+	attribute.String(1, name)
+	attribute.Varint(20, onnxAttributeInts)
+	attribute.Ints(8, values...)
+	return attribute
+}
+
+// onnxFloatAttribute encodes an AttributeProto holding a float.
+func onnxFloatAttribute(name string, value float32) wire.Writer {
+	var attribute wire.Writer
+	attribute.String(1, name)
+	attribute.Varint(20, onnxAttributeFloat)
+	attribute.Float(2, value)
+	return attribute
+}
+
+// onnxTensor encodes a TensorProto of single precision values with the dimensions, stored as little-endian raw data.
+func onnxTensor(name string, values []float64, dims ...int64) wire.Writer {
+	var tensor wire.Writer
+	tensor.Ints(1, dims...)
+	tensor.Varint(2, onnxFloatType)
+	tensor.String(8, name)
+	raw := make([]byte, 0, 4*len(values))
+	for _, value := range values {
+		raw = binary.LittleEndian.AppendUint32(raw, math.Float32bits(float32(value)))
+	}
+	tensor.Bytes(9, raw)
+	return tensor
+}
+
+// onnxValueInfo encodes a ValueInfoProto of a tensor of the element type whose first dimension is named and whose other dimensions are fixed.
+func onnxValueInfo(name string, elementType int64, batch string, dims ...int64) wire.Writer {
+	var shape wire.Writer
+	var dimension wire.Writer
+	dimension.String(2, batch)
+	shape.Message(1, dimension)
+	for _, size := range dims {
+		var dimension wire.Writer
+		dimension.Varint(1, size)
+		shape.Message(1, dimension)
+	}
+	var tensor, typ, info wire.Writer
+	tensor.Varint(1, elementType)
+	tensor.Message(2, shape)
+	typ.Message(1, tensor)
+	info.String(1, name)
+	info.Message(2, typ)
+	return info
+}
@@ -0,0 +1,79 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RemoveZScoreOutliers will split this dataset into the vectors of which every component lies within `threshold` standard deviations of its mean
+// and the remaining outliers, e.g., a threshold of 3 removes the vectors with extreme values before clustering. Both datasets keep the original order.
+func (dataset *Dataset) RemoveZScoreOutliers(threshold float64) (Dataset, Dataset, error) {
+	if threshold <= 0 || math.IsNaN(threshold) {
+		return Dataset{}, Dataset{}, fmt.Errorf("Expected a positive threshold but got %v", threshold)
+	}
+	standardizer, err := FitStandardizer(dataset)
+	if err != nil {
+		return Dataset{}, Dataset{}, err
+	}
+	outliers := make([]bool, dataset.Count())
+	for i, vec := range dataset.data {
+		for d := range standardizer.Mean {
+			if math.Abs(scale(Component(vec, d)-standardizer.Mean[d], standardizer.StdDev[d])) > threshold {
+				outliers[i] = true
+				break
+			}
+		}
+	}
+	cleaned, removed := dataset.separate(outliers)
+	return cleaned, removed, nil
+}
+
+// RemoveLowDensityOutliers will split this dataset into its vectors in dense regions and the fraction `frac` of vectors in the sparsest regions,
+// where the density around a vector is measured by the distance to its `k`th nearest neighbour. Both datasets keep the original order.
+// All pairwise distances are computed, using all cores, so the metric must be safe for concurrent use.
+func (dataset *Dataset) RemoveLowDensityOutliers(k int, frac float64, metric Metric) (Dataset, Dataset, error) {
+	n := dataset.Count()
+	if k <= 0 || k >= n {
+		return Dataset{}, Dataset{}, fmt.Errorf("Expected between 1 and %d neighbours but got %d", n-1, k)
+	}
+	if frac < 0 || frac > 1 || math.IsNaN(frac) {
+		return Dataset{}, Dataset{}, fmt.Errorf("Expected a fraction between 0 and 1 but got %v", frac)
+	}
+	kDistances := make([]float64, n)
+	parallelFor(n, func(i int) {
+		distances := make([]float64, 0, n-1)
+		for j, other := range dataset.data {
+			if j != i {
+				distances = append(distances, metric(dataset.data[i], other))
+			}
+		}
+		sort.Float64s(distances)
+		kDistances[i] = distances[k-1]
+	})
+
+	bySparsity := make([]int, n)
+	for i := range bySparsity {
+		bySparsity[i] = i
+	}
+	sort.SliceStable(bySparsity, func(a, b int) bool { return kDistances[bySparsity[a]] > kDistances[bySparsity[b]] })
+	outliers := make([]bool, n)
+	for _, i := range bySparsity[:int(math.Round(frac*float64(n)))] {
+		outliers[i] = true
+	}
+	cleaned, removed := dataset.separate(outliers)
+	return cleaned, removed, nil
+}
+
+// separate splits this dataset into the vectors that are not marked and those that are.
+func (dataset *Dataset) separate(marked []bool) (Dataset, Dataset) {
+	var unmarked, selected []int
+	for i, isMarked := range marked {
+		if isMarked {
+			selected = append(selected, i)
+		} else {
+			unmarked = append(unmarked, i)
+		}
+	}
+	return dataset.subset(unmarked), dataset.subset(selected)
+}
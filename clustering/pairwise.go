@@ -0,0 +1,117 @@
+package clustering
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// CondensedDistanceMatrix is a DistanceMatrix only storing the distances in its upper triangle, using half the memory of a DenseDistanceMatrix.
+// The distance between an element and itself is always 0 and the distances are assumed to be symmetric.
+type CondensedDistanceMatrix struct {
+	size      int
+	distances []float64
+}
+
+// Size returns the number of elements in the collection.
+func (matrix *CondensedDistanceMatrix) Size() int {
+	return matrix.size
+}
+
+// Distance returns the distance between the `i`th and `j`th element.
+func (matrix *CondensedDistanceMatrix) Distance(i, j int) float64 {
+	if i == j {
+		return 0
+	} else if i > j {
+		i, j = j, i
+	}
+	return matrix.distances[condensedIndex(matrix.size, i, j)]
+}
+
+// PairwiseDistances will compute the distance between every pair of elements of the dataset using all cores.
+// The metric must be safe for concurrent use.
+func PairwiseDistances(dataset *Dataset, metric Metric) DenseDistanceMatrix {
+	data := dataset.data
+	distances := make(DenseDistanceMatrix, len(data))
+	for i := range distances {
+		distances[i] = make([]float64, len(data))
+	}
+	parallelFor(len(data), func(i int) {
+		for j := i + 1; j < len(data); j++ {
+			distance := metric(data[i], data[j])
+			distances[i][j], distances[j][i] = distance, distance
+		}
+	})
+	return distances
+}
+
+// CondensedPairwiseDistances will compute the distance between every pair of elements of the dataset using all cores, keeping only the upper triangle.
+// The metric must be safe for concurrent use and is assumed to be symmetric.
+func CondensedPairwiseDistances(dataset *Dataset, metric Metric) *CondensedDistanceMatrix {
+	data := dataset.data
+	n := len(data)
+	matrix := &CondensedDistanceMatrix{size: n, distances: make([]float64, n*(n-1)/2)}
+	parallelFor(n, func(i int) {
+		for j := i + 1; j < n; j++ {
+			matrix.distances[condensedIndex(n, i, j)] = metric(data[i], data[j])
+		}
+	})
+	return matrix
+}
+
+// PairwiseDistanceBlocks will compute the distance matrix of the dataset using all cores in blocks of at most `rows` rows,
+// handing every block to `f` before computing the next one so that at most `rows * n` distances are kept in memory.
+// The block passed to `f` starts at row `from` and is reused afterwards, computation stops at the first error returned by `f`.
+// The metric must be safe for concurrent use.
+func PairwiseDistanceBlocks(dataset *Dataset, metric Metric, rows int, f func(from int, block [][]float64) error) error {
+	if rows <= 0 {
+		return fmt.Errorf("Expected a positive number of rows per block but got %d", rows)
+	}
+	data := dataset.data
+	block := make([][]float64, rows)
+	for i := range block {
+		block[i] = make([]float64, len(data))
+	}
+	for from := 0; from < len(data); from += rows {
+		block = block[:min(rows, len(data)-from)]
+		parallelFor(len(block), func(i int) {
+			for j := range data {
+				block[i][j] = metric(data[from+i], data[j])
+			}
+		})
+		if err := f(from, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parallelFor calls `f` for every index from 0 up to `n` spread over as many goroutines as there are cores.
+// A panic in `f` stops the other goroutines from taking further indices and is raised again on the calling goroutine, such that callers can recover from it.
+func parallelFor(n int, f func(i int)) {
+	workers := min(runtime.NumCPU(), n)
+	var next int64 = -1
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked interface{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicked = r })
+					atomic.StoreInt64(&next, int64(n))
+				}
+			}()
+			for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
@@ -0,0 +1,164 @@
+// Package parquetio reads clustering datasets from Parquet files, such as those exported by Spark or pandas.
+package parquetio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/frederikdesmedt/clustering"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Load will read the selected numeric columns of every row of the Parquet file into a dataset of VectorNs.
+// Nested columns are selected by their path with the names of the fields separated by dots, e.g., `position.x`.
+func Load(r io.ReaderAt, size int64, columns ...string) (clustering.Dataset, error) {
+	var data []clustering.Vector
+	err := Stream(r, size, columns, func(rowGroup clustering.Dataset) error {
+		data = append(data, rowGroup.Vectors()...)
+		return nil
+	})
+	if err != nil {
+		return clustering.Dataset{}, err
+	}
+	return clustering.CreateDataset(data, make(clustering.VectorN, len(columns)).Creator()), nil
+}
+
+// Stream will read the selected numeric columns of the Parquet file one row group at a time,
+// handing the rows of every row group as a dataset of VectorNs to `f` before reading the next row group.
+// Only the selected columns are read, reading stops at the first error returned by `f`.
+func Stream(r io.ReaderAt, size int64, columns []string, f func(clustering.Dataset) error) error {
+	if len(columns) == 0 {
+		return errors.New("Expected at least one column to read")
+	}
+	var file *parquet.File
+	indices := make([]int, len(columns))
+	var rowGroups []parquet.RowGroup
+	err := guard(func() (err error) {
+		if file, err = parquet.OpenFile(r, size); err != nil {
+			return err
+		}
+		for i, column := range columns {
+			leaf, ok := file.Schema().Lookup(strings.Split(column, ".")...)
+			if !ok {
+				return fmt.Errorf("The Parquet file has no column %q", column)
+			}
+			indices[i] = leaf.ColumnIndex
+		}
+		rowGroups = file.RowGroups()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rowGroup := range rowGroups {
+		var data []clustering.Vector
+		err := guard(func() error {
+			rows := make([]clustering.VectorN, rowGroup.NumRows())
+			for i := range rows {
+				rows[i] = make(clustering.VectorN, len(columns))
+			}
+			chunks := rowGroup.ColumnChunks()
+			for component, index := range indices {
+				if err := readColumn(chunks[index], rows, component, columns[component]); err != nil {
+					return err
+				}
+			}
+			data = make([]clustering.Vector, len(rows))
+			for i, row := range rows {
+				data[i] = row
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := f(clustering.CreateDataset(data, make(clustering.VectorN, len(columns)).Creator())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guard calls `read`, returning the panics the Parquet library raises on some corrupt files as an error.
+func guard(read func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("The Parquet file is corrupt: %v", r)
+		}
+	}()
+	return read()
+}
+
+// readColumn reads the values of a column chunk into the `component`th component of the rows of its row group.
+func readColumn(chunk parquet.ColumnChunk, rows []clustering.VectorN, component int, name string) error {
+	pages := chunk.Pages()
+	defer pages.Close()
+
+	for row := 0; ; {
+		page, err := pages.ReadPage()
+		if err == io.EOF {
+			if row != len(rows) {
+				return fmt.Errorf("Could not read column %q: The column has %d values but its row group has %d rows", name, row, len(rows))
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if row, err = readPage(page, rows, row, component); err != nil {
+			return fmt.Errorf("Could not read column %q: %v", name, err)
+		}
+	}
+}
+
+// readPage reads the values of a page into the rows starting at row `row` and returns the row following the last value of the page.
+func readPage(page parquet.Page, rows []clustering.VectorN, row int, component int) (int, error) {
+	defer parquet.Release(page)
+
+	values := page.Values()
+	buffer := make([]parquet.Value, 1024)
+	for {
+		n, readErr := values.ReadValues(buffer)
+		for _, value := range buffer[:n] {
+			if row >= len(rows) {
+				return row, errors.New("The column has more values than its row group has rows, repeated columns are not supported")
+			}
+			converted, err := asFloat(value)
+			if err != nil {
+				return row, fmt.Errorf("Row %d: %v", row, err)
+			}
+			rows[row][component] = converted
+			row++
+		}
+		if readErr == io.EOF {
+			return row, nil
+		} else if readErr != nil {
+			return row, readErr
+		}
+	}
+}
+
+func asFloat(value parquet.Value) (float64, error) {
+	if value.IsNull() {
+		return 0, errors.New("Null values are not supported")
+	}
+	switch value.Kind() {
+	case parquet.Boolean:
+		if value.Boolean() {
+			return 1, nil
+		}
+		return 0, nil
+	case parquet.Int32:
+		return float64(value.Int32()), nil
+	case parquet.Int64:
+		return float64(value.Int64()), nil
+	case parquet.Float:
+		return float64(value.Float()), nil
+	case parquet.Double:
+		return value.Double(), nil
+	default:
+		return 0, fmt.Errorf("Values of kind %v are not numeric", value.Kind())
+	}
+}
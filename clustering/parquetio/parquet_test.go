@@ -0,0 +1,127 @@
+package parquetio
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/frederikdesmedt/clustering"
+	"github.com/parquet-go/parquet-go"
+)
+
+type position struct {
+	Z float32 `parquet:"z"`
+}
+
+type row struct {
+	X        float64  `parquet:"x"`
+	Y        int32    `parquet:"y"`
+	Count    int64    `parquet:"count"`
+	Flag     bool     `parquet:"flag"`
+	Position position `parquet:"position"`
+	Label    string   `parquet:"label"`
+	Missing  *float64 `parquet:"missing,optional"`
+}
+
+// writeRows writes the rows as a Parquet file with a row group of every `perGroup` rows.
+func writeRows(t *testing.T, rows []row, perGroup int) []byte {
+	var buffer bytes.Buffer
+	writer := parquet.NewGenericWriter[row](&buffer)
+	for start := 0; start < len(rows); start += perGroup {
+		if _, err := writer.Write(rows[start:min(start+perGroup, len(rows))]); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func testRows(n int) []row {
+	rows := make([]row, n)
+	for i := range rows {
+		rows[i] = row{X: float64(i) / 2, Y: int32(-i), Count: int64(i * i), Flag: i%2 == 0, Position: position{Z: float32(i) + 0.25}, Label: "row"}
+	}
+	return rows
+}
+
+func TestLoad(t *testing.T) {
+	rows := testRows(25)
+	data := writeRows(t, rows, 10)
+	dataset, err := Load(bytes.NewReader(data), int64(len(data)), "x", "y", "count", "flag", "position.z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataset.Count() != len(rows) {
+		t.Fatalf("Expected %d vectors but got %d", len(rows), dataset.Count())
+	}
+	for i, r := range rows {
+		flag := 0.0
+		if r.Flag {
+			flag = 1
+		}
+		want := clustering.VectorN{r.X, float64(r.Y), float64(r.Count), flag, float64(r.Position.Z)}
+		got := dataset.At(i).(clustering.VectorN)
+		for d := range want {
+			if got[d] != want[d] {
+				t.Fatalf("Expected vector %d to be %v but got %v", i, want, got)
+			}
+		}
+	}
+}
+
+func TestStreamReadsEveryRowGroup(t *testing.T) {
+	data := writeRows(t, testRows(25), 10)
+	var sizes []int
+	err := Stream(bytes.NewReader(data), int64(len(data)), []string{"x"}, func(rowGroup clustering.Dataset) error {
+		sizes = append(sizes, rowGroup.Count())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 3 || sizes[0] != 10 || sizes[1] != 10 || sizes[2] != 5 {
+		t.Errorf("Expected row groups of 10, 10 and 5 rows but got %v", sizes)
+	}
+}
+
+func TestLoadRejectsUnsupportedColumns(t *testing.T) {
+	rows := testRows(5)
+	value := 1.0
+	rows[3].Missing = &value
+	data := writeRows(t, rows, 10)
+	for _, columns := range [][]string{{}, {"absent"}, {"position"}, {"label"}, {"missing"}} {
+		if _, err := Load(bytes.NewReader(data), int64(len(data)), columns...); err == nil {
+			t.Errorf("Expected an error loading the columns %q", columns)
+		}
+	}
+}
+
+func TestLoadRejectsCorruptFiles(t *testing.T) {
+	data := writeRows(t, testRows(50), 20)
+	load := func(corrupt []byte) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("Loading a corrupt file panicked: %v", r)
+			}
+		}()
+		Load(bytes.NewReader(corrupt), int64(len(corrupt)), "x", "y", "position.z")
+	}
+	for _, length := range []int{0, 4, 8, len(data) / 2, len(data) - 8, len(data) - 1} {
+		if _, err := Load(bytes.NewReader(data[:length]), int64(length), "x"); err == nil {
+			t.Errorf("Loading the first %d of %d bytes succeeded", length, len(data))
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		corrupt := append([]byte(nil), data...)
+		for i := 0; i < 4; i++ {
+			corrupt[rng.Intn(len(corrupt))] = byte(rng.Intn(256))
+		}
+		load(corrupt)
+	}
+}
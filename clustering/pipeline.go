@@ -0,0 +1,113 @@
+package clustering
+
+import "errors"
+
+// TransformerFitter fits a Transformer on a dataset, e.g., a scaler learning the range of every component.
+type TransformerFitter func(dataset *Dataset) (Transformer, error)
+
+// ClustererFitter fits a clusterer on a dataset.
+type ClustererFitter func(dataset *Dataset) (SimpleFlatClusterer, error)
+
+// Fitted will turn a function fitting a concrete Transformer, such as FitStandardizer or FitMinMaxScaler, into a TransformerFitter.
+func Fitted[T Transformer](fit func(dataset *Dataset) (T, error)) TransformerFitter {
+	return func(dataset *Dataset) (Transformer, error) {
+		transformer, err := fit(dataset)
+		if err != nil {
+			return nil, err
+		}
+		return transformer, nil
+	}
+}
+
+// Fixed will return a TransformerFitter that ignores the dataset and always results in the transformer, e.g., for a RandomProjection.
+func Fixed(transformer Transformer) TransformerFitter {
+	return func(*Dataset) (Transformer, error) {
+		return transformer, nil
+	}
+}
+
+// KMeansFitter will return a ClustererFitter performing K-Means clustering with `k` clusters.
+func KMeansFitter(k int) ClustererFitter {
+	return func(dataset *Dataset) (SimpleFlatClusterer, error) {
+		clusterer := dataset.KMeans(k)
+		return &clusterer, nil
+	}
+}
+
+// Pipeline chains preprocessing steps with a clustering algorithm, such that vectors to cluster are preprocessed exactly like the dataset the clusterer was fitted on.
+type Pipeline struct {
+	// Preprocessors are fitted in order, every preprocessor is fitted on the dataset transformed by all previous ones.
+	Preprocessors []TransformerFitter
+	// Clusterer is fitted on the dataset transformed by all preprocessors.
+	Clusterer ClustererFitter
+}
+
+// FittedPipeline is a SimpleFlatClusterer transforming every vector by the fitted preprocessors before assigning it to a cluster of the fitted clusterer.
+type FittedPipeline struct {
+	// Transformers holds the fitted preprocessors in the order they are applied.
+	Transformers []Transformer
+	// Clusterer is the clusterer fitted on the transformed dataset.
+	Clusterer SimpleFlatClusterer
+}
+
+// Fit will fit every step of the pipeline on the dataset, in order.
+func (pipeline Pipeline) Fit(dataset *Dataset) (FittedPipeline, error) {
+	if pipeline.Clusterer == nil {
+		return FittedPipeline{}, errors.New("Expected a pipeline with a clusterer")
+	}
+	fitted := FittedPipeline{Transformers: make([]Transformer, len(pipeline.Preprocessors))}
+	transformed := *dataset
+	for i, preprocessor := range pipeline.Preprocessors {
+		transformer, err := preprocessor(&transformed)
+		if err != nil {
+			return FittedPipeline{}, err
+		}
+		fitted.Transformers[i] = transformer
+		transformed = transformed.Transform(transformer)
+	}
+	clusterer, err := pipeline.Clusterer(&transformed)
+	if err != nil {
+		return FittedPipeline{}, err
+	}
+	fitted.Clusterer = clusterer
+	return fitted, nil
+}
+
+// Transform maps the vector through all fitted preprocessors.
+func (pipeline FittedPipeline) Transform(v Vector) Vector {
+	for _, transformer := range pipeline.Transformers {
+		v = transformer.Transform(v)
+	}
+	return v
+}
+
+// Predict returns the cluster the preprocessed vector is assigned to.
+func (pipeline FittedPipeline) Predict(v Vector) (Cluster, error) {
+	return pipeline.Clusterer.FindCluster(pipeline.Transform(v))
+}
+
+// FindCluster returns the unique cluster a vector is a part of, which is the cluster it is predicted to belong to.
+func (pipeline FittedPipeline) FindCluster(v Vector) (Cluster, error) {
+	return pipeline.Predict(v)
+}
+
+// Clusters returns all the clusters this clusterer contains.
+func (pipeline FittedPipeline) Clusters() []Cluster {
+	return pipeline.Clusterer.Clusters()
+}
+
+// ClusteredPartition will split the dataset according to the cluster each element belongs to
+// such that every element in the dataset is assigned to exactly one cluster and the
+// union of all vector slices is equal to the datapoint slice of the original dataset.
+// The partition holds the original, untransformed, vectors.
+func (pipeline FittedPipeline) ClusteredPartition(dataset *Dataset) (map[Cluster][]Vector, error) {
+	partition := make(map[Cluster][]Vector)
+	for _, vec := range dataset.data {
+		cluster, err := pipeline.Predict(vec)
+		if err != nil {
+			return nil, err
+		}
+		partition[cluster] = append(partition[cluster], vec)
+	}
+	return partition, nil
+}
@@ -0,0 +1,113 @@
+package clustering
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// PLYOptions determine how WritePLY exports a clustering.
+type PLYOptions struct {
+	// Colours holds the RGB colour of every cluster, in the order of the clusters of the clusterer, and is cycled if there are more clusters.
+	// A palette of ten distinct colours is used when it is not set.
+	Colours [][3]uint8
+	// Centroids adds an octahedron in the colour of every cluster at its centroid: the centroids of a *CentroidClusterer, or the mean of the vectors of every cluster of any other clusterer.
+	Centroids bool
+	// MarkerSize is the distance between the centroid and the vertices of its octahedron, it defaults to 2% of the diagonal of the bounding box of the dataset.
+	MarkerSize float64
+}
+
+// plyPalette is the default palette of WritePLY (Tableau 10).
+var plyPalette = [][3]uint8{
+	{31, 119, 180}, {255, 127, 14}, {44, 160, 44}, {214, 39, 40}, {148, 103, 189},
+	{140, 86, 75}, {227, 119, 194}, {127, 127, 127}, {188, 189, 34}, {23, 190, 207},
+}
+
+// plyNoiseColour is the colour of vectors assigned to Noise.
+var plyNoiseColour = [3]uint8{200, 200, 200}
+
+// The vertices and faces of an octahedron with vertices at unit distance from its centre.
+var (
+	octahedronVertices = [6][3]float64{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+	octahedronFaces    = [8][3]int{{0, 2, 4}, {2, 1, 4}, {1, 3, 4}, {3, 0, 4}, {2, 0, 5}, {1, 2, 5}, {3, 1, 5}, {0, 3, 5}}
+)
+
+// WritePLY will write the three-dimensional vectors of the dataset as an ASCII PLY point cloud in which every vector is coloured by the cluster the clusterer assigns it to,
+// e.g., to inspect the clustering of a point cloud in MeshLab or CloudCompare. Vectors that are Noise are light grey.
+func WritePLY(w io.Writer, dataset *Dataset, clusterer SimpleFlatClusterer, opts PLYOptions) error {
+	if len(opts.Colours) == 0 {
+		opts.Colours = plyPalette
+	}
+	for i, vec := range dataset.data {
+		if Dimension(vec) != 3 {
+			return fmt.Errorf("Expected three-dimensional vectors but vector %d has dimension %d", i, Dimension(vec))
+		}
+	}
+	clusters, err := dataset.FindClusters(clusterer)
+	if err != nil {
+		return err
+	}
+	colours := make(map[Cluster][3]uint8)
+	for _, cluster := range clusterer.Clusters() {
+		colours[cluster] = opts.Colours[len(colours)%len(opts.Colours)]
+	}
+	for _, cluster := range clusters {
+		if _, ok := colours[cluster]; !ok && cluster != Noise {
+			colours[cluster] = opts.Colours[len(colours)%len(opts.Colours)]
+		}
+	}
+	colours[Noise] = plyNoiseColour
+
+	var markers []Cluster
+	var centroids map[Cluster]Vector
+	if opts.Centroids && len(clusters) > 0 {
+		centroids = fittedCentroids(dataset, clusterer, clusters)
+		for cluster := range centroids {
+			if cluster != Noise {
+				markers = append(markers, cluster)
+			}
+		}
+		sort.Slice(markers, func(i, j int) bool { return markers[i] < markers[j] })
+		if opts.MarkerSize <= 0 {
+			lower, upper := components(dataset.data[0]), components(dataset.data[0])
+			for _, vec := range dataset.data {
+				for d := range lower {
+					lower[d], upper[d] = math.Min(lower[d], Component(vec, d)), math.Max(upper[d], Component(vec, d))
+				}
+			}
+			opts.MarkerSize = 0.02 * math.Sqrt(squaredDistance(lower, upper))
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "ply\nformat ascii 1.0\ncomment clusters coloured by go-clustering\n")
+	fmt.Fprintf(writer, "element vertex %d\n", dataset.Count()+len(markers)*len(octahedronVertices))
+	fmt.Fprintf(writer, "property float x\nproperty float y\nproperty float z\nproperty uchar red\nproperty uchar green\nproperty uchar blue\n")
+	if len(markers) > 0 {
+		fmt.Fprintf(writer, "element face %d\nproperty list uchar int vertex_indices\n", len(markers)*len(octahedronFaces))
+	}
+	fmt.Fprintf(writer, "end_header\n")
+	vertex := func(x, y, z float64, colour [3]uint8) {
+		fmt.Fprintf(writer, "%s %s %s %d %d %d\n", strconv.FormatFloat(x, 'g', -1, 32), strconv.FormatFloat(y, 'g', -1, 32), strconv.FormatFloat(z, 'g', -1, 32),
+			colour[0], colour[1], colour[2])
+	}
+	for i, vec := range dataset.data {
+		vertex(Component(vec, 0), Component(vec, 1), Component(vec, 2), colours[clusters[i]])
+	}
+	for _, cluster := range markers {
+		centroid := centroids[cluster]
+		for _, offset := range octahedronVertices {
+			vertex(Component(centroid, 0)+opts.MarkerSize*offset[0], Component(centroid, 1)+opts.MarkerSize*offset[1], Component(centroid, 2)+opts.MarkerSize*offset[2], colours[cluster])
+		}
+	}
+	for i := range markers {
+		first := dataset.Count() + i*len(octahedronVertices)
+		for _, face := range octahedronFaces {
+			fmt.Fprintf(writer, "3 %d %d %d\n", first+face[0], first+face[1], first+face[2])
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,120 @@
+package clustering
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The subset of PMML 4.4 needed to describe a center-based ClusteringModel.
+type pmmlDocument struct {
+	XMLName        xml.Name            `xml:"http://www.dmg.org/PMML-4_4 PMML"`
+	Version        string              `xml:"version,attr"`
+	Header         pmmlHeader          `xml:"Header"`
+	DataDictionary pmmlDataDictionary  `xml:"DataDictionary"`
+	Model          pmmlClusteringModel `xml:"ClusteringModel"`
+}
+
+type pmmlHeader struct {
+	Description string `xml:"description,attr"`
+	Application struct {
+		Name string `xml:"name,attr"`
+	} `xml:"Application"`
+}
+
+type pmmlDataDictionary struct {
+	NumberOfFields int             `xml:"numberOfFields,attr"`
+	Fields         []pmmlDataField `xml:"DataField"`
+}
+
+type pmmlDataField struct {
+	Name     string `xml:"name,attr"`
+	OpType   string `xml:"optype,attr"`
+	DataType string `xml:"dataType,attr"`
+}
+
+type pmmlClusteringModel struct {
+	ModelName         string            `xml:"modelName,attr"`
+	FunctionName      string            `xml:"functionName,attr"`
+	ModelClass        string            `xml:"modelClass,attr"`
+	NumberOfClusters  int               `xml:"numberOfClusters,attr"`
+	MiningFields      []pmmlMiningField `xml:"MiningSchema>MiningField"`
+	ComparisonMeasure struct {
+		Kind             string   `xml:"kind,attr"`
+		SquaredEuclidean struct{} `xml:"squaredEuclidean"`
+	} `xml:"ComparisonMeasure"`
+	ClusteringFields []pmmlClusteringField `xml:"ClusteringField"`
+	Clusters         []pmmlCluster         `xml:"Cluster"`
+}
+
+type pmmlMiningField struct {
+	Name string `xml:"name,attr"`
+}
+
+type pmmlClusteringField struct {
+	Field           string `xml:"field,attr"`
+	CompareFunction string `xml:"compareFunction,attr"`
+}
+
+type pmmlCluster struct {
+	ID    string `xml:"id,attr"`
+	Name  string `xml:"name,attr"`
+	Array struct {
+		N     int    `xml:"n,attr"`
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Array"`
+}
+
+// WritePMML will write the centroids as a PMML 4.4 ClusteringModel, e.g., to deploy a model on a scoring engine.
+// The model assigns a record to the cluster of the nearest centroid by squared Euclidean distance, with the id of every cluster being the index of its centroid.
+// The fields name the components of the vectors, they default to `x0`, `x1`, ... like WriteCSV.
+func (clusterer CentroidClusterer) WritePMML(w io.Writer, fields ...string) error {
+	encoded, centroids, err := newJSONModel("centroids", clusterer)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		for i := 0; i < encoded.Dimension; i++ {
+			fields = append(fields, "x"+strconv.Itoa(i))
+		}
+	}
+	if len(fields) != encoded.Dimension {
+		return fmt.Errorf("Expected %d field names but got %d", encoded.Dimension, len(fields))
+	}
+
+	document := pmmlDocument{Version: "4.4"}
+	document.Header.Description = "K-Means clustering model"
+	document.Header.Application.Name = "go-clustering"
+	document.DataDictionary.NumberOfFields = len(fields)
+	model := &document.Model
+	model.ModelName, model.FunctionName, model.ModelClass, model.NumberOfClusters = "k-means", "clustering", "centerBased", len(centroids)
+	model.ComparisonMeasure.Kind = "distance"
+	for _, field := range fields {
+		document.DataDictionary.Fields = append(document.DataDictionary.Fields, pmmlDataField{Name: field, OpType: "continuous", DataType: "double"})
+		model.MiningFields = append(model.MiningFields, pmmlMiningField{Name: field})
+		model.ClusteringFields = append(model.ClusteringFields, pmmlClusteringField{Field: field, CompareFunction: "absDiff"})
+	}
+	for i, centroid := range centroids {
+		cluster := pmmlCluster{ID: strconv.Itoa(i), Name: strconv.Itoa(i)}
+		values := make([]string, len(centroid))
+		for j, value := range centroid {
+			values[j] = strconv.FormatFloat(value, 'g', -1, 64)
+		}
+		cluster.Array.N, cluster.Array.Type, cluster.Array.Value = len(values), "real", strings.Join(values, " ")
+		model.Clusters = append(model.Clusters, cluster)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,90 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// RandomProjection is a Transformer mapping vectors onto a lower dimensional space by multiplying them with a random matrix.
+// By the Johnson-Lindenstrauss lemma the distances between the projected vectors are close to the original distances with high probability,
+// see JohnsonLindenstraussDimension, making it a cheap alternative to PCA for very high-dimensional data such as text.
+type RandomProjection struct {
+	from int
+	// rows holds the non-zero entries of every row of the projection matrix.
+	rows [][]projectionEntry
+}
+
+type projectionEntry struct {
+	column int
+	value  float64
+}
+
+// NewGaussianProjection will create a RandomProjection from `from` to `to` dimensions whose matrix entries are drawn from `N(0, 1/to)`.
+func NewGaussianProjection(from, to int, src rand.Source) RandomProjection {
+	checkProjectionDimensions(from, to)
+	rng := rand.New(src)
+	projection := RandomProjection{from: from, rows: make([][]projectionEntry, to)}
+	deviation := 1 / math.Sqrt(float64(to))
+	for i := range projection.rows {
+		projection.rows[i] = make([]projectionEntry, from)
+		for j := range projection.rows[i] {
+			projection.rows[i][j] = projectionEntry{column: j, value: rng.NormFloat64() * deviation}
+		}
+	}
+	return projection
+}
+
+// NewSparseProjection will create a RandomProjection from `from` to `to` dimensions whose matrix only has a fraction `density` of non-zero entries,
+// which are `±1/sqrt(density * to)` with equal probability (Achlioptas, Li et al.). A non-positive density uses the recommended `1/sqrt(from)`.
+// The sparse matrix is cheaper to store and apply than a Gaussian one while offering similar guarantees.
+func NewSparseProjection(from, to int, density float64, src rand.Source) RandomProjection {
+	checkProjectionDimensions(from, to)
+	if density <= 0 {
+		density = 1 / math.Sqrt(float64(from))
+	} else if density > 1 {
+		panic(fmt.Sprintf("Expected a density of at most 1 but got %v", density))
+	}
+	rng := rand.New(src)
+	projection := RandomProjection{from: from, rows: make([][]projectionEntry, to)}
+	magnitude := 1 / math.Sqrt(density*float64(to))
+	for i := range projection.rows {
+		for j := 0; j < from; j++ {
+			if rng.Float64() < density {
+				value := magnitude
+				if rng.Intn(2) == 0 {
+					value = -magnitude
+				}
+				projection.rows[i] = append(projection.rows[i], projectionEntry{column: j, value: value})
+			}
+		}
+	}
+	return projection
+}
+
+func checkProjectionDimensions(from, to int) {
+	if from <= 0 || to <= 0 {
+		panic(fmt.Sprintf("Expected positive dimensions but got %d and %d", from, to))
+	}
+}
+
+// Transform projects the vector onto the lower dimensional space, the result is a VectorN.
+func (projection RandomProjection) Transform(v Vector) Vector {
+	checkTransformDimension(v, projection.from)
+	result := make(VectorN, len(projection.rows))
+	for i, row := range projection.rows {
+		for _, entry := range row {
+			result[i] += entry.value * Component(v, entry.column)
+		}
+	}
+	return result
+}
+
+// JohnsonLindenstraussDimension will return the minimal dimension a random projection of `n` vectors needs
+// to keep all pairwise distances within a factor `1 ± eps` of the original distances with high probability.
+func JohnsonLindenstraussDimension(n int, eps float64) int {
+	if eps <= 0 || eps >= 1 {
+		panic(fmt.Sprintf("Expected an eps strictly between 0 and 1 but got %v", eps))
+	}
+	return int(math.Ceil(4 * math.Log(float64(n)) / (eps*eps/2 - eps*eps*eps/3)))
+}
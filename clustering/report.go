@@ -0,0 +1,142 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ClusterSummary describes a single cluster of a clustering.
+type ClusterSummary struct {
+	// Cluster is the summarized cluster.
+	Cluster Cluster
+	// Size is the number of vectors in the cluster.
+	Size int
+	// Centroid is the mean of the vectors in the cluster.
+	Centroid Vector
+	// Radius is the largest distance between a vector of the cluster and the centroid.
+	Radius float64
+	// MeanDistance is the mean distance between two distinct vectors of the cluster, 0 for a singleton cluster.
+	MeanDistance float64
+	// Variance holds the (population) variance of every component within the cluster.
+	Variance []float64
+}
+
+// ClusterReport will summarize every cluster of the clustering of the dataset by the clusterer in increasing order of the clusters, measuring distances by the metric,
+// e.g., to monitor the clusters of a production model. The mean distances need all pairwise distances within every cluster, which are computed using all cores,
+// so the metric must be safe for concurrent use.
+func ClusterReport(dataset *Dataset, clusterer SimpleFlatClusterer, metric Metric) ([]ClusterSummary, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return nil, err
+	}
+	partition := PartitionIndices(assignments)
+	report := make([]ClusterSummary, 0, len(partition))
+	for cluster, indices := range partition {
+		report = append(report, summarizeCluster(dataset, cluster, indices, metric))
+	}
+	sort.Slice(report, func(a, b int) bool { return report[a].Cluster < report[b].Cluster })
+	return report, nil
+}
+
+func summarizeCluster(dataset *Dataset, cluster Cluster, indices []int, metric Metric) ClusterSummary {
+	members := dataset.subset(indices)
+	_, variance := covariance(&members)
+	summary := ClusterSummary{Cluster: cluster, Size: len(indices), Variance: make([]float64, len(variance))}
+	for d := range variance {
+		summary.Variance[d] = variance[d][d]
+	}
+	var collector bucketCollector
+	for _, vec := range members.data {
+		collector.Collect(vec, 1)
+	}
+	summary.Centroid = collector.Average()
+	for _, vec := range members.data {
+		summary.Radius = max(summary.Radius, metric(vec, summary.Centroid))
+	}
+	if len(indices) > 1 {
+		totals := make([]float64, len(indices))
+		parallelFor(len(indices), func(i int) {
+			for j := i + 1; j < len(indices); j++ {
+				totals[i] += metric(members.data[i], members.data[j])
+			}
+		})
+		summary.MeanDistance = sum(totals) / choose2(len(indices))
+	}
+	return summary
+}
+
+// Separation describes the distances between the centroids of the clusters of a clustering, e.g., to detect redundant clusters that should be merged.
+type Separation struct {
+	// Clusters holds the clusters in increasing order.
+	Clusters []Cluster
+	// Centroids holds the mean of the vectors in every cluster of Clusters.
+	Centroids []Vector
+	// Spreads holds the mean distance between the vectors of every cluster of Clusters and its centroid.
+	Spreads []float64
+	// Distances holds the distance between the centroids of clusters `Clusters[i]` and `Clusters[j]` at `Distances[i][j]`.
+	Distances [][]float64
+	// Min, Max and Mean are the smallest, largest and mean distance between the centroids of two distinct clusters.
+	Min, Max, Mean float64
+	// Closest holds the two distinct clusters whose centroids are closest.
+	Closest [2]Cluster
+}
+
+// CentroidSeparation will compute the distances between the centroids of every pair of clusters of the clustering of the dataset by the clusterer, measured by the metric.
+func CentroidSeparation(dataset *Dataset, clusterer SimpleFlatClusterer, metric Metric) (Separation, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return Separation{}, err
+	}
+	clusters := sortedClusters(assignments)
+	if len(clusters) < 2 {
+		return Separation{}, fmt.Errorf("Expected at least two clusters but got %d", len(clusters))
+	}
+	rows := clusterIndices(clusters)
+	buckets := make([]bucketCollector, len(clusters))
+	for i, vec := range dataset.data {
+		buckets[rows[assignments[i]]].Collect(vec, 1)
+	}
+	separation := Separation{Clusters: clusters, Centroids: make([]Vector, len(clusters)), Spreads: make([]float64, len(clusters)), Min: math.Inf(1)}
+	for i := range buckets {
+		separation.Centroids[i] = buckets[i].Average()
+	}
+	sizes := make([]int, len(clusters))
+	for i, vec := range dataset.data {
+		row := rows[assignments[i]]
+		separation.Spreads[row] += metric(vec, separation.Centroids[row])
+		sizes[row]++
+	}
+	for i := range separation.Spreads {
+		separation.Spreads[i] /= float64(sizes[i])
+	}
+
+	separation.Distances = newMatrix(len(clusters), len(clusters))
+	total := 0.0
+	for i := range clusters {
+		for j := i + 1; j < len(clusters); j++ {
+			distance := metric(separation.Centroids[i], separation.Centroids[j])
+			separation.Distances[i][j], separation.Distances[j][i] = distance, distance
+			total += distance
+			if distance < separation.Min {
+				separation.Min, separation.Closest = distance, [2]Cluster{clusters[i], clusters[j]}
+			}
+			separation.Max = max(separation.Max, distance)
+		}
+	}
+	separation.Mean = total / choose2(len(clusters))
+	return separation, nil
+}
+
+// Overlapping returns the pairs of distinct clusters whose centroids are closer than the sum of their spreads, which are candidates to be merged.
+func (separation Separation) Overlapping() [][2]Cluster {
+	var pairs [][2]Cluster
+	for i := range separation.Clusters {
+		for j := i + 1; j < len(separation.Clusters); j++ {
+			if separation.Distances[i][j] < separation.Spreads[i]+separation.Spreads[j] {
+				pairs = append(pairs, [2]Cluster{separation.Clusters[i], separation.Clusters[j]})
+			}
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,166 @@
+package clustering
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// RPForestOptions configures an RPForest, every option that is not set falls back to its default.
+type RPForestOptions struct {
+	// Trees is the number of random projection trees, more trees are more accurate but use more memory, 10 by default.
+	Trees int
+	// LeafSize is the maximal number of vectors in a leaf, 16 by default.
+	LeafSize int
+	// Candidates is the minimal number of vectors whose distance to the query is measured, larger values are slower but more accurate,
+	// the number of trees times the leaf size by default.
+	Candidates int
+}
+
+func (opts RPForestOptions) withDefaults() RPForestOptions {
+	if opts.Trees <= 0 {
+		opts.Trees = 10
+	}
+	if opts.LeafSize <= 0 {
+		opts.LeafSize = 16
+	}
+	if opts.Candidates <= 0 {
+		opts.Candidates = opts.Trees * opts.LeafSize
+	}
+	return opts
+}
+
+// RPForest is a forest of random projection trees in the style of Annoy, an approximate NeighbourIndex that is cheap to build and to store.
+// Every tree recursively splits the vectors by the hyperplane halfway between two random vectors, and a query gathers candidates from the leaves of all trees
+// closest to the query before measuring their distances with the metric. The forest does not follow later changes to the dataset.
+type RPForest struct {
+	data   []Vector
+	points [][]float64
+	metric Metric
+	opts   RPForestOptions
+	nodes  []rpNode
+	roots  []int
+}
+
+// rpNode splits its vectors by the sign of `normal · x - offset` into its left and right child, a leaf holds its vectors instead.
+type rpNode struct {
+	normal      []float64
+	offset      float64
+	left, right int
+	vectors     []int
+}
+
+// NewRPForest will build an RPForest over the vectors of the dataset with distances measured by the metric, choosing the hyperplanes with the source.
+func NewRPForest(dataset *Dataset, metric Metric, opts RPForestOptions, src rand.Source) *RPForest {
+	forest := &RPForest{data: dataset.data, points: make([][]float64, dataset.Count()), metric: metric, opts: opts.withDefaults()}
+	for i, vec := range dataset.data {
+		forest.points[i] = components(vec)
+	}
+	rng := rand.New(src)
+	for t := 0; t < forest.opts.Trees; t++ {
+		vectors := make([]int, len(forest.points))
+		for i := range vectors {
+			vectors[i] = i
+		}
+		forest.roots = append(forest.roots, forest.build(vectors, rng))
+	}
+	return forest
+}
+
+// build adds the node holding the vectors and its descendants, returning its position.
+func (forest *RPForest) build(vectors []int, rng *rand.Rand) int {
+	position := len(forest.nodes)
+	forest.nodes = append(forest.nodes, rpNode{left: -1, right: -1})
+	if len(vectors) <= forest.opts.LeafSize {
+		forest.nodes[position].vectors = vectors
+		return position
+	}
+	a, b := forest.points[vectors[rng.Intn(len(vectors))]], forest.points[vectors[rng.Intn(len(vectors))]]
+	normal, offset := make([]float64, len(a)), 0.0
+	for d := range normal {
+		normal[d] = a[d] - b[d]
+		offset += normal[d] * (a[d] + b[d]) / 2
+	}
+	var left, right []int
+	for _, i := range vectors {
+		if dotProduct(normal, forest.points[i]) > offset {
+			right = append(right, i)
+		} else {
+			left = append(left, i)
+		}
+	}
+	if len(left) == 0 || len(right) == 0 {
+		// The hyperplane does not separate the vectors, e.g., because they coincide, so they are split at random instead.
+		rng.Shuffle(len(vectors), func(x, y int) { vectors[x], vectors[y] = vectors[y], vectors[x] })
+		left, right = vectors[:len(vectors)/2], vectors[len(vectors)/2:]
+		normal = nil
+	}
+	forest.nodes[position].normal, forest.nodes[position].offset = normal, offset
+	leftChild := forest.build(left, rng)
+	rightChild := forest.build(right, rng)
+	forest.nodes[position].left, forest.nodes[position].right = leftChild, rightChild
+	return position
+}
+
+// candidates returns the vectors in the leaves closest to the query in all trees, visiting leaves in order of the smallest margin to a hyperplane on their path
+// until at least `count` vectors are found.
+func (forest *RPForest) candidates(query []float64, count int) []int {
+	// The queue holds the negated margins such that the node with the largest margin is visited first.
+	queue := &closestNeighbours{}
+	for _, root := range forest.roots {
+		heap.Push(queue, Neighbour{Index: root, Distance: math.Inf(-1)})
+	}
+	seen := make(map[int]bool)
+	var found []int
+	for queue.Len() > 0 && len(found) < count {
+		entry := heap.Pop(queue).(Neighbour)
+		node := forest.nodes[entry.Index]
+		if node.left < 0 {
+			for _, i := range node.vectors {
+				if !seen[i] {
+					seen[i] = true
+					found = append(found, i)
+				}
+			}
+			continue
+		}
+		margin := 0.0
+		if node.normal != nil {
+			margin = dotProduct(node.normal, query) - node.offset
+		}
+		heap.Push(queue, Neighbour{Index: node.right, Distance: math.Max(entry.Distance, -margin)})
+		heap.Push(queue, Neighbour{Index: node.left, Distance: math.Max(entry.Distance, margin)})
+	}
+	return found
+}
+
+func (forest *RPForest) search(query Vector, k, count int) []Neighbour {
+	nearest := &nearestNeighbours{k: k}
+	for _, i := range forest.candidates(components(query), count) {
+		nearest.offer(i, forest.metric(query, forest.data[i]))
+	}
+	return nearest.sorted()
+}
+
+// Nearest returns approximately the `k` vectors closest to the query in increasing order of distance, or all vectors if there are fewer.
+func (forest *RPForest) Nearest(query Vector, k int) []Neighbour {
+	if k <= 0 {
+		return nil
+	}
+	return forest.search(query, k, max(k, forest.opts.Candidates))
+}
+
+// WithinRadius returns approximately the vectors within distance `radius` of the query in increasing order of distance,
+// by widening the search until it finds a vector outside of the radius.
+func (forest *RPForest) WithinRadius(query Vector, radius float64) []Neighbour {
+	for count := forest.opts.Candidates; ; count *= 2 {
+		found := forest.search(query, count, count)
+		if len(found) < count || found[len(found)-1].Distance > radius {
+			within := 0
+			for within < len(found) && found[within].Distance <= radius {
+				within++
+			}
+			return found[:within]
+		}
+	}
+}
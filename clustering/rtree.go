@@ -0,0 +1,191 @@
+package clustering
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// rtreeFanout is the maximal number of children of a node of an RTree.
+const rtreeFanout = 16
+
+// Rect is an axis-aligned rectangle in the plane, e.g., the bounding box of a geographical feature or a map tile. A point is a rectangle without area.
+type Rect struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// Intersects reports whether both rectangles have at least one point in common.
+func (rect Rect) Intersects(other Rect) bool {
+	return rect.MinX <= other.MaxX && other.MinX <= rect.MaxX && rect.MinY <= other.MaxY && other.MinY <= rect.MaxY
+}
+
+// Distance returns the Euclidean distance between the vector of two dimensions and the closest point of the rectangle, 0 if the vector lies inside it.
+func (rect Rect) Distance(v Vector) float64 {
+	dx := math.Max(0, math.Max(rect.MinX-Component(v, 0), Component(v, 0)-rect.MaxX))
+	dy := math.Max(0, math.Max(rect.MinY-Component(v, 1), Component(v, 1)-rect.MaxY))
+	return math.Hypot(dx, dy)
+}
+
+func (rect Rect) union(other Rect) Rect {
+	return Rect{math.Min(rect.MinX, other.MinX), math.Min(rect.MinY, other.MinY), math.Max(rect.MaxX, other.MaxX), math.Max(rect.MaxY, other.MaxY)}
+}
+
+// RTree is an index over rectangles in the plane, bulk loaded with the sort-tile-recursive algorithm such that its nodes barely overlap,
+// answering which rectangles intersect a rectangle, e.g., to aggregate the features of a map tile. An RTree over the points of a dataset is a NeighbourIndex.
+type RTree struct {
+	rects []Rect
+	nodes []rtreeNode
+	root  int
+}
+
+// rtreeNode bounds its children, which are the indices of rectangles for a leaf and of nodes otherwise.
+type rtreeNode struct {
+	bounds   Rect
+	leaf     bool
+	children []int
+}
+
+// NewRTree will bulk load an RTree over the rectangles, queries return the indices of the rectangles.
+func NewRTree(rects []Rect) *RTree {
+	tree := &RTree{rects: rects, root: -1}
+	if len(rects) == 0 {
+		return tree
+	}
+	entries := make([]int, len(rects))
+	for i := range entries {
+		entries[i] = i
+	}
+	level := tree.pack(entries, true)
+	for len(level) > 1 {
+		level = tree.pack(level, false)
+	}
+	tree.root = level[0]
+	return tree
+}
+
+// NewPointRTree will bulk load an RTree over the vectors of the dataset, which must have two dimensions.
+func NewPointRTree(dataset *Dataset) *RTree {
+	rects := make([]Rect, dataset.Count())
+	for i, vec := range dataset.data {
+		if Dimension(vec) != 2 {
+			panic(fmt.Sprintf("Expected vectors of dimension 2 but got %d", Dimension(vec)))
+		}
+		x, y := Component(vec, 0), Component(vec, 1)
+		rects[i] = Rect{x, y, x, y}
+	}
+	return NewRTree(rects)
+}
+
+func (tree *RTree) bounds(entry int, leaf bool) Rect {
+	if leaf {
+		return tree.rects[entry]
+	}
+	return tree.nodes[entry].bounds
+}
+
+// pack groups the rectangles or nodes into nodes of rtreeFanout children by sorting them into vertical slices by their centre
+// and every slice from bottom to top, returning the new nodes.
+func (tree *RTree) pack(entries []int, leaf bool) []int {
+	centre := func(entry int, horizontal bool) float64 {
+		bounds := tree.bounds(entry, leaf)
+		if horizontal {
+			return bounds.MinX + bounds.MaxX
+		}
+		return bounds.MinY + bounds.MaxY
+	}
+	sort.Slice(entries, func(a, b int) bool { return centre(entries[a], true) < centre(entries[b], true) })
+	nodes := (len(entries) + rtreeFanout - 1) / rtreeFanout
+	sliceSize := int(math.Ceil(math.Sqrt(float64(nodes)))) * rtreeFanout
+	var packed []int
+	for from := 0; from < len(entries); from += sliceSize {
+		slice := entries[from:min(from+sliceSize, len(entries))]
+		sort.Slice(slice, func(a, b int) bool { return centre(slice[a], false) < centre(slice[b], false) })
+		for start := 0; start < len(slice); start += rtreeFanout {
+			node := rtreeNode{leaf: leaf, children: append([]int(nil), slice[start:min(start+rtreeFanout, len(slice))]...)}
+			node.bounds = tree.bounds(node.children[0], leaf)
+			for _, child := range node.children[1:] {
+				node.bounds = node.bounds.union(tree.bounds(child, leaf))
+			}
+			packed = append(packed, len(tree.nodes))
+			tree.nodes = append(tree.nodes, node)
+		}
+	}
+	return packed
+}
+
+// Search will return the indices of the rectangles intersecting the rectangle in increasing order.
+func (tree *RTree) Search(rect Rect) []int {
+	var found []int
+	if tree.root < 0 {
+		return found
+	}
+	for stack := []int{tree.root}; len(stack) > 0; {
+		node := tree.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		for _, child := range node.children {
+			if !tree.bounds(child, node.leaf).Intersects(rect) {
+				continue
+			}
+			if node.leaf {
+				found = append(found, child)
+			} else {
+				stack = append(stack, child)
+			}
+		}
+	}
+	sort.Ints(found)
+	return found
+}
+
+// Nearest returns the `k` rectangles closest to the query in increasing order of distance, or all rectangles if there are fewer.
+// Nodes are visited in order of their distance to the query, such that the rectangles are found in order as well.
+func (tree *RTree) Nearest(query Vector, k int) []Neighbour {
+	var nearest []Neighbour
+	if tree.root < 0 || k <= 0 {
+		return nearest
+	}
+	// Rectangles are queued with negative indices to tell them apart from nodes.
+	queue := &closestNeighbours{{Index: tree.root, Distance: tree.nodes[tree.root].bounds.Distance(query)}}
+	for queue.Len() > 0 && len(nearest) < k {
+		entry := heap.Pop(queue).(Neighbour)
+		if entry.Index < 0 {
+			nearest = append(nearest, Neighbour{Index: -entry.Index - 1, Distance: entry.Distance})
+			continue
+		}
+		node := tree.nodes[entry.Index]
+		for _, child := range node.children {
+			distance := tree.bounds(child, node.leaf).Distance(query)
+			if node.leaf {
+				child = -child - 1
+			}
+			heap.Push(queue, Neighbour{Index: child, Distance: distance})
+		}
+	}
+	return nearest
+}
+
+// WithinRadius returns the rectangles within distance `radius` of the query in increasing order of distance.
+func (tree *RTree) WithinRadius(query Vector, radius float64) []Neighbour {
+	var within []Neighbour
+	if tree.root < 0 || radius < 0 || math.IsNaN(radius) {
+		return within
+	}
+	for stack := []int{tree.root}; len(stack) > 0; {
+		node := tree.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		for _, child := range node.children {
+			distance := tree.bounds(child, node.leaf).Distance(query)
+			if distance > radius {
+				continue
+			}
+			if node.leaf {
+				within = append(within, Neighbour{Index: child, Distance: distance})
+			} else {
+				stack = append(stack, child)
+			}
+		}
+	}
+	sortNeighbours(within)
+	return within
+}
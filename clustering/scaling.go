@@ -0,0 +1,167 @@
+package clustering
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// MinMaxScaler is an InvertibleTransformer linearly mapping every component from the range it spans in the fitted dataset onto [0, 1].
+// Components that are constant in the fitted dataset are mapped onto 0.
+type MinMaxScaler struct {
+	// Min and Max hold the smallest and largest value of every component in the fitted dataset.
+	Min, Max []float64
+}
+
+// FitMinMaxScaler will create a MinMaxScaler recording the range of every component of the vectors in the dataset.
+func FitMinMaxScaler(dataset *Dataset) (MinMaxScaler, error) {
+	if dataset.IsEmpty() {
+		return MinMaxScaler{}, errors.New("Cannot fit a scaler on an empty dataset")
+	}
+	dimension := Dimension(dataset.data[0])
+	scaler := MinMaxScaler{Min: make([]float64, dimension), Max: make([]float64, dimension)}
+	for i := range scaler.Min {
+		scaler.Min[i], scaler.Max[i] = math.Inf(1), math.Inf(-1)
+	}
+	for _, vec := range dataset.data {
+		checkTransformDimension(vec, dimension)
+		for i := 0; i < dimension; i++ {
+			scaler.Min[i] = math.Min(scaler.Min[i], Component(vec, i))
+			scaler.Max[i] = math.Max(scaler.Max[i], Component(vec, i))
+		}
+	}
+	return scaler, nil
+}
+
+// Transform maps every component of the vector from its fitted range onto [0, 1], values outside of the fitted range end up outside of [0, 1].
+func (scaler MinMaxScaler) Transform(v Vector) Vector {
+	checkTransformDimension(v, len(scaler.Min))
+	return v.Creator().New(func(i int) float64 {
+		if span := scaler.Max[i] - scaler.Min[i]; span > 0 {
+			return (Component(v, i) - scaler.Min[i]) / span
+		}
+		return 0
+	})
+}
+
+// InverseTransform maps every component of the vector from [0, 1] back onto its fitted range.
+func (scaler MinMaxScaler) InverseTransform(v Vector) Vector {
+	checkTransformDimension(v, len(scaler.Min))
+	return v.Creator().New(func(i int) float64 {
+		return scaler.Min[i] + Component(v, i)*(scaler.Max[i]-scaler.Min[i])
+	})
+}
+
+// Standardizer is an InvertibleTransformer mapping every component onto its z-score, i.e., its distance to the mean in standard deviations, within the fitted dataset.
+// Components that are constant in the fitted dataset are only centered.
+type Standardizer struct {
+	// Mean and StdDev hold the mean and (population) standard deviation of every component in the fitted dataset.
+	Mean, StdDev []float64
+}
+
+// FitStandardizer will create a Standardizer recording the mean and standard deviation of every component of the vectors in the dataset.
+func FitStandardizer(dataset *Dataset) (Standardizer, error) {
+	if dataset.IsEmpty() {
+		return Standardizer{}, errors.New("Cannot fit a scaler on an empty dataset")
+	}
+	dimension := Dimension(dataset.data[0])
+	standardizer := Standardizer{Mean: make([]float64, dimension), StdDev: make([]float64, dimension)}
+	n := float64(dataset.Count())
+	for _, vec := range dataset.data {
+		checkTransformDimension(vec, dimension)
+		for i := 0; i < dimension; i++ {
+			standardizer.Mean[i] += Component(vec, i) / n
+		}
+	}
+	for _, vec := range dataset.data {
+		for i := 0; i < dimension; i++ {
+			diff := Component(vec, i) - standardizer.Mean[i]
+			standardizer.StdDev[i] += diff * diff / n
+		}
+	}
+	for i := range standardizer.StdDev {
+		standardizer.StdDev[i] = math.Sqrt(standardizer.StdDev[i])
+	}
+	return standardizer, nil
+}
+
+// Transform maps every component of the vector onto its z-score.
+func (standardizer Standardizer) Transform(v Vector) Vector {
+	checkTransformDimension(v, len(standardizer.Mean))
+	return v.Creator().New(func(i int) float64 {
+		return scale(Component(v, i)-standardizer.Mean[i], standardizer.StdDev[i])
+	})
+}
+
+// InverseTransform maps every z-score back onto the value of the original component.
+func (standardizer Standardizer) InverseTransform(v Vector) Vector {
+	checkTransformDimension(v, len(standardizer.Mean))
+	return v.Creator().New(func(i int) float64 {
+		return standardizer.Mean[i] + Component(v, i)*unitIfZero(standardizer.StdDev[i])
+	})
+}
+
+// scale divides the value by the scale, unless the scale is zero.
+func scale(value, scale float64) float64 {
+	return value / unitIfZero(scale)
+}
+
+func unitIfZero(value float64) float64 {
+	if value == 0 {
+		return 1
+	}
+	return value
+}
+
+// RobustScaler is an InvertibleTransformer centering every component on its median and scaling it by its interquartile range within the fitted dataset,
+// such that outliers have little influence on the scale. Components with an interquartile range of 0 are only centered.
+type RobustScaler struct {
+	// Median and IQR hold the median and interquartile range of every component in the fitted dataset.
+	Median, IQR []float64
+}
+
+// FitRobustScaler will create a RobustScaler recording the median and interquartile range of every component of the vectors in the dataset.
+func FitRobustScaler(dataset *Dataset) (RobustScaler, error) {
+	if dataset.IsEmpty() {
+		return RobustScaler{}, errors.New("Cannot fit a scaler on an empty dataset")
+	}
+	dimension := Dimension(dataset.data[0])
+	scaler := RobustScaler{Median: make([]float64, dimension), IQR: make([]float64, dimension)}
+	values := make([]float64, dataset.Count())
+	for i := 0; i < dimension; i++ {
+		for j, vec := range dataset.data {
+			checkTransformDimension(vec, dimension)
+			values[j] = Component(vec, i)
+		}
+		sort.Float64s(values)
+		scaler.Median[i] = quantile(values, 0.5)
+		scaler.IQR[i] = quantile(values, 0.75) - quantile(values, 0.25)
+	}
+	return scaler, nil
+}
+
+// Transform centers every component of the vector on its median and divides it by its interquartile range.
+func (scaler RobustScaler) Transform(v Vector) Vector {
+	checkTransformDimension(v, len(scaler.Median))
+	return v.Creator().New(func(i int) float64 {
+		return scale(Component(v, i)-scaler.Median[i], scaler.IQR[i])
+	})
+}
+
+// InverseTransform maps every scaled component back onto the value of the original component.
+func (scaler RobustScaler) InverseTransform(v Vector) Vector {
+	checkTransformDimension(v, len(scaler.Median))
+	return v.Creator().New(func(i int) float64 {
+		return scaler.Median[i] + Component(v, i)*unitIfZero(scaler.IQR[i])
+	})
+}
+
+// quantile returns the `q`th quantile of the sorted values, interpolating linearly between the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (position-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
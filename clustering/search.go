@@ -0,0 +1,139 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Parameters holds the hyperparameters of a single trial by name, e.g., `"k"`, `"eps"`, `"minPoints"` or `"metric"`.
+type Parameters map[string]interface{}
+
+// ParameterGrid holds the candidate values of every hyperparameter by name.
+type ParameterGrid map[string][]interface{}
+
+// Trial clusters the dataset with the provided hyperparameters, returning the cluster of every vector, e.g., the Assignments of a fitted clusterer or the result of DBSCAN.
+// Trials run in parallel, so a trial must be safe for concurrent use.
+type Trial func(dataset *Dataset, params Parameters) ([]Cluster, error)
+
+// Scorer scores the clustering of a dataset in which the `i`th vector is assigned to `assignments[i]`, higher scores are better.
+type Scorer func(dataset *Dataset, assignments []Cluster) (float64, error)
+
+// SearchResult is the outcome of a single trial of a hyperparameter search.
+type SearchResult struct {
+	// Parameters holds the hyperparameters of the trial.
+	Parameters Parameters
+	// Score is the score of the resulting clustering, it is NaN if the trial or scoring failed.
+	Score float64
+	// Err holds the error of the trial or scoring, if any.
+	Err error
+}
+
+// SilhouetteScorer will return a Scorer computing the mean Silhouette of the clustering with the metric, which must be safe for concurrent use.
+func SilhouetteScorer(metric Metric) Scorer {
+	return func(dataset *Dataset, assignments []Cluster) (float64, error) {
+		values, err := silhouetteValues(dataset.data, assignments, metric)
+		if err != nil {
+			return 0, err
+		}
+		return sum(values) / float64(len(values)), nil
+	}
+}
+
+// CalinskiHarabaszScorer is a Scorer computing the CalinskiHarabasz score of the clustering.
+func CalinskiHarabaszScorer(dataset *Dataset, assignments []Cluster) (float64, error) {
+	return calinskiHarabasz(dataset, assignments)
+}
+
+// AdjustedRandScorer will return a Scorer computing the AdjustedRandIndex of the clustering against the ground-truth labels.
+func AdjustedRandScorer(labels []Cluster) Scorer {
+	return func(_ *Dataset, assignments []Cluster) (float64, error) {
+		return AdjustedRandIndex(labels, assignments)
+	}
+}
+
+// GridSearch will run a trial for every combination of the candidate hyperparameters in parallel and score the resulting clusterings,
+// returning the results ranked from the highest to the lowest score followed by the failed trials.
+func GridSearch(dataset *Dataset, grid ParameterGrid, trial Trial, score Scorer) ([]SearchResult, error) {
+	names, err := gridNames(grid)
+	if err != nil {
+		return nil, err
+	}
+	combinations := []Parameters{{}}
+	for _, name := range names {
+		var extended []Parameters
+		for _, combination := range combinations {
+			for _, value := range grid[name] {
+				params := make(Parameters, len(combination)+1)
+				for key, existing := range combination {
+					params[key] = existing
+				}
+				params[name] = value
+				extended = append(extended, params)
+			}
+		}
+		combinations = extended
+	}
+	return runTrials(dataset, combinations, trial, score), nil
+}
+
+// RandomSearch will run `trials` trials in parallel, every one with each hyperparameter drawn uniformly from its candidates, and score the resulting clusterings,
+// returning the results ranked from the highest to the lowest score followed by the failed trials.
+func RandomSearch(dataset *Dataset, grid ParameterGrid, trials int, trial Trial, score Scorer, src rand.Source) ([]SearchResult, error) {
+	if trials <= 0 {
+		return nil, fmt.Errorf("Expected a positive number of trials but got %d", trials)
+	}
+	names, err := gridNames(grid)
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(src)
+	combinations := make([]Parameters, trials)
+	for i := range combinations {
+		combinations[i] = make(Parameters, len(names))
+		for _, name := range names {
+			combinations[i][name] = grid[name][rng.Intn(len(grid[name]))]
+		}
+	}
+	return runTrials(dataset, combinations, trial, score), nil
+}
+
+// gridNames returns the names of the hyperparameters of the grid in sorted order, such that searches are reproducible.
+func gridNames(grid ParameterGrid) ([]string, error) {
+	if len(grid) == 0 {
+		return nil, errors.New("Expected at least one hyperparameter to search")
+	}
+	names := make([]string, 0, len(grid))
+	for name, values := range grid {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("Expected at least one candidate value for hyperparameter %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func runTrials(dataset *Dataset, combinations []Parameters, trial Trial, score Scorer) []SearchResult {
+	results := make([]SearchResult, len(combinations))
+	parallelFor(len(combinations), func(i int) {
+		results[i] = SearchResult{Parameters: combinations[i], Score: math.NaN()}
+		assignments, err := trial(dataset, combinations[i])
+		if err == nil {
+			var value float64
+			if value, err = score(dataset, assignments); err == nil {
+				results[i].Score = value
+			}
+		}
+		results[i].Err = err
+	})
+	sort.SliceStable(results, func(a, b int) bool {
+		if math.IsNaN(results[b].Score) {
+			return !math.IsNaN(results[a].Score)
+		}
+		return results[a].Score > results[b].Score
+	})
+	return results
+}
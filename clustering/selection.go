@@ -0,0 +1,317 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// KClustererFitter fits a clusterer with `k` clusters on a dataset, drawing any randomness it needs from the source, e.g., FitKMeans.
+type KClustererFitter func(dataset *Dataset, k int, src rand.Source) (SimpleFlatClusterer, error)
+
+// FitKMeans will perform K-Means clustering with `k` clusters on the dataset seeded by k-means++ with the source, it is the KClustererFitter of KMeansPlusPlus.
+func FitKMeans(dataset *Dataset, k int, src rand.Source) (SimpleFlatClusterer, error) {
+	clusterer := dataset.KMeansPlusPlus(k, src)
+	return &clusterer, nil
+}
+
+// GapResult holds the gap statistic of every number of clusters that was considered.
+type GapResult struct {
+	// Ks holds the considered numbers of clusters in increasing order.
+	// A number of clusters that leaves no Inertia on the dataset or on a reference dataset, e.g., because there are no more distinct vectors than clusters, is skipped.
+	Ks []int
+	// Gaps holds the gap statistic of every number of clusters in Ks.
+	Gaps []float64
+	// StdErrs holds the standard error of every gap statistic, accounting for the simulation error of the reference datasets.
+	StdErrs []float64
+	// BestK is the smallest `k` whose gap is at least the gap of the next `k` minus its standard error, or the largest `k` if there is none.
+	BestK int
+}
+
+// GapStatistic will compute the gap statistic (Tibshirani, Walther and Hastie) for every number of clusters from `kMin` up to and including `kMax`:
+// the difference between the mean logarithm of the Inertia of `references` datasets sampled uniformly over the bounding box of the dataset and the logarithm of the Inertia of the dataset itself,
+// when clustered with the fitter. The recommended number of clusters is the one after which the gap stops increasing significantly.
+// The reference datasets are sampled and every fit is seeded with the source.
+func GapStatistic(dataset *Dataset, kMin, kMax, references int, fit KClustererFitter, src rand.Source) (GapResult, error) {
+	return gapStatistic(dataset, kMin, kMax, references, fit, src, nil)
+}
+
+// gapStatistic computes the GapStatistic, using the Inertia of the clusterers, if any, which cluster the dataset into every number of clusters from `kMin` on,
+// instead of fitting the dataset again, such that the gaps score these clusterers.
+func gapStatistic(dataset *Dataset, kMin, kMax, references int, fit KClustererFitter, src rand.Source, clusterers []SimpleFlatClusterer) (GapResult, error) {
+	if kMin <= 0 || kMax < kMin {
+		return GapResult{}, fmt.Errorf("Expected a range of positive numbers of clusters but got %d up to %d", kMin, kMax)
+	}
+	if references <= 0 {
+		return GapResult{}, fmt.Errorf("Expected a positive number of reference datasets but got %d", references)
+	}
+	bounds, err := FitMinMaxScaler(dataset)
+	if err != nil {
+		return GapResult{}, err
+	}
+	rng := rand.New(src)
+	creator := dataset.data[0].Creator()
+	referenceSets := make([]Dataset, references)
+	for b := range referenceSets {
+		data := make([]Vector, dataset.Count())
+		for i := range data {
+			data[i] = creator.New(func(d int) float64 {
+				return bounds.Min[d] + rng.Float64()*(bounds.Max[d]-bounds.Min[d])
+			})
+		}
+		referenceSets[b] = CreateDataset(data, creator)
+	}
+
+	var result GapResult
+considered:
+	for k := kMin; k <= kMax; k++ {
+		var inertia float64
+		if clusterers != nil {
+			inertia, err = Inertia(dataset, clusterers[k-kMin])
+		} else {
+			inertia, err = fittedInertia(dataset, k, fit, rand.NewSource(rng.Int63()))
+		}
+		if err != nil {
+			return GapResult{}, err
+		}
+		// The logarithm of no Inertia is infinite, which leaves no meaningful gap.
+		if !(inertia > 0) {
+			continue
+		}
+		logs := make([]float64, references)
+		for b := range referenceSets {
+			reference, err := fittedInertia(&referenceSets[b], k, fit, rand.NewSource(rng.Int63()))
+			if err != nil {
+				return GapResult{}, err
+			}
+			if !(reference > 0) {
+				continue considered
+			}
+			logs[b] = math.Log(reference)
+		}
+		mean := sum(logs) / float64(references)
+		deviation := 0.0
+		for _, log := range logs {
+			deviation += (log - mean) * (log - mean) / float64(references)
+		}
+		result.Ks = append(result.Ks, k)
+		result.Gaps = append(result.Gaps, mean-math.Log(inertia))
+		result.StdErrs = append(result.StdErrs, math.Sqrt(deviation)*math.Sqrt(1+1/float64(references)))
+	}
+	if len(result.Ks) == 0 {
+		return GapResult{}, fmt.Errorf("Every number of clusters from %d up to %d leaves no Inertia", kMin, kMax)
+	}
+	result.BestK = result.Ks[len(result.Ks)-1]
+	for i := 0; i+1 < len(result.Ks); i++ {
+		if result.Gaps[i] >= result.Gaps[i+1]-result.StdErrs[i+1] {
+			result.BestK = result.Ks[i]
+			break
+		}
+	}
+	return result, nil
+}
+
+// fittedInertia fits a clusterer with `k` clusters on the dataset seeded with the source and returns its Inertia.
+func fittedInertia(dataset *Dataset, k int, fit KClustererFitter, src rand.Source) (float64, error) {
+	clusterer, err := fit(dataset, k, src)
+	if err != nil {
+		return 0, err
+	}
+	return Inertia(dataset, clusterer)
+}
+
+// ElbowResult holds the Inertia of every number of clusters that was considered, e.g., to plot the elbow curve.
+type ElbowResult struct {
+	// Ks holds the considered numbers of clusters in increasing order.
+	Ks []int
+	// Inertias holds the Inertia of the clustering with every number of clusters in Ks.
+	Inertias []float64
+	// BestK is the number of clusters at the knee of the curve.
+	BestK int
+}
+
+// Elbow will cluster the dataset with the fitter for every number of clusters from `kMin` up to and including `kMax` and locate the elbow of the resulting Inertia curve
+// with the Kneedle algorithm: after normalizing both axes to the unit interval, the knee is the point furthest below the line connecting the ends of the curve.
+// Every fit is seeded with the source.
+func Elbow(dataset *Dataset, kMin, kMax int, fit KClustererFitter, src rand.Source) (ElbowResult, error) {
+	if kMin <= 0 || kMax < kMin+2 {
+		return ElbowResult{}, fmt.Errorf("Expected a range of at least three positive numbers of clusters but got %d up to %d", kMin, kMax)
+	}
+	result, rng := ElbowResult{BestK: kMin}, rand.New(src)
+	for k := kMin; k <= kMax; k++ {
+		inertia, err := fittedInertia(dataset, k, fit, rand.NewSource(rng.Int63()))
+		if err != nil {
+			return ElbowResult{}, err
+		}
+		result.Ks, result.Inertias = append(result.Ks, k), append(result.Inertias, inertia)
+	}
+
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, inertia := range result.Inertias {
+		lowest, highest = math.Min(lowest, inertia), math.Max(highest, inertia)
+	}
+	if highest == lowest {
+		return result, nil
+	}
+	best := 0.0
+	for i, k := range result.Ks {
+		x := float64(k-kMin) / float64(kMax-kMin)
+		y := (result.Inertias[i] - lowest) / (highest - lowest)
+		if difference := 1 - x - y; difference > best {
+			best, result.BestK = difference, k
+		}
+	}
+	return result, nil
+}
+
+// InformationCriterion selects how SelectGMM compares mixtures.
+type InformationCriterion int
+
+const (
+	// BayesianInformationCriterion compares mixtures by their BIC, which penalizes complexity more for large datasets.
+	BayesianInformationCriterion InformationCriterion = iota
+	// AkaikeInformationCriterion compares mixtures by their AIC.
+	AkaikeInformationCriterion
+)
+
+// SelectGMM will fit a GaussianMixture on the dataset for every number of components from `kMin` up to and including `kMax` and every covariance type,
+// all covariance types if none are provided, and return the mixture with the lowest information criterion. The covariance type of the options is ignored.
+func SelectGMM(dataset *Dataset, kMin, kMax int, covariances []CovarianceType, criterion InformationCriterion, opts GMMOptions, src rand.Source) (GaussianMixture, error) {
+	if kMin <= 0 || kMax < kMin {
+		return GaussianMixture{}, fmt.Errorf("Expected a range of positive numbers of components but got %d up to %d", kMin, kMax)
+	}
+	if len(covariances) == 0 {
+		covariances = []CovarianceType{FullCovariance, DiagonalCovariance, SphericalCovariance}
+	}
+	var best GaussianMixture
+	bestScore := math.Inf(1)
+	for k := kMin; k <= kMax; k++ {
+		for _, covariance := range covariances {
+			opts.Covariance = covariance
+			mixture, err := dataset.GMM(k, opts, src)
+			if err != nil {
+				return GaussianMixture{}, err
+			}
+			score, err := mixture.BIC(dataset)
+			if criterion == AkaikeInformationCriterion {
+				score, err = mixture.AIC(dataset)
+			}
+			if err != nil {
+				return GaussianMixture{}, err
+			}
+			if score < bestScore {
+				best, bestScore = mixture, score
+			}
+		}
+	}
+	return best, nil
+}
+
+// KCriterion selects how ChooseK compares clusterings with different numbers of clusters.
+type KCriterion int
+
+const (
+	// SilhouetteCriterion prefers the clustering with the highest mean Silhouette using Euclidean distances.
+	SilhouetteCriterion KCriterion = iota
+	// CalinskiHarabaszCriterion prefers the clustering with the highest CalinskiHarabasz score.
+	CalinskiHarabaszCriterion
+	// GapCriterion prefers the number of clusters the GapStatistic of the fitted clusterers recommends with 10 reference datasets.
+	GapCriterion
+)
+
+// ChooseK will fit a clusterer for every number of clusters from `kMin` up to and including `kMax` in parallel and return the best clusterer according to the criterion,
+// together with its number of clusters. A nil fitter uses FitKMeans, any other fitter must be safe for concurrent use. Every fit is seeded with the source.
+// The silhouette and Calinski-Harabasz criteria require at least two clusters.
+func ChooseK(dataset *Dataset, kMin, kMax int, criterion KCriterion, fit KClustererFitter, src rand.Source) (SimpleFlatClusterer, int, error) {
+	if kMin <= 0 || kMax < kMin {
+		return nil, 0, fmt.Errorf("Expected a range of positive numbers of clusters but got %d up to %d", kMin, kMax)
+	}
+	if criterion != GapCriterion && kMin < 2 {
+		return nil, 0, fmt.Errorf("Expected at least two clusters for this criterion but got %d", kMin)
+	}
+	if fit == nil {
+		fit = FitKMeans
+	}
+	rng := rand.New(src)
+	clusterers, scores, errs := make([]SimpleFlatClusterer, kMax-kMin+1), make([]float64, kMax-kMin+1), make([]error, kMax-kMin+1)
+	// The sources are drawn up front, such that the fits do not depend on the order they run in.
+	sources := make([]rand.Source, len(clusterers))
+	for i := range sources {
+		sources[i] = rand.NewSource(rng.Int63())
+	}
+	parallelFor(len(clusterers), func(i int) {
+		if clusterers[i], errs[i] = fit(dataset, kMin+i, sources[i]); errs[i] != nil {
+			return
+		}
+		switch criterion {
+		case SilhouetteCriterion:
+			scores[i], _, errs[i] = Silhouette(dataset, clusterers[i], Euclidean)
+		case CalinskiHarabaszCriterion:
+			scores[i], errs[i] = CalinskiHarabasz(dataset, clusterers[i])
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	best := 0
+	if criterion == GapCriterion {
+		gap, err := gapStatistic(dataset, kMin, kMax, 10, fit, rand.NewSource(rng.Int63()), clusterers)
+		if err != nil {
+			return nil, 0, err
+		}
+		best = gap.BestK - kMin
+	} else {
+		for i, score := range scores {
+			if score > scores[best] {
+				best = i
+			}
+		}
+	}
+	return clusterers[best], kMin + best, nil
+}
+
+// PredictionStrength will compute the prediction strength (Tibshirani and Walther) of clustering the dataset into `k` clusters with the fitter:
+// the dataset is split randomly into halves which are clustered separately, and for every cluster of the test half the fraction of its pairs of vectors
+// that the clusterer of the training half also puts together is measured. The prediction strength is the smallest such fraction,
+// values above 0.8 or 0.9 indicate that `k` clusters are a stable description of the data.
+func PredictionStrength(dataset *Dataset, k int, fit KClustererFitter, src rand.Source) (float64, error) {
+	training, test := dataset.Split(0.5, src)
+	if k <= 0 || k > test.Count() {
+		return 0, fmt.Errorf("Expected between 1 and %d clusters but got %d", test.Count(), k)
+	}
+	trainingClusterer, err := fit(&training, k, src)
+	if err != nil {
+		return 0, err
+	}
+	testClusterer, err := fit(&test, k, src)
+	if err != nil {
+		return 0, err
+	}
+	predicted, err := Assignments(&test, trainingClusterer)
+	if err != nil {
+		return 0, err
+	}
+	actual, err := Assignments(&test, testClusterer)
+	if err != nil {
+		return 0, err
+	}
+	strength := 1.0
+	for _, indices := range PartitionIndices(actual) {
+		if len(indices) < 2 {
+			continue
+		}
+		together := 0
+		for a := range indices {
+			for b := a + 1; b < len(indices); b++ {
+				if predicted[indices[a]] == predicted[indices[b]] {
+					together++
+				}
+			}
+		}
+		strength = math.Min(strength, float64(together)/choose2(len(indices)))
+	}
+	return strength, nil
+}
@@ -0,0 +1,104 @@
+package clustering
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// separatedBlobs returns `k` tight clusters of `n` vectors each, far apart compared to their spread.
+func separatedBlobs(k, n int, rng *rand.Rand) *Dataset {
+	var dataset Dataset
+	for c := 0; c < k; c++ {
+		for i := 0; i < n; i++ {
+			dataset.Append(VectorN{float64(10*c) + rng.NormFloat64()*0.3, float64(10*(c%2)) + rng.NormFloat64()*0.3})
+		}
+	}
+	return &dataset
+}
+
+func TestGapStatisticFindsSeparatedClusters(t *testing.T) {
+	dataset := separatedBlobs(3, 40, rand.New(rand.NewSource(1)))
+	result, err := GapStatistic(dataset, 1, 6, 10, FitKMeans, rand.NewSource(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BestK != 3 {
+		t.Errorf("Expected 3 clusters but the gap statistic recommends %d, gaps %v", result.BestK, result.Gaps)
+	}
+	for i, gap := range result.Gaps {
+		if math.IsNaN(gap) || math.IsInf(gap, 0) || math.IsNaN(result.StdErrs[i]) {
+			t.Errorf("Expected a finite gap for %d clusters but got %v ± %v", result.Ks[i], gap, result.StdErrs[i])
+		}
+	}
+	again, err := GapStatistic(dataset, 1, 6, 10, FitKMeans, rand.NewSource(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range result.Gaps {
+		if again.Gaps[i] != result.Gaps[i] {
+			t.Fatalf("Expected the same gaps from the same source but got %v and %v", result.Gaps, again.Gaps)
+		}
+	}
+}
+
+func TestGapStatisticSkipsClusteringsWithoutInertia(t *testing.T) {
+	var dataset Dataset
+	for i := 0; i < 20; i++ {
+		dataset.Append(VectorN{float64(i % 3), float64(i % 3 * 5)})
+	}
+	result, err := GapStatistic(&dataset, 1, 6, 5, FitKMeans, rand.NewSource(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, k := range result.Ks {
+		if k >= 3 {
+			t.Errorf("Expected only fewer than 3 clusters of 3 distinct vectors to be considered but got %d", k)
+		}
+		if math.IsNaN(result.Gaps[i]) || math.IsInf(result.Gaps[i], 0) {
+			t.Errorf("Expected a finite gap for %d clusters but got %v", k, result.Gaps[i])
+		}
+	}
+	if len(result.Ks) == 0 || result.BestK < result.Ks[0] || result.BestK > result.Ks[len(result.Ks)-1] {
+		t.Errorf("Expected one of the considered numbers of clusters %v but got %d", result.Ks, result.BestK)
+	}
+
+	var constant Dataset
+	for i := 0; i < 10; i++ {
+		constant.Append(VectorN{1, 1})
+	}
+	if _, err := GapStatistic(&constant, 1, 3, 5, FitKMeans, rand.NewSource(1)); err == nil {
+		t.Error("Expected an error for a dataset of identical vectors")
+	}
+}
+
+func TestChooseKReturnsTheScoredFit(t *testing.T) {
+	dataset := separatedBlobs(3, 30, rand.New(rand.NewSource(1)))
+	for _, criterion := range []KCriterion{SilhouetteCriterion, CalinskiHarabaszCriterion, GapCriterion} {
+		var mutex sync.Mutex
+		fitted := make(map[SimpleFlatClusterer]bool)
+		fit := func(fitting *Dataset, k int, src rand.Source) (SimpleFlatClusterer, error) {
+			clusterer, err := FitKMeans(fitting, k, src)
+			if fitting == dataset {
+				mutex.Lock()
+				defer mutex.Unlock()
+				fitted[clusterer] = true
+			}
+			return clusterer, err
+		}
+		clusterer, k, err := ChooseK(dataset, 2, 5, criterion, fit, rand.NewSource(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != 3 || len(clusterer.Clusters()) != 3 {
+			t.Errorf("Criterion %d chose %d clusters with %d clusters, expected 3", criterion, k, len(clusterer.Clusters()))
+		}
+		if len(fitted) != 4 {
+			t.Errorf("Criterion %d fitted the dataset %d times, expected once for every number of clusters", criterion, len(fitted))
+		}
+		if !fitted[clusterer] {
+			t.Errorf("Criterion %d returned a clusterer it did not fit", criterion)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package clustering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NDArray is a dense array of numbers of any shape, stored in row-major order, e.g., an attribute of a fitted scikit-learn model saved with `numpy.save`.
+type NDArray struct {
+	// Shape holds the size of every axis.
+	Shape []int
+	// Data holds the elements in row-major order.
+	Data []float64
+}
+
+// ReadNPY will read an array stored in the NumPy .npy format, whose elements must be little-endian floats or integers.
+// Arrays stored in Fortran order are converted to row-major order.
+func ReadNPY(r io.Reader) (NDArray, error) {
+	var preamble [8]byte
+	if _, err := io.ReadFull(r, preamble[:]); err != nil {
+		return NDArray{}, err
+	}
+	if string(preamble[:6]) != "\x93NUMPY" {
+		return NDArray{}, errors.New("Expected a file starting with the NumPy magic string")
+	}
+	var length int
+	switch preamble[6] {
+	case 1:
+		var size [2]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return NDArray{}, err
+		}
+		length = int(binary.LittleEndian.Uint16(size[:]))
+	case 2, 3:
+		var size [4]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return NDArray{}, err
+		}
+		length = int(binary.LittleEndian.Uint32(size[:]))
+	default:
+		return NDArray{}, fmt.Errorf("Unsupported version %d of the NumPy format", preamble[6])
+	}
+	if length > 1<<20 {
+		return NDArray{}, fmt.Errorf("The NumPy header of %d bytes is too large", length)
+	}
+	header := make([]byte, length)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return NDArray{}, err
+	}
+	descr, fortran, shape, err := parseNPYHeader(string(header))
+	if err != nil {
+		return NDArray{}, err
+	}
+
+	count := 1
+	for _, dim := range shape {
+		if dim > 1<<31 || count*dim > 1<<31 {
+			return NDArray{}, fmt.Errorf("The array of shape %v is too large", shape)
+		}
+		count *= dim
+	}
+	var size int
+	var decode func([]byte) float64
+	switch strings.TrimLeft(descr, "<|") {
+	case "f8":
+		size, decode = 8, func(b []byte) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b)) }
+	case "f4":
+		size, decode = 4, func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }
+	case "i8":
+		size, decode = 8, func(b []byte) float64 { return float64(int64(binary.LittleEndian.Uint64(b))) }
+	case "i4":
+		size, decode = 4, func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) }
+	default:
+		return NDArray{}, fmt.Errorf("Unsupported NumPy element type %q, expected little-endian floats or integers", descr)
+	}
+	// The elements are read in chunks, such that a header claiming more elements than the input holds fails at the end of the input instead of allocating them all up front.
+	const chunk = 1 << 16
+	array := NDArray{Shape: shape, Data: make([]float64, 0, min(count, chunk))}
+	buffer := make([]byte, size*min(count, chunk))
+	for len(array.Data) < count {
+		elements := buffer[:size*min(count-len(array.Data), chunk)]
+		if _, err := io.ReadFull(r, elements); err != nil {
+			return NDArray{}, err
+		}
+		for i := 0; i < len(elements); i += size {
+			array.Data = append(array.Data, decode(elements[i:]))
+		}
+	}
+	if fortran {
+		array = array.transposed()
+	}
+	return array, nil
+}
+
+// parseNPYHeader extracts the element type, order and shape from the Python dictionary literal of a NumPy header,
+// e.g., `{'descr': '<f8', 'fortran_order': False, 'shape': (3, 2), }`.
+func parseNPYHeader(header string) (string, bool, []int, error) {
+	value := func(key string) (string, error) {
+		start := strings.Index(header, "'"+key+"':")
+		if start < 0 {
+			return "", fmt.Errorf("The NumPy header has no %q", key)
+		}
+		return strings.TrimSpace(header[start+len(key)+3:]), nil
+	}
+	descr, err := value("descr")
+	if err != nil {
+		return "", false, nil, err
+	}
+	if end := strings.IndexByte(descr[min(1, len(descr)):], '\''); strings.HasPrefix(descr, "'") && end >= 0 {
+		descr = descr[1 : end+1]
+	}
+	order, err := value("fortran_order")
+	if err != nil {
+		return "", false, nil, err
+	}
+	shapes, err := value("shape")
+	if err != nil {
+		return "", false, nil, err
+	}
+	end := strings.IndexByte(shapes, ')')
+	if !strings.HasPrefix(shapes, "(") || end < 0 {
+		return "", false, nil, fmt.Errorf("Invalid shape in NumPy header %q", header)
+	}
+	var shape []int
+	for _, dim := range strings.Split(shapes[1:end], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		n, err := strconv.Atoi(dim)
+		if err != nil || n < 0 {
+			return "", false, nil, fmt.Errorf("Invalid shape in NumPy header %q", header)
+		}
+		shape = append(shape, n)
+	}
+	return descr, strings.HasPrefix(order, "True"), shape, nil
+}
+
+// transposed converts an array stored in column-major order into row-major order.
+func (array NDArray) transposed() NDArray {
+	result := NDArray{Shape: array.Shape, Data: make([]float64, len(array.Data))}
+	index := make([]int, len(array.Shape))
+	for i := range array.Data {
+		// `index` is the multi-index of the `i`th element in row-major order, the first axis varies fastest in column-major order.
+		offset, stride := 0, 1
+		for axis, value := range index {
+			offset += value * stride
+			stride *= array.Shape[axis]
+		}
+		result.Data[i] = array.Data[offset]
+		for axis := len(index) - 1; axis >= 0; axis-- {
+			if index[axis]++; index[axis] < array.Shape[axis] {
+				break
+			}
+			index[axis] = 0
+		}
+	}
+	return result
+}
+
+// check returns an error if the array does not hold as many elements as its shape.
+func (array NDArray) check(what string) error {
+	count := 1
+	for _, dim := range array.Shape {
+		if dim < 0 {
+			return fmt.Errorf("Expected %s to have a non-negative shape but got %v", what, array.Shape)
+		}
+		if dim > 0 && count > math.MaxInt/dim {
+			return fmt.Errorf("Expected %s of shape %v to hold %d elements", what, array.Shape, len(array.Data))
+		}
+		count *= dim
+	}
+	if count != len(array.Data) {
+		return fmt.Errorf("Expected %s of shape %v to hold %d elements but got %d", what, array.Shape, count, len(array.Data))
+	}
+	return nil
+}
+
+// rows returns the rows of a two-dimensional array.
+func (array NDArray) rows(what string) ([][]float64, error) {
+	if err := array.check(what); err != nil {
+		return nil, err
+	}
+	if len(array.Shape) != 2 {
+		return nil, fmt.Errorf("Expected %s to be a two-dimensional array but got shape %v", what, array.Shape)
+	}
+	if array.Shape[0] > 0 && array.Shape[1] == 0 {
+		return nil, fmt.Errorf("Expected the rows of %s to hold elements but got shape %v", what, array.Shape)
+	}
+	rows := make([][]float64, array.Shape[0])
+	for i := range rows {
+		rows[i] = array.Data[i*array.Shape[1] : (i+1)*array.Shape[1]]
+	}
+	return rows, nil
+}
+
+// SKLearnCentroids will create a CentroidClusterer from the `cluster_centers_` of a fitted scikit-learn KMeans or MiniBatchKMeans,
+// which assigns every vector to the same cluster as the `predict` method of the scikit-learn model. The centroids are VectorNs.
+func SKLearnCentroids(centers NDArray) (CentroidClusterer, error) {
+	rows, err := centers.rows("cluster_centers_")
+	if err != nil {
+		return nil, err
+	}
+	dimension := 0
+	if len(rows) > 0 {
+		dimension = len(rows[0])
+	}
+	return decodeVectors(rows, dimension, vectorNCreator(dimension))
+}
+
+// SKLearnGMM will create a GaussianMixture from the `weights_`, `means_` and `covariances_` of a fitted scikit-learn GaussianMixture with the covariance type,
+// i.e., `full`, `tied`, `diag` or `spherical`. Tied covariances become a full covariance matrix shared by all components. The means are VectorNs.
+func SKLearnGMM(covarianceType string, weights, means, covariances NDArray) (GaussianMixture, error) {
+	rows, err := means.rows("means_")
+	if err != nil {
+		return GaussianMixture{}, err
+	}
+	if err := weights.check("weights_"); err != nil {
+		return GaussianMixture{}, err
+	}
+	if err := covariances.check("covariances_"); err != nil {
+		return GaussianMixture{}, err
+	}
+	if len(weights.Shape) != 1 {
+		return GaussianMixture{}, fmt.Errorf("Expected weights_ to be a one-dimensional array but got shape %v", weights.Shape)
+	}
+	k, d := means.Shape[0], means.Shape[1]
+	encoded := jsonModel{Model: "gaussian-mixture", Vector: "dense", Dimension: d, Weights: weights.Data, Means: rows, Covariance: "full"}
+	shapes := map[string][]int{"full": {k, d, d}, "tied": {d, d}, "diag": {k, d}, "spherical": {k}}
+	shape, ok := shapes[covarianceType]
+	if !ok {
+		return GaussianMixture{}, fmt.Errorf("Unknown scikit-learn covariance type %q", covarianceType)
+	}
+	if fmt.Sprint(covariances.Shape) != fmt.Sprint(shape) {
+		return GaussianMixture{}, fmt.Errorf("Expected %s covariances_ of shape %v but got shape %v", covarianceType, shape, covariances.Shape)
+	}
+	encoded.Covariances = make([][][]float64, k)
+	for c := range encoded.Covariances {
+		covariance := make([][]float64, d)
+		for i := range covariance {
+			covariance[i] = make([]float64, d)
+			switch covarianceType {
+			case "full":
+				copy(covariance[i], covariances.Data[(c*d+i)*d:])
+			case "tied":
+				copy(covariance[i], covariances.Data[i*d:])
+			case "diag":
+				covariance[i][i] = covariances.Data[c*d+i]
+			case "spherical":
+				covariance[i][i] = covariances.Data[c]
+			}
+		}
+		encoded.Covariances[c] = covariance
+	}
+	switch covarianceType {
+	case "diag":
+		encoded.Covariance = "diagonal"
+	case "spherical":
+		encoded.Covariance = "spherical"
+	}
+	var mixture GaussianMixture
+	if err := mixture.fromModel(encoded); err != nil {
+		return GaussianMixture{}, err
+	}
+	return mixture, nil
+}
+
+// ImportSKLearnKMeans will read a scikit-learn KMeans exported as a JSON object holding its `cluster_centers_` as nested lists,
+// e.g., written by `json.dump({"cluster_centers_": model.cluster_centers_.tolist()}, f)`, see SKLearnCentroids.
+func ImportSKLearnKMeans(r io.Reader) (CentroidClusterer, error) {
+	var exported struct {
+		Centers json.RawMessage `json:"cluster_centers_"`
+	}
+	if err := json.NewDecoder(r).Decode(&exported); err != nil {
+		return nil, err
+	}
+	centers, err := decodeNDArray(exported.Centers, "cluster_centers_")
+	if err != nil {
+		return nil, err
+	}
+	return SKLearnCentroids(centers)
+}
+
+// ImportSKLearnGMM will read a scikit-learn GaussianMixture exported as a JSON object holding its `covariance_type` and
+// its `weights_`, `means_` and `covariances_` as nested lists, see SKLearnGMM.
+func ImportSKLearnGMM(r io.Reader) (GaussianMixture, error) {
+	var exported struct {
+		CovarianceType string          `json:"covariance_type"`
+		Weights        json.RawMessage `json:"weights_"`
+		Means          json.RawMessage `json:"means_"`
+		Covariances    json.RawMessage `json:"covariances_"`
+	}
+	if err := json.NewDecoder(r).Decode(&exported); err != nil {
+		return GaussianMixture{}, err
+	}
+	if exported.CovarianceType == "" {
+		exported.CovarianceType = "full"
+	}
+	arrays := make([]NDArray, 3)
+	for i, field := range []struct {
+		data json.RawMessage
+		name string
+	}{{exported.Weights, "weights_"}, {exported.Means, "means_"}, {exported.Covariances, "covariances_"}} {
+		array, err := decodeNDArray(field.data, field.name)
+		if err != nil {
+			return GaussianMixture{}, err
+		}
+		arrays[i] = array
+	}
+	return SKLearnGMM(exported.CovarianceType, arrays[0], arrays[1], arrays[2])
+}
+
+// decodeNDArray decodes nested JSON lists of numbers, which must be rectangular, into an array.
+func decodeNDArray(data json.RawMessage, what string) (NDArray, error) {
+	if len(data) == 0 {
+		return NDArray{}, fmt.Errorf("The exported model has no %s", what)
+	}
+	var nested interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&nested); err != nil {
+		return NDArray{}, err
+	}
+	var array NDArray
+	for level := nested; ; {
+		list, ok := level.([]interface{})
+		if !ok {
+			break
+		}
+		array.Shape = append(array.Shape, len(list))
+		if len(list) == 0 {
+			break
+		}
+		level = list[0]
+	}
+	var flatten func(value interface{}, axis int) error
+	flatten = func(value interface{}, axis int) error {
+		if axis == len(array.Shape) {
+			number, ok := value.(float64)
+			if !ok {
+				return fmt.Errorf("Expected %s to hold numbers but got %v", what, value)
+			}
+			array.Data = append(array.Data, number)
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok || len(list) != array.Shape[axis] {
+			return fmt.Errorf("Expected %s to be a rectangular array of shape %v", what, array.Shape)
+		}
+		for _, element := range list {
+			if err := flatten(element, axis+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return array, flatten(nested, 0)
+}
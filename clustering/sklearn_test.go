@@ -0,0 +1,218 @@
+package clustering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// npy encodes the values as a version 1 .npy file of the NumPy element type, whose header is padded like `numpy.save` pads it.
+func npy(descr string, fortran bool, shape []int, values []float64) []byte {
+	dims := make([]string, len(shape))
+	for i, dim := range shape {
+		dims[i] = fmt.Sprint(dim)
+	}
+	order := "False"
+	if fortran {
+		order = "True"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': (%s,), }", descr, order, strings.Join(dims, ", "))
+	header += strings.Repeat(" ", 63-(10+len(header))%64) + "\n"
+	var buffer bytes.Buffer
+	buffer.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&buffer, binary.LittleEndian, uint16(len(header)))
+	buffer.WriteString(header)
+	for _, value := range values {
+		switch descr {
+		case "<f8":
+			binary.Write(&buffer, binary.LittleEndian, value)
+		case "<f4":
+			binary.Write(&buffer, binary.LittleEndian, float32(value))
+		case "<i8":
+			binary.Write(&buffer, binary.LittleEndian, int64(value))
+		case "<i4":
+			binary.Write(&buffer, binary.LittleEndian, int32(value))
+		}
+	}
+	return buffer.Bytes()
+}
+
+// readNPY reads the .npy file, failing the test if reading it panics.
+func readNPY(t *testing.T, data []byte) (array NDArray, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("Reading %q panicked: %v", data, r)
+		}
+	}()
+	return ReadNPY(bytes.NewReader(data))
+}
+
+func TestReadNPY(t *testing.T) {
+	values := []float64{1, -2, 3, 4, 5, -6}
+	for _, descr := range []string{"<f8", "<f4", "<i8", "<i4"} {
+		array, err := readNPY(t, npy(descr, false, []int{2, 3}, values))
+		if err != nil {
+			t.Fatalf("%s: %v", descr, err)
+		}
+		if fmt.Sprint(array.Shape) != "[2 3]" || fmt.Sprint(array.Data) != fmt.Sprint(values) {
+			t.Errorf("%s: Expected %v of shape [2 3] but got %v of shape %v", descr, values, array.Data, array.Shape)
+		}
+	}
+	// The column-major elements of [[1, -2, 3], [4, 5, -6]].
+	array, err := readNPY(t, npy("<f8", true, []int{2, 3}, []float64{1, 4, -2, 5, 3, -6}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(array.Shape) != "[2 3]" || fmt.Sprint(array.Data) != fmt.Sprint(values) {
+		t.Errorf("Expected the Fortran-order array to read as %v but got %v of shape %v", values, array.Data, array.Shape)
+	}
+	if array, err := readNPY(t, npy("<f8", false, []int{0, 3}, nil)); err != nil || len(array.Data) != 0 {
+		t.Errorf("Expected an empty array but got %v, %v", array, err)
+	}
+}
+
+func TestReadNPYRejectsCorruptFiles(t *testing.T) {
+	valid := npy("<f8", false, []int{2, 3}, []float64{1, 2, 3, 4, 5, 6})
+	for length := 0; length < len(valid); length++ {
+		if _, err := readNPY(t, valid[:length]); err == nil {
+			t.Errorf("Reading the first %d of %d bytes succeeded", length, len(valid))
+		}
+	}
+	header := func(header string) []byte {
+		return append(append([]byte("\x93NUMPY\x01\x00"), byte(len(header)), byte(len(header)>>8)), header...)
+	}
+	hostile := map[string][]byte{
+		"magic":          append([]byte("\x93NUMPZ"), valid[6:]...),
+		"version":        append([]byte("\x93NUMPY\x04\x00"), valid[8:]...),
+		"huge header":    []byte("\x93NUMPY\x02\x00\xff\xff\xff\xff"),
+		"big-endian":     npy(">f8", false, []int{2, 3}, nil),
+		"complex":        npy("<c16", false, []int{2, 3}, nil),
+		"negative shape": header("{'descr': '<f8', 'fortran_order': False, 'shape': (-1, 3), }"),
+		"huge shape":     npy("<f8", false, []int{1 << 32, 1 << 32}, nil),
+		"overflow":       npy("<f8", false, []int{1 << 31, 1 << 33}, nil),
+		"zero overflow":  npy("<f8", false, []int{0, 1 << 40, 1 << 40}, nil),
+		"missing data":   npy("<f8", false, []int{1 << 30}, []float64{1, 2}),
+		"no shape":       header("{'descr': '<f8', 'fortran_order': False, }"),
+		"no descr":       header("{'fortran_order': False, 'shape': (2,), }"),
+		"open shape":     header("{'descr': '<f8', 'fortran_order': False, 'shape': (2, "),
+		"invalid shape":  header("{'descr': '<f8', 'fortran_order': False, 'shape': (2, x), }"),
+	}
+	for name, data := range hostile {
+		if _, err := readNPY(t, data); err == nil {
+			t.Errorf("%s: Expected an error", name)
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 1000; trial++ {
+		corrupt := append([]byte(nil), valid...)
+		for i := 0; i < 3; i++ {
+			corrupt[rng.Intn(len(corrupt))] = byte(rng.Intn(256))
+		}
+		if array, err := readNPY(t, corrupt); err == nil {
+			if err := array.check("the array"); err != nil {
+				t.Errorf("Reading %q returned an array that does not match its shape: %v", corrupt, err)
+			}
+		}
+	}
+}
+
+func TestSKLearnCentroids(t *testing.T) {
+	array, err := readNPY(t, npy("<f4", false, []int{2, 2}, []float64{0, 1, 10, 11}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	centroids, err := SKLearnCentroids(array)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(centroids) != "[[0 1] [10 11]]" {
+		t.Errorf("Expected the centroids [[0 1] [10 11]] but got %v", centroids)
+	}
+	for _, hostile := range []NDArray{
+		{Shape: []int{2, 2}, Data: []float64{1, 2, 3}},
+		{Shape: []int{4}, Data: []float64{1, 2, 3, 4}},
+		{Shape: []int{-2, -2}, Data: []float64{1, 2, 3, 4}},
+		{Shape: []int{1 << 31, 0}},
+		{Shape: []int{1 << 32, 1 << 32}},
+		{Shape: []int{math.MaxInt, 2, 0}},
+	} {
+		if _, err := SKLearnCentroids(hostile); err == nil {
+			t.Errorf("Expected an error for the centroids %v", hostile)
+		}
+	}
+}
+
+func TestImportSKLearnKMeans(t *testing.T) {
+	centroids, err := ImportSKLearnKMeans(strings.NewReader(`{"cluster_centers_": [[0, 1.5], [10, -11]], "n_iter_": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(centroids) != "[[0 1.5] [10 -11]]" {
+		t.Errorf("Expected the centroids [[0 1.5] [10 -11]] but got %v", centroids)
+	}
+	for _, hostile := range []string{``, `[]`, `{}`, `{"cluster_centers_": 3}`, `{"cluster_centers_": [[1, 2], [3]]}`, `{"cluster_centers_": [[1, 2], 3]}`,
+		`{"cluster_centers_": [[1, "2"]]}`, `{"cluster_centers_": [1, 2]}`, `{"cluster_centers_": [[[1]]]}`, `{"cluster_centers_": [[1, 2]`,
+		`{"cluster_centers_": [[], []]}`, `{"cluster_centers_": ` + strings.Repeat("[", 20000) + strings.Repeat("]", 20000) + `}`} {
+		if _, err := ImportSKLearnKMeans(strings.NewReader(hostile)); err == nil {
+			t.Errorf("Expected an error importing %.40q", hostile)
+		}
+	}
+}
+
+func TestImportSKLearnGMM(t *testing.T) {
+	weights, means := `[0.25, 0.75]`, `[[0, 0], [10, 10]]`
+	tests := []struct {
+		covarianceType, covariances string
+		want                        [][][]float64
+	}{
+		{"full", `[[[1, 0.5], [0.5, 2]], [[3, 0], [0, 4]]]`, [][][]float64{{{1, 0.5}, {0.5, 2}}, {{3, 0}, {0, 4}}}},
+		{"tied", `[[1, 0.5], [0.5, 2]]`, [][][]float64{{{1, 0.5}, {0.5, 2}}, {{1, 0.5}, {0.5, 2}}}},
+		{"diag", `[[1, 2], [3, 4]]`, [][][]float64{{{1, 0}, {0, 2}}, {{3, 0}, {0, 4}}}},
+		{"spherical", `[1, 3]`, [][][]float64{{{1, 0}, {0, 1}}, {{3, 0}, {0, 3}}}},
+	}
+	for _, test := range tests {
+		exported := fmt.Sprintf(`{"covariance_type": %q, "weights_": %s, "means_": %s, "covariances_": %s}`, test.covarianceType, weights, means, test.covariances)
+		mixture, err := ImportSKLearnGMM(strings.NewReader(exported))
+		if err != nil {
+			t.Fatalf("%s: %v", test.covarianceType, err)
+		}
+		if fmt.Sprint(mixture.Weights) != "[0.25 0.75]" || fmt.Sprint(mixture.Means) != "[[0 0] [10 10]]" || fmt.Sprint(mixture.Covariances) != fmt.Sprint(test.want) {
+			t.Errorf("%s: Expected %v, %v and %v but got %v, %v and %v", test.covarianceType, weights, means, test.want, mixture.Weights, mixture.Means, mixture.Covariances)
+		}
+		for want, point := range []Vector{VectorN{0.5, -1}, VectorN{9, 11}} {
+			if cluster, err := mixture.FindCluster(point); err != nil || cluster != Cluster(want) {
+				t.Errorf("%s: Expected %v in cluster %d but got %d, %v", test.covarianceType, point, want, cluster, err)
+			}
+		}
+	}
+	hostile := []string{
+		`{"covariance_type": "full", "weights_": [1], "means_": [[0, 0]], "covariances_": [[[1, 2], [2, 1]]]}`,
+		`{"covariance_type": "full", "weights_": [1], "means_": [[0, 0]], "covariances_": [[1, 0], [0, 1]]}`,
+		`{"covariance_type": "tied", "weights_": [0.5, 0.5], "means_": [[0], [1]], "covariances_": [[1], [1]]}`,
+		`{"covariance_type": "diag", "weights_": [1], "means_": [[0, 0]], "covariances_": [[1, -1]]}`,
+		`{"covariance_type": "spherical", "weights_": [1], "means_": [[0, 0]], "covariances_": [0]}`,
+		`{"covariance_type": "spherical", "weights_": [0.5, 0.5], "means_": [[0, 0]], "covariances_": [1]}`,
+		`{"covariance_type": "spherical", "weights_": [[1]], "means_": [[0, 0]], "covariances_": [1]}`,
+		`{"covariance_type": "spherical", "weights_": [1], "means_": [[0, 0]]}`,
+		`{"covariance_type": "full", "weights_": [1], "means_": [], "covariances_": []}`,
+		`{"covariance_type": "banded", "weights_": [1], "means_": [[0, 0]], "covariances_": [1]}`,
+		`{"weights_": [1], "means_": [[0, 0]], "covariances_": [1]}`,
+		`{"covariance_type": "spherical", "weights_": [1], "means_": [[0, 0]], "covariances_": [1]`,
+	}
+	for _, exported := range hostile {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Importing %s panicked: %v", exported, r)
+				}
+			}()
+			if _, err := ImportSKLearnGMM(strings.NewReader(exported)); err == nil {
+				t.Errorf("Expected an error importing %s", exported)
+			}
+		}()
+	}
+}
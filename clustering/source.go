@@ -0,0 +1,69 @@
+package clustering
+
+import "errors"
+
+// DataSource produces vectors one at a time, e.g., while reading them from a file or network stream,
+// such that algorithms only needing a single pass over the data do not need the entire dataset in memory.
+// Sources that can fail should also provide an `Err() error` method returning the error that ended the source, if any.
+type DataSource interface {
+	// Next returns the next vector, or false when the source is exhausted.
+	Next() (Vector, bool)
+}
+
+type datasetSource struct {
+	data []Vector
+	next int
+}
+
+func (source *datasetSource) Next() (Vector, bool) {
+	if source.next >= len(source.data) {
+		return nil, false
+	}
+	source.next++
+	return source.data[source.next-1], true
+}
+
+// Source will return a DataSource producing the vectors of this dataset in order.
+func (dataset *Dataset) Source() DataSource {
+	return &datasetSource{data: dataset.data}
+}
+
+// Collect will read all vectors of the source into a dataset, the output of the source's `Err` method is returned if it has one.
+// The creator is used for the dataset when the source is empty, otherwise the creator of the first vector is used.
+func Collect(source DataSource, creator VectorCreator) (Dataset, error) {
+	var data []Vector
+	for vec, ok := source.Next(); ok; vec, ok = source.Next() {
+		data = append(data, vec)
+	}
+	if err := sourceErr(source); err != nil {
+		return Dataset{}, err
+	}
+	if len(data) > 0 {
+		creator = data[0].Creator()
+	}
+	return CreateDataset(data, creator), nil
+}
+
+// sourceErr returns the error that ended the source, if it reports errors at all.
+func sourceErr(source DataSource) error {
+	if failing, ok := source.(interface{ Err() error }); ok {
+		return failing.Err()
+	}
+	return nil
+}
+
+// SequentialKMeans will perform single-pass (MacQueen) K-Means clustering on the vectors of the source starting from the provided centroids:
+// every vector moves its nearest centroid towards it by a fraction inversely proportional to the number of vectors assigned to that centroid so far.
+// The vectors are consumed one at a time, so the source may be far larger than the available memory.
+func SequentialKMeans(source DataSource, centroids ...Vector) (CentroidClusterer, error) {
+	if len(centroids) == 0 {
+		return nil, errors.New("Expected at least one initial centroid")
+	}
+	model := IncrementalCentroids{Centroids: append(CentroidClusterer(nil), centroids...)}
+	for vec, ok := source.Next(); ok; vec, ok = source.Next() {
+		if err := model.Update(vec); err != nil {
+			return nil, err
+		}
+	}
+	return model.Centroids, sourceErr(source)
+}
@@ -0,0 +1,185 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+// SparseVector is a real vector with an arbitrary, but fixed, number of components of which only the non-zero components are stored,
+// e.g., for bag-of-words or one-hot encoded data where nearly all of the many components are zero.
+type SparseVector struct {
+	dimension int
+	// indices holds the indices of the stored components in increasing order, values holds the corresponding components.
+	indices []int
+	values  []float64
+}
+
+// SparseVectord creates a new sparse vector of the provided dimension with `values[k]` as its `indices[k]`th component and all other components zero.
+// SparseVectord panics if the indices are out of range or there are not as many values as indices.
+func SparseVectord(dimension int, indices []int, values []float64) SparseVector {
+	if len(indices) != len(values) {
+		panic(fmt.Sprintf("Expected as many values as indices but got %d values and %d indices", len(values), len(indices)))
+	}
+	order := make([]int, len(indices))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool { return indices[order[a]] < indices[order[b]] })
+	v := SparseVector{dimension: dimension}
+	for _, k := range order {
+		if indices[k] < 0 || indices[k] >= dimension {
+			panic(fmt.Sprintf("Index %d is out of range for a vector of dimension %d", indices[k], dimension))
+		}
+		if last := len(v.indices) - 1; last >= 0 && v.indices[last] == indices[k] {
+			v.values[last] += values[k]
+		} else if values[k] != 0 {
+			v.indices, v.values = append(v.indices, indices[k]), append(v.values, values[k])
+		}
+	}
+	return v
+}
+
+type sparseVectorCreator int
+
+func (dimension sparseVectorCreator) New(f func(int) float64) Vector {
+	v := SparseVector{dimension: int(dimension)}
+	for i := 0; i < int(dimension); i++ {
+		if value := f(i); value != 0 {
+			v.indices, v.values = append(v.indices, i), append(v.values, value)
+		}
+	}
+	return v
+}
+
+func (dimension sparseVectorCreator) Null() Vector {
+	return SparseVector{dimension: int(dimension)}
+}
+
+func checkSparseVector(v SparseVector, other Vector) SparseVector {
+	otherv, ok := other.(SparseVector)
+	if !ok {
+		panic("Expected a SparseVector but got " + reflect.TypeOf(other).Name())
+	}
+	if v.dimension != otherv.dimension {
+		panic(fmt.Sprintf("Expected a SparseVector of dimension %d but got dimension %d", v.dimension, otherv.dimension))
+	}
+	return otherv
+}
+
+// combine merges the non-zero components of both vectors, combining the components by `f`.
+func (v SparseVector) combine(other SparseVector, f func(a, b float64) float64) SparseVector {
+	result := SparseVector{dimension: v.dimension}
+	for a, b := 0, 0; a < len(v.indices) || b < len(other.indices); {
+		var index int
+		var value float64
+		switch {
+		case b == len(other.indices) || (a < len(v.indices) && v.indices[a] < other.indices[b]):
+			index, value = v.indices[a], f(v.values[a], 0)
+			a++
+		case a == len(v.indices) || other.indices[b] < v.indices[a]:
+			index, value = other.indices[b], f(0, other.values[b])
+			b++
+		default:
+			index, value = v.indices[a], f(v.values[a], other.values[b])
+			a, b = a+1, b+1
+		}
+		if value != 0 {
+			result.indices, result.values = append(result.indices, index), append(result.values, value)
+		}
+	}
+	return result
+}
+
+// Add adds two vectors by component-wise addition and returns the result.
+func (v SparseVector) Add(other Vector) Vector {
+	return v.combine(checkSparseVector(v, other), func(a, b float64) float64 { return a + b })
+}
+
+// Subtract subtracts the other vector from this vector, i.e., `v - other`.
+func (v SparseVector) Subtract(other Vector) Vector {
+	return v.combine(checkSparseVector(v, other), func(a, b float64) float64 { return a - b })
+}
+
+// MulScalar multiplies this vector with a scalar.
+func (v SparseVector) MulScalar(other float64) Vector {
+	if other == 0 {
+		return v.Creator().Null()
+	}
+	result := SparseVector{dimension: v.dimension, indices: v.indices, values: make([]float64, len(v.values))}
+	for k, value := range v.values {
+		result.values[k] = value * other
+	}
+	return result
+}
+
+// TransposedMul multiplies the transpose of this vector with the other vector.
+func (v SparseVector) TransposedMul(other Vector) float64 {
+	otherv := checkSparseVector(v, other)
+	result := 0.0
+	for a, b := 0, 0; a < len(v.indices) && b < len(otherv.indices); {
+		switch {
+		case v.indices[a] < otherv.indices[b]:
+			a++
+		case otherv.indices[b] < v.indices[a]:
+			b++
+		default:
+			result += v.values[a] * otherv.values[b]
+			a, b = a+1, b+1
+		}
+	}
+	return result
+}
+
+// Length calculates the length of this vector.
+func (v SparseVector) Length() float64 {
+	return math.Sqrt(v.TransposedMul(v))
+}
+
+// Normalize will calculate the vector in the same direction but with a length of 1. When this vector is the null-vector a random vector with length 1 is returned.
+func (v SparseVector) Normalize() Vector {
+	if v.dimension == 0 {
+		return v
+	} else if v.Length() == 0 {
+		return v.Creator().New(func(_ int) float64 {
+			return rand.NormFloat64()
+		}).Normalize()
+	}
+
+	return v.MulScalar(1 / v.Length())
+}
+
+// DistanceTo will return the distance between this vector and the other vector.
+func (v SparseVector) DistanceTo(other Vector) float64 {
+	return v.Subtract(other).Length()
+}
+
+// Creator will return a VectorCreator creating SparseVectors of the same dimension as this vector.
+func (v SparseVector) Creator() VectorCreator {
+	return sparseVectorCreator(v.dimension)
+}
+
+// Dimension will return the number of components of this vector.
+func (v SparseVector) Dimension() int {
+	return v.dimension
+}
+
+// Component will return the `i`th component of this vector.
+func (v SparseVector) Component(i int) float64 {
+	if i < 0 || i >= v.dimension {
+		panic(fmt.Sprintf("Index %d is out of range for a vector of dimension %d", i, v.dimension))
+	}
+	if k := sort.SearchInts(v.indices, i); k < len(v.indices) && v.indices[k] == i {
+		return v.values[k]
+	}
+	return 0
+}
+
+// NonZero will call `f` with the index and value of every non-zero component of this vector, in increasing order of the indices.
+func (v SparseVector) NonZero(f func(i int, value float64)) {
+	for k, i := range v.indices {
+		f(i, v.values[k])
+	}
+}
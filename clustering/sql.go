@@ -0,0 +1,79 @@
+package clustering
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SQLQueryer executes queries, e.g., a `*sql.DB`, `*sql.Conn` or `*sql.Tx`.
+type SQLQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// LoadSQL will execute the query and read every column of every resulting row into a VectorN, all columns must be numeric and non-null.
+func LoadSQL(ctx context.Context, db SQLQueryer, query string, args ...interface{}) (Dataset, error) {
+	var data []Vector
+	var creator VectorCreator
+	err := StreamSQL(ctx, db, 1024, func(batch Dataset) error {
+		data, creator = append(data, batch.data...), batch.creator
+		return nil
+	}, query, args...)
+	if err != nil {
+		return Dataset{}, err
+	}
+	return CreateDataset(data, creator), nil
+}
+
+// StreamSQL will execute the query and hand the resulting rows to `f` in datasets of at most `batchSize` VectorNs,
+// such that large results never have to be kept in memory entirely. All columns must be numeric and non-null.
+// `f` is called at least once, reading stops at the first error returned by `f`.
+func StreamSQL(ctx context.Context, db SQLQueryer, batchSize int, f func(Dataset) error, query string, args ...interface{}) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("Expected a positive batch size but got %d", batchSize)
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	creator := vectorNCreator(len(columns))
+	values := make([]sql.NullFloat64, len(columns))
+	destinations := make([]interface{}, len(columns))
+	for i := range values {
+		destinations[i] = &values[i]
+	}
+	batch, row := make([]Vector, 0, batchSize), 0
+	for rows.Next() {
+		if err := rows.Scan(destinations...); err != nil {
+			return fmt.Errorf("Could not read row %d: %v", row, err)
+		}
+		vec := make(VectorN, len(columns))
+		for i, value := range values {
+			if !value.Valid {
+				return fmt.Errorf("Column %q of row %d is null", columns[i], row)
+			}
+			vec[i] = value.Float64
+		}
+		batch = append(batch, vec)
+		row++
+		if len(batch) == batchSize {
+			if err := f(CreateDataset(batch, creator)); err != nil {
+				return err
+			}
+			batch = make([]Vector, 0, batchSize)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 || row == 0 {
+		return f(CreateDataset(batch, creator))
+	}
+	return nil
+}
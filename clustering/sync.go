@@ -0,0 +1,47 @@
+package clustering
+
+import "sync"
+
+// SyncDataset is a dataset that can safely be shared by goroutines, e.g., in a long-running service
+// where one goroutine appends incoming vectors while others cluster the vectors received so far.
+// Vectors can only be appended, so every Snapshot stays valid, and unchanged, while new vectors arrive.
+type SyncDataset struct {
+	mutex   sync.RWMutex
+	dataset Dataset
+}
+
+// NewSyncDataset will create a SyncDataset initially holding the vectors of the dataset, the dataset should not be modified afterwards.
+func NewSyncDataset(dataset Dataset) *SyncDataset {
+	return &SyncDataset{dataset: dataset}
+}
+
+// Append will add the vectors to the end of this dataset, returning an error without changing the dataset if one of them does not belong to its vector space.
+func (dataset *SyncDataset) Append(vecs ...Vector) error {
+	dataset.mutex.Lock()
+	defer dataset.mutex.Unlock()
+	return dataset.dataset.Append(vecs...)
+}
+
+// AppendWeighted will add the vector with the provided weight to the end of this dataset, making the dataset weighted if it was not.
+func (dataset *SyncDataset) AppendWeighted(vec Vector, weight float64) error {
+	dataset.mutex.Lock()
+	defer dataset.mutex.Unlock()
+	return dataset.dataset.AppendWeighted(vec, weight)
+}
+
+// Count will return the number of data points in this dataset.
+func (dataset *SyncDataset) Count() int {
+	dataset.mutex.RLock()
+	defer dataset.mutex.RUnlock()
+	return dataset.dataset.Count()
+}
+
+// Snapshot will return the vectors currently in this dataset without copying them, any algorithm can be run on the snapshot
+// while other goroutines keep appending to this dataset. The snapshot shares its storage with this dataset, so its vectors must not be replaced or removed.
+func (dataset *SyncDataset) Snapshot() Dataset {
+	dataset.mutex.RLock()
+	defer dataset.mutex.RUnlock()
+	// Limiting the capacity makes appends to this dataset write beyond the snapshot, and appends to the snapshot copy its storage.
+	snapshot, _ := dataset.dataset.Slice(0, dataset.dataset.Count())
+	return snapshot
+}
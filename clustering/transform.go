@@ -0,0 +1,37 @@
+package clustering
+
+import "fmt"
+
+// Transformer maps vectors from one vector space to another, e.g., to rescale the features of a dataset before clustering it.
+type Transformer interface {
+	// Transform maps the vector into the target vector space.
+	Transform(v Vector) Vector
+}
+
+// InvertibleTransformer is a Transformer that can map vectors back into the original vector space, e.g., to express centroids in the original units.
+type InvertibleTransformer interface {
+	Transformer
+	// InverseTransform maps the vector from the target vector space back into the original vector space.
+	InverseTransform(v Vector) Vector
+}
+
+// Transform will return a dataset holding every vector of this dataset mapped by the transformer.
+func (dataset *Dataset) Transform(transformer Transformer) Dataset {
+	return dataset.Map(transformer.Transform)
+}
+
+// InverseTransform will return a clusterer with every centroid mapped back by the transformer,
+// e.g., to express the centroids of a clusterer fitted on a scaled dataset in the units of the original dataset.
+func (clusterer CentroidClusterer) InverseTransform(transformer InvertibleTransformer) CentroidClusterer {
+	centroids := make([]Vector, len(clusterer))
+	for i, centroid := range clusterer {
+		centroids[i] = transformer.InverseTransform(centroid)
+	}
+	return centroids
+}
+
+func checkTransformDimension(v Vector, dimension int) {
+	if Dimension(v) != dimension {
+		panic(fmt.Sprintf("Expected a vector of dimension %d but got dimension %d", dimension, Dimension(v)))
+	}
+}
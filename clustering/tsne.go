@@ -0,0 +1,322 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// TSNEOptions configures a t-SNE embedding, every option that is not set falls back to its default.
+type TSNEOptions struct {
+	// Perplexity is the effective number of neighbours every vector considers, 30 by default.
+	Perplexity float64
+	// Iterations is the number of gradient descent steps, 1000 by default.
+	Iterations int
+	// LearningRate is the step size of the gradient descent, 200 by default.
+	LearningRate float64
+	// Theta trades accuracy for speed in the Barnes-Hut approximation, 0.5 is a common choice.
+	// When Theta is 0 the exact, quadratic, algorithm is used.
+	Theta float64
+	// Metric measures the distances between the vectors of the dataset, Euclidean by default.
+	Metric Metric
+}
+
+// TSNE will embed the vectors of this dataset into the plane with t-distributed stochastic neighbour embedding, such that vectors that are close
+// in the original space are close in the embedding. The `i`th Vector2 of the resulting dataset is the embedding of the `i`th vector of this dataset,
+// so the embedding can be coloured by the Assignments of any clusterer fitted on this dataset to visually validate high-dimensional clusterings.
+func (dataset *Dataset) TSNE(opts TSNEOptions, src rand.Source) (Dataset, error) {
+	opts = opts.withDefaults()
+	n := dataset.Count()
+	if n < 2 {
+		return Dataset{}, errors.New("Expected at least two vectors to embed")
+	}
+	if opts.Perplexity >= float64(n-1) {
+		return Dataset{}, fmt.Errorf("Expected a perplexity below %d for a dataset of %d vectors but got %v", n-1, n, opts.Perplexity)
+	}
+	if opts.Theta < 0 {
+		return Dataset{}, fmt.Errorf("Expected a non-negative theta but got %v", opts.Theta)
+	}
+
+	affinities := tsneAffinities(dataset.data, opts)
+	rng := rand.New(src)
+	embedding, velocity, gains := make([][2]float64, n), make([][2]float64, n), make([][2]float64, n)
+	for i := range embedding {
+		embedding[i] = [2]float64{1e-4 * rng.NormFloat64(), 1e-4 * rng.NormFloat64()}
+		gains[i] = [2]float64{1, 1}
+	}
+	for iteration := 0; iteration < opts.Iterations; iteration++ {
+		// Early exaggeration first forms tight, well separated groups which are subsequently refined.
+		exaggeration, momentum := 1.0, 0.8
+		if iteration < 250 {
+			exaggeration, momentum = 12, 0.5
+		}
+		gradient := tsneGradient(embedding, affinities, exaggeration, opts.Theta)
+		var mean [2]float64
+		for i := range embedding {
+			for d := 0; d < 2; d++ {
+				if (gradient[i][d] > 0) != (velocity[i][d] > 0) {
+					gains[i][d] += 0.2
+				} else {
+					gains[i][d] = math.Max(0.01, gains[i][d]*0.8)
+				}
+				velocity[i][d] = momentum*velocity[i][d] - opts.LearningRate*gains[i][d]*gradient[i][d]
+				embedding[i][d] += velocity[i][d]
+				mean[d] += embedding[i][d] / float64(n)
+			}
+		}
+		for i := range embedding {
+			embedding[i][0] -= mean[0]
+			embedding[i][1] -= mean[1]
+		}
+	}
+
+	data := make([]Vector, n)
+	for i, point := range embedding {
+		data[i] = Vector2d(point[0], point[1])
+	}
+	return CreateDataset(data, vector2Creator{}), nil
+}
+
+func (opts TSNEOptions) withDefaults() TSNEOptions {
+	if opts.Perplexity <= 0 {
+		opts.Perplexity = 30
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 1000
+	}
+	if opts.LearningRate <= 0 {
+		opts.LearningRate = 200
+	}
+	if opts.Metric == nil {
+		opts.Metric = Euclidean
+	}
+	return opts
+}
+
+type tsneAffinity struct {
+	j int
+	p float64
+}
+
+// tsneAffinities computes the symmetric joint probabilities `p_ij` of the vectors, considering all other vectors in the exact algorithm
+// and only the `3 * perplexity` nearest neighbours of every vector when approximating.
+func tsneAffinities(data []Vector, opts TSNEOptions) [][]tsneAffinity {
+	n := len(data)
+	neighbours := n - 1
+	if opts.Theta > 0 {
+		neighbours = min(n-1, int(3*opts.Perplexity))
+	}
+	conditional := make([][]tsneAffinity, n)
+	parallelFor(n, func(i int) {
+		candidates := make([]tsneAffinity, 0, n-1)
+		for j := range data {
+			if j != i {
+				distance := opts.Metric(data[i], data[j])
+				candidates = append(candidates, tsneAffinity{j: j, p: distance * distance})
+			}
+		}
+		if neighbours < len(candidates) {
+			sort.Slice(candidates, func(a, b int) bool { return candidates[a].p < candidates[b].p })
+			candidates = candidates[:neighbours]
+		}
+		conditional[i] = conditionalAffinities(candidates, opts.Perplexity)
+	})
+
+	joint := make([]map[int]float64, n)
+	for i := range joint {
+		joint[i] = make(map[int]float64)
+	}
+	for i, row := range conditional {
+		for _, affinity := range row {
+			p := affinity.p / float64(2*n)
+			joint[i][affinity.j] += p
+			joint[affinity.j][i] += p
+		}
+	}
+	affinities := make([][]tsneAffinity, n)
+	for i, row := range joint {
+		for j, p := range row {
+			affinities[i] = append(affinities[i], tsneAffinity{j: j, p: p})
+		}
+		// Fixing the order keeps the floating point sums, and thereby the embedding, reproducible.
+		sort.Slice(affinities[i], func(a, b int) bool { return affinities[i][a].j < affinities[i][b].j })
+	}
+	return affinities
+}
+
+// conditionalAffinities turns the squared distances to the candidates into the probabilities `p_j|i` of a Gaussian
+// whose precision is found by bisection such that the distribution has the requested perplexity.
+func conditionalAffinities(candidates []tsneAffinity, perplexity float64) []tsneAffinity {
+	targetEntropy := math.Log(perplexity)
+	precision, lower, upper := 1.0, 0.0, math.Inf(1)
+	probabilities := make([]tsneAffinity, len(candidates))
+	// Distances are shifted by the smallest distance to avoid underflow, which does not change the normalized probabilities.
+	smallest := math.Inf(1)
+	for _, candidate := range candidates {
+		smallest = math.Min(smallest, candidate.p)
+	}
+	for step := 0; step < 100; step++ {
+		total, weighted := 0.0, 0.0
+		for k, candidate := range candidates {
+			p := math.Exp(-precision * (candidate.p - smallest))
+			probabilities[k] = tsneAffinity{j: candidate.j, p: p}
+			total += p
+			weighted += p * (candidate.p - smallest)
+		}
+		entropy := math.Log(total) + precision*weighted/total
+		for k := range probabilities {
+			probabilities[k].p /= total
+		}
+		if math.Abs(entropy-targetEntropy) < 1e-5 {
+			break
+		} else if entropy > targetEntropy {
+			lower = precision
+			if math.IsInf(upper, 1) {
+				precision *= 2
+			} else {
+				precision = (precision + upper) / 2
+			}
+		} else {
+			upper = precision
+			precision = (precision + lower) / 2
+		}
+	}
+	return probabilities
+}
+
+// tsneGradient computes the gradient of the Kullback-Leibler divergence between the affinities and the Student-t similarities of the embedding.
+func tsneGradient(embedding [][2]float64, affinities [][]tsneAffinity, exaggeration, theta float64) [][2]float64 {
+	n := len(embedding)
+	gradient, repulsion, normalization := make([][2]float64, n), make([][2]float64, n), make([]float64, n)
+	var tree *quadTree
+	if theta > 0 {
+		tree = newQuadTree(embedding)
+	}
+	parallelFor(n, func(i int) {
+		for _, affinity := range affinities[i] {
+			dx, dy := embedding[i][0]-embedding[affinity.j][0], embedding[i][1]-embedding[affinity.j][1]
+			q := 1 / (1 + dx*dx + dy*dy)
+			gradient[i][0] += exaggeration * affinity.p * q * dx
+			gradient[i][1] += exaggeration * affinity.p * q * dy
+		}
+		if tree != nil {
+			tree.repulsion(embedding, i, theta, &repulsion[i], &normalization[i])
+			return
+		}
+		for j := range embedding {
+			if j != i {
+				dx, dy := embedding[i][0]-embedding[j][0], embedding[i][1]-embedding[j][1]
+				q := 1 / (1 + dx*dx + dy*dy)
+				normalization[i] += q
+				repulsion[i][0] += q * q * dx
+				repulsion[i][1] += q * q * dy
+			}
+		}
+	})
+	z := sum(normalization)
+	for i := range gradient {
+		gradient[i][0] = 4 * (gradient[i][0] - repulsion[i][0]/z)
+		gradient[i][1] = 4 * (gradient[i][1] - repulsion[i][1]/z)
+	}
+	return gradient
+}
+
+// quadTree summarizes the points of the embedding within a square cell by their number and center of mass for the Barnes-Hut approximation.
+type quadTree struct {
+	center    [2]float64
+	halfWidth float64
+	mass      [2]float64
+	count     int
+	points    []int
+	children  *[4]quadTree
+}
+
+func newQuadTree(embedding [][2]float64) *quadTree {
+	minimum, maximum := [2]float64{math.Inf(1), math.Inf(1)}, [2]float64{math.Inf(-1), math.Inf(-1)}
+	for _, point := range embedding {
+		for d := 0; d < 2; d++ {
+			minimum[d], maximum[d] = math.Min(minimum[d], point[d]), math.Max(maximum[d], point[d])
+		}
+	}
+	tree := &quadTree{
+		center:    [2]float64{(minimum[0] + maximum[0]) / 2, (minimum[1] + maximum[1]) / 2},
+		halfWidth: math.Max(maximum[0]-minimum[0], maximum[1]-minimum[1])/2 + 1e-5,
+	}
+	for i := range embedding {
+		tree.insert(embedding, i)
+	}
+	return tree
+}
+
+func (tree *quadTree) insert(embedding [][2]float64, i int) {
+	tree.count++
+	tree.mass[0] += embedding[i][0]
+	tree.mass[1] += embedding[i][1]
+	if tree.children == nil {
+		tree.points = append(tree.points, i)
+		// Cells are only split while they are large enough, so points at the same position end up in the same leaf.
+		if len(tree.points) == 1 || tree.halfWidth < 1e-9 {
+			return
+		}
+		tree.children = new([4]quadTree)
+		for k := range tree.children {
+			child := &tree.children[k]
+			child.halfWidth = tree.halfWidth / 2
+			child.center = tree.center
+			child.center[0] += child.halfWidth * float64(2*(k&1)-1)
+			child.center[1] += child.halfWidth * float64(k&2-1)
+		}
+		points := tree.points
+		tree.points = nil
+		for _, point := range points {
+			tree.child(embedding[point]).insert(embedding, point)
+		}
+		return
+	}
+	tree.child(embedding[i]).insert(embedding, i)
+}
+
+func (tree *quadTree) child(point [2]float64) *quadTree {
+	k := 0
+	if point[0] > tree.center[0] {
+		k |= 1
+	}
+	if point[1] > tree.center[1] {
+		k |= 2
+	}
+	return &tree.children[k]
+}
+
+// repulsion accumulates the approximate repulsive force on the `i`th point and its contribution to the normalization of the similarities.
+func (tree *quadTree) repulsion(embedding [][2]float64, i int, theta float64, force *[2]float64, normalization *float64) {
+	if tree.count == 0 {
+		return
+	}
+	if tree.children == nil {
+		for _, j := range tree.points {
+			if j != i {
+				dx, dy := embedding[i][0]-embedding[j][0], embedding[i][1]-embedding[j][1]
+				q := 1 / (1 + dx*dx + dy*dy)
+				*normalization += q
+				force[0] += q * q * dx
+				force[1] += q * q * dy
+			}
+		}
+		return
+	}
+	count := float64(tree.count)
+	dx, dy := embedding[i][0]-tree.mass[0]/count, embedding[i][1]-tree.mass[1]/count
+	distance2 := dx*dx + dy*dy
+	if 4*tree.halfWidth*tree.halfWidth < theta*theta*distance2 {
+		q := 1 / (1 + distance2)
+		*normalization += count * q
+		force[0] += count * q * q * dx
+		force[1] += count * q * q * dy
+		return
+	}
+	for k := range tree.children {
+		tree.children[k].repulsion(embedding, i, theta, force, normalization)
+	}
+}
@@ -0,0 +1,44 @@
+package clustering
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestConditionalAffinitiesPerplexity(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// Tight clusters far apart make the precision large, such that shifting by any other than the smallest distance overflows.
+	var data []Vector
+	for c := 0; c < 6; c++ {
+		centre := VectorN{rng.NormFloat64() * 100, rng.NormFloat64() * 100, rng.NormFloat64() * 100}
+		for i := 0; i < 10; i++ {
+			data = append(data, VectorN{centre[0] + rng.NormFloat64()*0.1, centre[1] + rng.NormFloat64()*0.1, centre[2] + rng.NormFloat64()*0.1})
+		}
+	}
+	for _, perplexity := range []float64{2, 5, 30} {
+		for i := range data {
+			// The candidates are in the order of the dataset as in the exact algorithm, not sorted by distance.
+			var candidates []tsneAffinity
+			for j := range data {
+				if j != i {
+					distance := Euclidean(data[i], data[j])
+					candidates = append(candidates, tsneAffinity{j: j, p: distance * distance})
+				}
+			}
+			entropy, total := 0.0, 0.0
+			for _, affinity := range conditionalAffinities(candidates, perplexity) {
+				total += affinity.p
+				if affinity.p > 0 {
+					entropy -= affinity.p * math.Log(affinity.p)
+				}
+			}
+			if math.Abs(total-1) > 1e-9 {
+				t.Fatalf("The affinities of row %d sum to %v, expected 1", i, total)
+			}
+			if achieved := math.Exp(entropy); math.Abs(achieved-perplexity) > 1e-3*perplexity {
+				t.Errorf("Row %d achieves a perplexity of %v, expected %v", i, achieved, perplexity)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package clustering
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypedDataset is a dataset whose vectors all have the concrete type `V`, such that its operations return `V`s instead of Vectors that need type assertions.
+// Untyped gives access to the underlying Dataset for all other algorithms.
+type TypedDataset[V Vector] struct {
+	dataset Dataset
+}
+
+// NewTypedDataset will create a typed dataset containing the provided vectors.
+// The vector space of an empty dataset is the one of the zero value of `V`.
+func NewTypedDataset[V Vector](data ...V) TypedDataset[V] {
+	vecs := make([]Vector, len(data))
+	for i, vec := range data {
+		vecs[i] = vec
+	}
+	var zero V
+	creator := zero.Creator()
+	if len(data) > 0 {
+		creator = data[0].Creator()
+	}
+	return TypedDataset[V]{dataset: CreateDataset(vecs, creator)}
+}
+
+// Typed will return the dataset as a typed dataset, returning an error if one of its vectors is not a `V`, e.g., `Typed[VectorN](LoadCSV(r, opts))`.
+func Typed[V Vector](dataset Dataset, err error) (TypedDataset[V], error) {
+	if err != nil {
+		return TypedDataset[V]{}, err
+	}
+	for i, vec := range dataset.data {
+		if _, ok := vec.(V); !ok {
+			var zero V
+			return TypedDataset[V]{}, fmt.Errorf("Expected a %v but vector %d is a %v", reflect.TypeOf(zero), i, reflect.TypeOf(vec))
+		}
+	}
+	return TypedDataset[V]{dataset: dataset}, nil
+}
+
+// Untyped will return the underlying dataset, which shares its storage with this typed dataset.
+func (dataset *TypedDataset[V]) Untyped() *Dataset {
+	return &dataset.dataset
+}
+
+// Count will return the number of data points in this dataset.
+func (dataset *TypedDataset[V]) Count() int {
+	return dataset.dataset.Count()
+}
+
+// At will return the `i`th vector of this dataset.
+func (dataset *TypedDataset[V]) At(i int) V {
+	return dataset.dataset.data[i].(V)
+}
+
+// Vectors will return a copy of the vectors of this dataset in the same order.
+func (dataset *TypedDataset[V]) Vectors() []V {
+	return typedVectors[V](dataset.dataset.data)
+}
+
+// Append will add the vectors to the end of this dataset, returning an error without changing the dataset if one of them does not belong to its vector space.
+func (dataset *TypedDataset[V]) Append(vecs ...V) error {
+	untyped := make([]Vector, len(vecs))
+	for i, vec := range vecs {
+		untyped[i] = vec
+	}
+	return dataset.dataset.Append(untyped...)
+}
+
+// Max will return the largest vector in the dataset, if there are multiple largest vectors, the first is returned, if the dataset is empty, a vector with size 0 is returned.
+func (dataset *TypedDataset[V]) Max() V {
+	return dataset.dataset.Max().(V)
+}
+
+// Partition will split the dataset according to the cluster the clusterer assigns to each element, like ClusteredPartition.
+func (dataset *TypedDataset[V]) Partition(clusterer SimpleFlatClusterer) (map[Cluster][]V, error) {
+	assignments, err := Assignments(&dataset.dataset, clusterer)
+	if err != nil {
+		return nil, err
+	}
+	partition := make(map[Cluster][]V)
+	for i, cluster := range assignments {
+		partition[cluster] = append(partition[cluster], dataset.At(i))
+	}
+	return partition, nil
+}
+
+// TypedCentroids will return the centroids of the clusterer as `V`s, panicking if one of them is not a `V`.
+func TypedCentroids[V Vector](clusterer CentroidClusterer) []V {
+	return typedVectors[V](clusterer)
+}
+
+func typedVectors[V Vector](vecs []Vector) []V {
+	typed := make([]V, len(vecs))
+	for i, vec := range vecs {
+		typed[i] = vec.(V)
+	}
+	return typed
+}
@@ -0,0 +1,352 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Silhouette will compute the silhouette value of every vector of the dataset, in order, and their mean for the clustering by the clusterer.
+// The silhouette of a vector compares its mean distance `a` to the other vectors of its cluster with its mean distance `b` to the vectors of the nearest other cluster as `(b - a) / max(a, b)`,
+// values close to 1 indicate a vector that is well inside its cluster while negative values indicate a vector that is closer to another cluster.
+// Vectors in singleton clusters have silhouette 0. All pairwise distances are computed, using all cores, so the metric must be safe for concurrent use;
+// see SampledSilhouette for large datasets.
+func Silhouette(dataset *Dataset, clusterer SimpleFlatClusterer, metric Metric) (float64, []float64, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return 0, nil, err
+	}
+	values, err := silhouetteValues(dataset.data, assignments, metric)
+	if err != nil {
+		return 0, nil, err
+	}
+	return sum(values) / float64(len(values)), values, nil
+}
+
+// SampledSilhouette will estimate the mean silhouette of the clustering by the clusterer from a uniform random sample of `n` vectors of the dataset,
+// computing only the distances between the sampled vectors. The entire dataset is used when it has no more than `n` vectors.
+func SampledSilhouette(dataset *Dataset, clusterer SimpleFlatClusterer, metric Metric, n int, src rand.Source) (float64, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("Expected a non-negative sample size but got %d", n)
+	}
+	sample := dataset.Sample(n, src)
+	mean, _, err := Silhouette(&sample, clusterer, metric)
+	return mean, err
+}
+
+// SilhouetteGroup holds the silhouettes of the vectors of a single cluster in decreasing order, as drawn in a silhouette plot.
+type SilhouetteGroup struct {
+	// Cluster is the cluster the vectors belong to.
+	Cluster Cluster
+	// Indices holds the index of every vector in the dataset, such that vector `Indices[i]` has silhouette `Values[i]`.
+	Indices []int
+	// Values holds the silhouettes of the vectors in decreasing order.
+	Values []float64
+	// Mean is the mean silhouette of the vectors of the cluster.
+	Mean float64
+}
+
+// SilhouetteGroups will compute the silhouette of every vector of the dataset like Silhouette and group them by cluster in increasing order of the clusters,
+// such that they can be fed straight into a silhouette plot.
+func SilhouetteGroups(dataset *Dataset, clusterer SimpleFlatClusterer, metric Metric) ([]SilhouetteGroup, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return nil, err
+	}
+	values, err := silhouetteValues(dataset.data, assignments, metric)
+	if err != nil {
+		return nil, err
+	}
+	partition := PartitionIndices(assignments)
+	groups := make([]SilhouetteGroup, 0, len(partition))
+	for cluster, indices := range partition {
+		sort.SliceStable(indices, func(a, b int) bool { return values[indices[a]] > values[indices[b]] })
+		group := SilhouetteGroup{Cluster: cluster, Indices: indices, Values: make([]float64, len(indices))}
+		for i, index := range indices {
+			group.Values[i] = values[index]
+		}
+		group.Mean = sum(group.Values) / float64(len(indices))
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a].Cluster < groups[b].Cluster })
+	return groups, nil
+}
+
+// silhouetteValues computes the silhouette of every vector given the cluster it is assigned to.
+func silhouetteValues(data []Vector, assignments []Cluster, metric Metric) ([]float64, error) {
+	labels, sizes := denseLabels(assignments)
+	if len(sizes) < 2 {
+		return nil, errors.New("Expected at least two clusters to compute silhouettes")
+	}
+	values := make([]float64, len(data))
+	parallelFor(len(data), func(i int) {
+		own := labels[i]
+		if sizes[own] == 1 {
+			return
+		}
+		totals := make([]float64, len(sizes))
+		for j, other := range data {
+			if j != i {
+				totals[labels[j]] += metric(data[i], other)
+			}
+		}
+		a, b := totals[own]/float64(sizes[own]-1), math.Inf(1)
+		for label, total := range totals {
+			if label != own {
+				b = math.Min(b, total/float64(sizes[label]))
+			}
+		}
+		if spread := math.Max(a, b); spread > 0 {
+			values[i] = (b - a) / spread
+		}
+	})
+	return values, nil
+}
+
+// denseLabels numbers the distinct clusters of the assignments from 0 in order of appearance,
+// returning the number of every assignment and the size of every numbered cluster.
+func denseLabels(assignments []Cluster) ([]int, []int) {
+	numbers := make(map[Cluster]int)
+	labels := make([]int, len(assignments))
+	var sizes []int
+	for i, cluster := range assignments {
+		number, ok := numbers[cluster]
+		if !ok {
+			number = len(sizes)
+			numbers[cluster] = number
+			sizes = append(sizes, 0)
+		}
+		labels[i] = number
+		sizes[number]++
+	}
+	return labels, sizes
+}
+
+// DaviesBouldin will compute the Davies-Bouldin index of the clustering of the dataset by the clusterer: the mean over all clusters of the largest ratio
+// between the sum of the scatters of the cluster and another cluster and the distance between their centroids, using Euclidean distances.
+// The scatter of a cluster is the mean distance of its vectors to its centroid. Lower values indicate compact, well separated clusters.
+func DaviesBouldin(dataset *Dataset, clusterer SimpleFlatClusterer) (float64, error) {
+	labels, sizes, centroids, err := clusterCentroids(dataset, clusterer)
+	if err != nil {
+		return 0, err
+	}
+	if len(sizes) < 2 {
+		return 0, errors.New("Expected at least two clusters to compute the Davies-Bouldin index")
+	}
+	scatters := make([]float64, len(sizes))
+	for i, vec := range dataset.data {
+		scatters[labels[i]] += Euclidean(vec, centroids[labels[i]]) / float64(sizes[labels[i]])
+	}
+	index := 0.0
+	for a := range centroids {
+		worst := 0.0
+		for b := range centroids {
+			if a != b {
+				worst = math.Max(worst, (scatters[a]+scatters[b])/Euclidean(centroids[a], centroids[b]))
+			}
+		}
+		index += worst / float64(len(centroids))
+	}
+	return index, nil
+}
+
+// CalinskiHarabasz will compute the Calinski-Harabasz (variance ratio) score of the clustering of the dataset by the clusterer:
+// the ratio between the dispersion of the centroids around the mean and the dispersion of the vectors around their centroids, each normalized by its degrees of freedom.
+// Higher values indicate dense, well separated clusters.
+func CalinskiHarabasz(dataset *Dataset, clusterer SimpleFlatClusterer) (float64, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return 0, err
+	}
+	return calinskiHarabasz(dataset, assignments)
+}
+
+func calinskiHarabasz(dataset *Dataset, assignments []Cluster) (float64, error) {
+	labels, sizes, centroids := assignmentCentroids(dataset, assignments)
+	n, k := dataset.Count(), len(sizes)
+	if k < 2 || k >= n {
+		return 0, fmt.Errorf("Expected between 2 and %d clusters to compute the Calinski-Harabasz score but got %d", n-1, k)
+	}
+	mean := dataset.data[0].Creator().Null()
+	for _, vec := range dataset.data {
+		mean = mean.Add(vec.MulScalar(1 / float64(n)))
+	}
+	between, within := 0.0, withinSumOfSquares(dataset, labels, centroids)
+	for c, centroid := range centroids {
+		distance := Euclidean(centroid, mean)
+		between += float64(sizes[c]) * distance * distance
+	}
+	if within == 0 {
+		return math.Inf(1), nil
+	}
+	return between * float64(n-k) / (within * float64(k-1)), nil
+}
+
+// Dunn will compute the Dunn index of the clustering of the dataset by the clusterer: the smallest distance between vectors of different clusters
+// divided by the largest distance between vectors of the same cluster. Higher values indicate compact, well separated clusters.
+// All pairwise distances are computed, using all cores, so the metric must be safe for concurrent use.
+func Dunn(dataset *Dataset, clusterer SimpleFlatClusterer, metric Metric) (float64, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return 0, err
+	}
+	labels, sizes := denseLabels(assignments)
+	if len(sizes) < 2 {
+		return 0, errors.New("Expected at least two clusters to compute the Dunn index")
+	}
+	data := dataset.data
+	separations, diameters := make([]float64, len(data)), make([]float64, len(data))
+	parallelFor(len(data), func(i int) {
+		separations[i] = math.Inf(1)
+		for j := i + 1; j < len(data); j++ {
+			distance := metric(data[i], data[j])
+			if labels[i] == labels[j] {
+				diameters[i] = math.Max(diameters[i], distance)
+			} else {
+				separations[i] = math.Min(separations[i], distance)
+			}
+		}
+	})
+	separation, diameter := math.Inf(1), 0.0
+	for i := range data {
+		separation, diameter = math.Min(separation, separations[i]), math.Max(diameter, diameters[i])
+	}
+	if diameter == 0 {
+		return math.Inf(1), nil
+	}
+	return separation / diameter, nil
+}
+
+// Inertia will compute the within-cluster sum of squares of the clustering of the dataset by the clusterer,
+// i.e., the sum of the squared Euclidean distances between every vector and the mean of the vectors in its cluster. Lower values indicate more compact clusters.
+func Inertia(dataset *Dataset, clusterer SimpleFlatClusterer) (float64, error) {
+	labels, _, centroids, err := clusterCentroids(dataset, clusterer)
+	if err != nil {
+		return 0, err
+	}
+	return withinSumOfSquares(dataset, labels, centroids), nil
+}
+
+// withinSumOfSquares sums the squared Euclidean distances between every vector and the centroid of its numbered cluster.
+func withinSumOfSquares(dataset *Dataset, labels []int, centroids []Vector) float64 {
+	total := 0.0
+	for i, vec := range dataset.data {
+		distance := Euclidean(vec, centroids[labels[i]])
+		total += distance * distance
+	}
+	return total
+}
+
+// Hopkins will compute the Hopkins statistic of the dataset from `m` vectors sampled from the dataset and `m` vectors sampled uniformly from its bounding box,
+// testing whether the dataset has any cluster structure at all: `sum(u) / (sum(u) + sum(w))` where `u` are the Euclidean distances of the uniform vectors to their nearest vector of the dataset
+// and `w` the distances of the sampled vectors to their nearest other vector. Uniformly spread data results in values around 0.5, while values close to 1 indicate clusters.
+func Hopkins(dataset *Dataset, m int, src rand.Source) (float64, error) {
+	n := dataset.Count()
+	if m <= 0 || m >= n {
+		return 0, fmt.Errorf("Expected between 1 and %d samples but got %d", n-1, m)
+	}
+	bounds, err := FitMinMaxScaler(dataset)
+	if err != nil {
+		return 0, err
+	}
+	rng := rand.New(src)
+	creator := dataset.data[0].Creator()
+	uniform := make([]Vector, m)
+	for i := range uniform {
+		uniform[i] = creator.New(func(d int) float64 {
+			return bounds.Min[d] + rng.Float64()*(bounds.Max[d]-bounds.Min[d])
+		})
+	}
+	sampled := sampleIndices(n, m, rng)
+	u, w := make([]float64, m), make([]float64, m)
+	parallelFor(m, func(i int) {
+		u[i], w[i] = math.Inf(1), math.Inf(1)
+		for j, vec := range dataset.data {
+			u[i] = math.Min(u[i], Euclidean(uniform[i], vec))
+			if j != sampled[i] {
+				w[i] = math.Min(w[i], Euclidean(dataset.data[sampled[i]], vec))
+			}
+		}
+	})
+	if total := sum(u) + sum(w); total > 0 {
+		return sum(u) / total, nil
+	}
+	return 0.5, nil
+}
+
+// XieBeni will compute the Xie-Beni index of a fuzzy clustering of the dataset, where `memberships[i][c]` is the degree to which the `i`th vector belongs to cluster `c`,
+// e.g., the Probabilities of a GaussianMixture. The index is the fuzzy within-cluster sum of squares divided by the number of vectors times the smallest squared distance between two centroids,
+// where memberships are raised to the power `fuzziness` (usually 2) and the centroids are the correspondingly weighted means. Lower values indicate compact, well separated clusters.
+func XieBeni(dataset *Dataset, memberships [][]float64, fuzziness float64) (float64, error) {
+	n := dataset.Count()
+	if len(memberships) != n || n == 0 {
+		return 0, fmt.Errorf("Expected memberships for all %d vectors but got %d", n, len(memberships))
+	}
+	if fuzziness < 1 {
+		return 0, fmt.Errorf("Expected a fuzziness of at least 1 but got %v", fuzziness)
+	}
+	k := len(memberships[0])
+	if k < 2 {
+		return 0, errors.New("Expected at least two clusters to compute the Xie-Beni index")
+	}
+	buckets := make([]bucketCollector, k)
+	for i, vec := range dataset.data {
+		if len(memberships[i]) != k {
+			return 0, fmt.Errorf("Expected memberships of %d clusters for vector %d but got %d", k, i, len(memberships[i]))
+		}
+		for c, membership := range memberships[i] {
+			buckets[c].Collect(vec, math.Pow(membership, fuzziness))
+		}
+	}
+	centroids := make([]Vector, k)
+	for c := range buckets {
+		if centroids[c] = buckets[c].Average(); centroids[c] == nil {
+			return 0, fmt.Errorf("Cluster %d has no members", c)
+		}
+	}
+	compactness := 0.0
+	for i, vec := range dataset.data {
+		for c, membership := range memberships[i] {
+			distance := Euclidean(vec, centroids[c])
+			compactness += math.Pow(membership, fuzziness) * distance * distance
+		}
+	}
+	separation := math.Inf(1)
+	for a := range centroids {
+		for b := a + 1; b < k; b++ {
+			distance := Euclidean(centroids[a], centroids[b])
+			separation = math.Min(separation, distance*distance)
+		}
+	}
+	if separation == 0 {
+		return math.Inf(1), nil
+	}
+	return compactness / (float64(n) * separation), nil
+}
+
+// clusterCentroids assigns every vector of the dataset to a cluster, returning the numbered cluster of every vector as by denseLabels,
+// the size of every cluster and the mean of the vectors in every cluster.
+func clusterCentroids(dataset *Dataset, clusterer SimpleFlatClusterer) ([]int, []int, []Vector, error) {
+	assignments, err := Assignments(dataset, clusterer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	labels, sizes, centroids := assignmentCentroids(dataset, assignments)
+	return labels, sizes, centroids, nil
+}
+
+// assignmentCentroids returns the numbered cluster of every vector as by denseLabels, the size of every cluster and the mean of the vectors in every cluster.
+func assignmentCentroids(dataset *Dataset, assignments []Cluster) ([]int, []int, []Vector) {
+	labels, sizes := denseLabels(assignments)
+	buckets := make([]bucketCollector, len(sizes))
+	for i, vec := range dataset.data {
+		buckets[labels[i]].Collect(vec, 1)
+	}
+	centroids := make([]Vector, len(sizes))
+	for i := range buckets {
+		centroids[i] = buckets[i].Average()
+	}
+	return labels, sizes, centroids
+}
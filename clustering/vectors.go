@@ -1,167 +1,362 @@
-package clustering
-
-import (
-	"math/rand"
-	"reflect"
-)
-
-// VectorCreator is able to create a vector of some real abstract vector space.
-// A VectorCreator is a separate interface because an arbitrary vector must be able to be created without having an initial vector.
-type VectorCreator interface {
-	// New should create a new vector of some real abstract vector space with the components set as specified by the provided function.
-	// Note how the function might not be completely used, e.g., if the new vector only has 3 components only `f(0)`, `f(1)`, and `f(2)` are used.
-	New(func(int) float64) Vector
-	// Null creates a null-vector in this real abstract vector space.
-	Null() Vector
-}
-
-// Vector represents a vector in an abstract real vector space.
-type Vector interface {
-	// Add adds two vectors by component-wise addition and returns the result.
-	Add(Vector) Vector
-	// Subtract subtracts the other vector from this vector, i.e., `v - other`.
-	Subtract(Vector) Vector
-	// MulScalar multiplies this vector with a scalar.
-	MulScalar(float64) Vector
-	// TransposedMul multiplies the transpose of this vector with the other vector.
-	TransposedMul(Vector) float64
-	// Length calculates the length of this vector.
-	Length() float64
-	// Normalize will calculate the vector in the same direction but with a length of 1. When this vector is the null-vector a random vector with length 1 is returned.
-	Normalize() Vector
-	// DistanceTo will return the distance between this vector and the other vector.
-	DistanceTo(Vector) float64
-	// Creator will return a VectorCreator creating vectors of this kind.
-	Creator() VectorCreator
-}
-
-// Vector2 is a real vector with 2 components.
-type Vector2 [2]float64
-
-// Dataset is an indexed list of vectors representing some kind of dataset.
-type Dataset struct {
-	data    []Vector
-	creator VectorCreator
-}
-
-// CreateDataset will create a dataset containing the provided data.
-func CreateDataset(data []Vector, creator VectorCreator) Dataset {
-	return Dataset{data: data, creator: creator}
-}
-
-// CreateNonEmptyDataset will create a dataset containing the provided non-empty slice of data.
-func CreateNonEmptyDataset(data []Vector) Dataset {
-	if len(data) == 0 {
-		panic("Expected a non-empty dataset, but got an empty dataset")
-	}
-	return CreateDataset(data, data[0].Creator())
-}
-
-type vector2Creator struct{}
-
-func (v vector2Creator) New(f func(int) float64) Vector {
-	return Vector2{f(0), f(1)}
-}
-
-func (v vector2Creator) Null() Vector {
-	return Vector2{0, 0}
-}
-
-func checkVector2(v Vector) Vector2 {
-	v2, ok := v.(Vector2)
-	if !ok {
-		panic("Expected a Vector2 but got " + reflect.TypeOf(v).Name())
-	}
-	return v2
-}
-
-// Add adds two vectors by component-wise addition and returns the result.
-func (v Vector2) Add(other Vector) Vector {
-	otherv := checkVector2(other)
-	return Vector2{
-		v[0] + otherv[0],
-		v[1] + otherv[1],
-	}
-}
-
-// Subtract subtracts the other vector from this vector, i.e., `v - other`.
-func (v Vector2) Subtract(other Vector) Vector {
-	otherv := checkVector2(other)
-	return Vector2{
-		v[0] - otherv[0],
-		v[1] - otherv[1],
-	}
-}
-
-// MulScalar multiplies this vector with a scalar.
-func (v Vector2) MulScalar(other float64) Vector {
-	return Vector2{
-		v[0] * other,
-		v[1] * other,
-	}
-}
-
-// TransposedMul multiplies the transpose of this vector with the other vector.
-func (v Vector2) TransposedMul(other Vector) float64 {
-	otherv := checkVector2(other)
-	return v[0]*otherv[0] + v[1]*otherv[1]
-}
-
-// Length calculates the length of this vector.
-func (v Vector2) Length() float64 {
-	return v.TransposedMul(v)
-}
-
-// Normalize will calculate the vector in the same direction but with a length of 1. When this vector is the null-vector a random vector with length 1 is returned.
-func (v Vector2) Normalize() Vector {
-	if v.Length() == 0 {
-		return Vector2d(rand.Float64(), rand.Float64())
-	}
-
-	return v.MulScalar(1 / v.Length())
-}
-
-// DistanceTo will return the distance between this vector and the other vector.
-func (v Vector2) DistanceTo(other Vector) float64 {
-	otherv := checkVector2(other)
-	return v.Subtract(otherv).Length()
-}
-
-// Creator will return a VectorCreator creating Vector2s.
-func (v Vector2) Creator() VectorCreator {
-	return vector2Creator{}
-}
-
-// Vector2d creates a new 2-dimensional vector with the supplied values as its components.
-func Vector2d(x, y float64) Vector2 {
-	return Vector2{x, y}
-}
-
-// Max will return the largest vector in the dataset, if there are multiple largest vectors, the first is returned, if the dataset is empty, a vector with size 0 is returned.
-func (dataset *Dataset) Max() Vector {
-	result := dataset.creator.Null()
-	length := result.Length()
-	for _, vec := range dataset.AsSlice() {
-		vecLen := vec.Length()
-		if vecLen > length {
-			result = vec
-			length = vecLen
-		}
-	}
-	return result
-}
-
-// Count will return the number of data points in this dataset.
-func (dataset *Dataset) Count() int {
-	return len(dataset.data)
-}
-
-// IsEmpty returns true if and only if this dataset is empty.
-func (dataset *Dataset) IsEmpty() bool {
-	return dataset.Count() == 0
-}
-
-// AsSlice will give back a slice with the elements of this dataset in the same order.
-func (dataset *Dataset) AsSlice() []Vector {
-	return dataset.data
-}
+package clustering
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"reflect"
+)
+
+// VectorCreator is able to create a vector of some real abstract vector space.
+// A VectorCreator is a separate interface because an arbitrary vector must be able to be created without having an initial vector.
+type VectorCreator interface {
+	// New should create a new vector of some real abstract vector space with the components set as specified by the provided function.
+	// Note how the function might not be completely used, e.g., if the new vector only has 3 components only `f(0)`, `f(1)`, and `f(2)` are used.
+	New(func(int) float64) Vector
+	// Null creates a null-vector in this real abstract vector space.
+	Null() Vector
+}
+
+// Vector represents a vector in an abstract real vector space.
+type Vector interface {
+	// Add adds two vectors by component-wise addition and returns the result.
+	Add(Vector) Vector
+	// Subtract subtracts the other vector from this vector, i.e., `v - other`.
+	Subtract(Vector) Vector
+	// MulScalar multiplies this vector with a scalar.
+	MulScalar(float64) Vector
+	// TransposedMul multiplies the transpose of this vector with the other vector.
+	TransposedMul(Vector) float64
+	// Length calculates the length of this vector.
+	Length() float64
+	// Normalize will calculate the vector in the same direction but with a length of 1. When this vector is the null-vector a random vector with length 1 is returned.
+	Normalize() Vector
+	// DistanceTo will return the distance between this vector and the other vector.
+	DistanceTo(Vector) float64
+	// Creator will return a VectorCreator creating vectors of this kind.
+	Creator() VectorCreator
+}
+
+// IndexedVector is a Vector whose components can be read one at a time, as those of a Vector2 and a VectorN.
+// Vectors of other kinds are read through their VectorCreator instead, see Dimension and Component.
+type IndexedVector interface {
+	Vector
+	// Dimension will return the number of components of this vector.
+	Dimension() int
+	// Component will return the `i`th component of this vector.
+	Component(i int) float64
+}
+
+// Dimension will return the number of components of the vector,
+// which for a Vector that is not an IndexedVector is the number of components its VectorCreator sets when creating a vector.
+func Dimension(v Vector) int {
+	if indexed, ok := v.(IndexedVector); ok {
+		return indexed.Dimension()
+	}
+	dimension := 0
+	v.Creator().New(func(i int) float64 {
+		dimension = max(dimension, i+1)
+		return 0
+	})
+	return dimension
+}
+
+// Component will return the `i`th component of the vector,
+// which for a Vector that is not an IndexedVector is its product with the `i`th unit vector created by its VectorCreator.
+func Component(v Vector, i int) float64 {
+	if indexed, ok := v.(IndexedVector); ok {
+		return indexed.Component(i)
+	}
+	return v.TransposedMul(v.Creator().New(func(j int) float64 {
+		if j == i {
+			return 1
+		}
+		return 0
+	}))
+}
+
+// Vector2 is a real vector with 2 components.
+type Vector2 [2]float64
+
+// VectorN is a real vector with an arbitrary, but fixed, number of components.
+type VectorN []float64
+
+// Dataset is an indexed list of vectors representing some kind of dataset.
+type Dataset struct {
+	data    []Vector
+	creator VectorCreator
+	// weights holds the weight of every vector, a nil slice means every vector has weight 1.
+	weights []float64
+	// index holds the attached NeighbourIndex, if any.
+	index NeighbourIndex
+	// instrumentation holds the attached Instrumentation, if any.
+	instrumentation Instrumentation
+	// logger holds the attached logger, if any.
+	logger *slog.Logger
+}
+
+// CreateDataset will create a dataset containing the provided data.
+func CreateDataset(data []Vector, creator VectorCreator) Dataset {
+	return Dataset{data: data, creator: creator}
+}
+
+// CreateNonEmptyDataset will create a dataset containing the provided non-empty slice of data.
+func CreateNonEmptyDataset(data []Vector) Dataset {
+	if len(data) == 0 {
+		panic("Expected a non-empty dataset, but got an empty dataset")
+	}
+	return CreateDataset(data, data[0].Creator())
+}
+
+type vector2Creator struct{}
+
+func (v vector2Creator) New(f func(int) float64) Vector {
+	return Vector2{f(0), f(1)}
+}
+
+func (v vector2Creator) Null() Vector {
+	return Vector2{0, 0}
+}
+
+func checkVector2(v Vector) Vector2 {
+	v2, ok := v.(Vector2)
+	if !ok {
+		panic("Expected a Vector2 but got " + reflect.TypeOf(v).Name())
+	}
+	return v2
+}
+
+// Add adds two vectors by component-wise addition and returns the result.
+func (v Vector2) Add(other Vector) Vector {
+	otherv := checkVector2(other)
+	return Vector2{
+		v[0] + otherv[0],
+		v[1] + otherv[1],
+	}
+}
+
+// Subtract subtracts the other vector from this vector, i.e., `v - other`.
+func (v Vector2) Subtract(other Vector) Vector {
+	otherv := checkVector2(other)
+	return Vector2{
+		v[0] - otherv[0],
+		v[1] - otherv[1],
+	}
+}
+
+// MulScalar multiplies this vector with a scalar.
+func (v Vector2) MulScalar(other float64) Vector {
+	return Vector2{
+		v[0] * other,
+		v[1] * other,
+	}
+}
+
+// TransposedMul multiplies the transpose of this vector with the other vector.
+func (v Vector2) TransposedMul(other Vector) float64 {
+	otherv := checkVector2(other)
+	return v[0]*otherv[0] + v[1]*otherv[1]
+}
+
+// Length calculates the length of this vector.
+func (v Vector2) Length() float64 {
+	return v.TransposedMul(v)
+}
+
+// Normalize will calculate the vector in the same direction but with a length of 1. When this vector is the null-vector a random vector with length 1 is returned.
+func (v Vector2) Normalize() Vector {
+	if v.Length() == 0 {
+		return Vector2d(rand.Float64(), rand.Float64())
+	}
+
+	return v.MulScalar(1 / v.Length())
+}
+
+// DistanceTo will return the distance between this vector and the other vector.
+func (v Vector2) DistanceTo(other Vector) float64 {
+	otherv := checkVector2(other)
+	return v.Subtract(otherv).Length()
+}
+
+// Creator will return a VectorCreator creating Vector2s.
+func (v Vector2) Creator() VectorCreator {
+	return vector2Creator{}
+}
+
+// Dimension will return the number of components of a Vector2, which is always 2.
+func (v Vector2) Dimension() int {
+	return 2
+}
+
+// Component will return the `i`th component of this vector.
+func (v Vector2) Component(i int) float64 {
+	return v[i]
+}
+
+// Vector2d creates a new 2-dimensional vector with the supplied values as its components.
+func Vector2d(x, y float64) Vector2 {
+	return Vector2{x, y}
+}
+
+type vectorNCreator int
+
+func (dimension vectorNCreator) New(f func(int) float64) Vector {
+	v := make(VectorN, dimension)
+	for i := range v {
+		v[i] = f(i)
+	}
+	return v
+}
+
+func (dimension vectorNCreator) Null() Vector {
+	return make(VectorN, dimension)
+}
+
+func checkVectorN(v VectorN, other Vector) VectorN {
+	otherv, ok := other.(VectorN)
+	if !ok {
+		panic("Expected a VectorN but got " + reflect.TypeOf(other).Name())
+	}
+	if len(v) != len(otherv) {
+		panic(fmt.Sprintf("Expected a VectorN of dimension %d but got dimension %d", len(v), len(otherv)))
+	}
+	return otherv
+}
+
+// Add adds two vectors by component-wise addition and returns the result.
+func (v VectorN) Add(other Vector) Vector {
+	otherv := checkVectorN(v, other)
+	result := make(VectorN, len(v))
+	for i := range v {
+		result[i] = v[i] + otherv[i]
+	}
+	return result
+}
+
+// Subtract subtracts the other vector from this vector, i.e., `v - other`.
+func (v VectorN) Subtract(other Vector) Vector {
+	otherv := checkVectorN(v, other)
+	result := make(VectorN, len(v))
+	for i := range v {
+		result[i] = v[i] - otherv[i]
+	}
+	return result
+}
+
+// MulScalar multiplies this vector with a scalar.
+func (v VectorN) MulScalar(other float64) Vector {
+	result := make(VectorN, len(v))
+	for i := range v {
+		result[i] = v[i] * other
+	}
+	return result
+}
+
+// TransposedMul multiplies the transpose of this vector with the other vector.
+func (v VectorN) TransposedMul(other Vector) float64 {
+	otherv := checkVectorN(v, other)
+	result := 0.0
+	for i := range v {
+		result += v[i] * otherv[i]
+	}
+	return result
+}
+
+// Length calculates the length of this vector.
+func (v VectorN) Length() float64 {
+	return math.Sqrt(v.TransposedMul(v))
+}
+
+// Normalize will calculate the vector in the same direction but with a length of 1. When this vector is the null-vector a random vector with length 1 is returned.
+func (v VectorN) Normalize() Vector {
+	if len(v) == 0 {
+		return v
+	} else if v.Length() == 0 {
+		return v.Creator().New(func(_ int) float64 {
+			return rand.NormFloat64()
+		}).Normalize()
+	}
+
+	return v.MulScalar(1 / v.Length())
+}
+
+// DistanceTo will return the distance between this vector and the other vector.
+func (v VectorN) DistanceTo(other Vector) float64 {
+	return v.Subtract(other).Length()
+}
+
+// Creator will return a VectorCreator creating VectorNs of the same dimension as this vector.
+func (v VectorN) Creator() VectorCreator {
+	return vectorNCreator(len(v))
+}
+
+// Dimension will return the number of components of this vector.
+func (v VectorN) Dimension() int {
+	return len(v)
+}
+
+// Component will return the `i`th component of this vector.
+func (v VectorN) Component(i int) float64 {
+	return v[i]
+}
+
+// VectorNd creates a new vector with the supplied values as its components.
+func VectorNd(components ...float64) VectorN {
+	return VectorN(components)
+}
+
+func components(v Vector) []float64 {
+	result := make([]float64, Dimension(v))
+	for i := range result {
+		result[i] = Component(v, i)
+	}
+	return result
+}
+
+// Max will return the largest vector in the dataset, if there are multiple largest vectors, the first is returned, if the dataset is empty, a vector with size 0 is returned.
+func (dataset *Dataset) Max() Vector {
+	result := dataset.creator.Null()
+	length := result.Length()
+	for _, vec := range dataset.data {
+		vecLen := vec.Length()
+		if vecLen > length {
+			result = vec
+			length = vecLen
+		}
+	}
+	return result
+}
+
+// Count will return the number of data points in this dataset.
+func (dataset *Dataset) Count() int {
+	return len(dataset.data)
+}
+
+// IsEmpty returns true if and only if this dataset is empty.
+func (dataset *Dataset) IsEmpty() bool {
+	return dataset.Count() == 0
+}
+
+// AsSlice will give back a slice with the elements of this dataset in the same order.
+// The slice is the storage of this dataset, so modifying it modifies the dataset, use Vectors for a copy or ForEach to iterate.
+func (dataset *Dataset) AsSlice() []Vector {
+	return dataset.data
+}
+
+// Vectors will return a copy of the slice with the elements of this dataset in the same order, which can be modified without affecting the dataset.
+func (dataset *Dataset) Vectors() []Vector {
+	return append([]Vector(nil), dataset.data...)
+}
+
+// At will return the `i`th vector of this dataset.
+func (dataset *Dataset) At(i int) Vector {
+	return dataset.data[i]
+}
+
+// ForEach will call `f` with the index and the vector of every element of this dataset, in order.
+func (dataset *Dataset) ForEach(f func(i int, vec Vector)) {
+	for i, vec := range dataset.data {
+		f(i, vec)
+	}
+}
@@ -0,0 +1,154 @@
+package clustering
+
+import "fmt"
+
+// Slice will return the vectors `from` up to, but excluding, `to` of this dataset as a dataset sharing the storage of this dataset, without copying.
+// Replacing a vector of the slice therefore also replaces it in this dataset, while appending to or removing from the slice never changes this dataset.
+func (dataset *Dataset) Slice(from, to int) (Dataset, error) {
+	if from < 0 || to < from || to > dataset.Count() {
+		return Dataset{}, fmt.Errorf("Slice [%d:%d] is out of range for a dataset of %d vectors", from, to, dataset.Count())
+	}
+	slice := Dataset{data: dataset.data[from:to:to], creator: dataset.creator}
+	if dataset.weights != nil {
+		slice.weights = dataset.weights[from:to:to]
+	}
+	return slice, nil
+}
+
+// Batches will divide this dataset into consecutive batches of `size` vectors, the last batch holding the remaining vectors if the size does not divide the dataset,
+// e.g., for mini-batch or out-of-core algorithms. Every batch is a Slice sharing the storage of this dataset. Batches panics if the size is not positive.
+func (dataset *Dataset) Batches(size int) []Dataset {
+	if size <= 0 {
+		panic(fmt.Sprintf("Expected a positive batch size but got %d", size))
+	}
+	batches := make([]Dataset, 0, (dataset.Count()+size-1)/size)
+	for from := 0; from < dataset.Count(); from += size {
+		batch, _ := dataset.Slice(from, min(from+size, dataset.Count()))
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// DatasetView is a read-only window on the vectors of a dataset at a list of indices, e.g., the held-out fold of a cross-validation.
+// A view only stores the indices, every access reads the underlying dataset.
+type DatasetView struct {
+	dataset *Dataset
+	indices []int
+}
+
+// View will return a DatasetView on the vectors of this dataset at the provided indices, in the order of the indices.
+func (dataset *Dataset) View(indices ...int) (DatasetView, error) {
+	for _, i := range indices {
+		if err := dataset.checkIndex(i); err != nil {
+			return DatasetView{}, err
+		}
+	}
+	return DatasetView{dataset: dataset, indices: append([]int(nil), indices...)}, nil
+}
+
+// Count will return the number of vectors in this view.
+func (view DatasetView) Count() int {
+	return len(view.indices)
+}
+
+// At will return the `i`th vector of this view.
+func (view DatasetView) At(i int) Vector {
+	return view.dataset.data[view.indices[i]]
+}
+
+// Weight will return the weight of the `i`th vector of this view.
+func (view DatasetView) Weight(i int) float64 {
+	return view.dataset.Weight(view.indices[i])
+}
+
+// Index will return the index of the `i`th vector of this view in the underlying dataset.
+func (view DatasetView) Index(i int) int {
+	return view.indices[i]
+}
+
+// Source will return a DataSource producing the vectors of this view in order.
+func (view DatasetView) Source() DataSource {
+	return &viewSource{view: view}
+}
+
+// Dataset will return a dataset holding the vectors of this view, the vectors themselves are shared with the underlying dataset.
+func (view DatasetView) Dataset() Dataset {
+	return view.dataset.subset(view.indices)
+}
+
+type viewSource struct {
+	view DatasetView
+	next int
+}
+
+func (source *viewSource) Next() (Vector, bool) {
+	if source.next >= source.view.Count() {
+		return nil, false
+	}
+	source.next++
+	return source.view.At(source.next - 1), true
+}
+
+// TransformedView is a read-only view on a dataset in which every vector is mapped by a transformer when it is accessed,
+// such that several experiments can share the same base dataset without each materializing a full transformed copy.
+type TransformedView struct {
+	dataset     *Dataset
+	transformer Transformer
+}
+
+// TransformedView will return a view on this dataset transforming every vector by the transformer on access.
+func (dataset *Dataset) TransformedView(transformer Transformer) TransformedView {
+	return TransformedView{dataset: dataset, transformer: transformer}
+}
+
+// Then will return a view on the same dataset transforming every vector by the transformer of this view followed by the provided transformer.
+func (view TransformedView) Then(transformer Transformer) TransformedView {
+	first := view.transformer
+	return TransformedView{dataset: view.dataset, transformer: transformerFunc(func(v Vector) Vector {
+		return transformer.Transform(first.Transform(v))
+	})}
+}
+
+// Count will return the number of vectors in this view.
+func (view TransformedView) Count() int {
+	return view.dataset.Count()
+}
+
+// At will return the `i`th vector of the underlying dataset transformed.
+func (view TransformedView) At(i int) Vector {
+	return view.transformer.Transform(view.dataset.data[i])
+}
+
+// Weight will return the weight of the `i`th vector of this view.
+func (view TransformedView) Weight(i int) float64 {
+	return view.dataset.Weight(i)
+}
+
+// Source will return a DataSource producing the transformed vectors of this view in order, transforming them one at a time.
+func (view TransformedView) Source() DataSource {
+	return &transformedSource{view: view}
+}
+
+// Dataset will return a dataset holding all transformed vectors of this view, e.g., when an algorithm needs random access to every vector many times.
+func (view TransformedView) Dataset() Dataset {
+	return view.dataset.Transform(view.transformer)
+}
+
+type transformerFunc func(v Vector) Vector
+
+func (f transformerFunc) Transform(v Vector) Vector {
+	return f(v)
+}
+
+type transformedSource struct {
+	view TransformedView
+	next int
+}
+
+func (source *transformedSource) Next() (Vector, bool) {
+	if source.next >= source.view.Count() {
+		return nil, false
+	}
+	source.next++
+	return source.view.At(source.next - 1), true
+}
@@ -0,0 +1,68 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Voronoi will return the cell of every centroid of the two-dimensional clusterer clipped to the bounds, as a convex polygon whose vertices are in counter-clockwise order.
+// The cell of a centroid holds the points of the bounds that are at least as close to it as to every other centroid, i.e., the points the clusterer assigns to its cluster,
+// such that the edges of the cells are the decision boundaries of the clusterer. A cell is empty if it lies outside the bounds, or if the centroid equals an earlier centroid,
+// which wins the tie.
+func (clusterer CentroidClusterer) Voronoi(bounds Rect) ([][]Vector2, error) {
+	if !(bounds.MinX < bounds.MaxX && bounds.MinY < bounds.MaxY) {
+		return nil, errors.New("Expected bounds with a positive area")
+	}
+	centroids := make([]Vector2, len(clusterer))
+	for i, centroid := range clusterer {
+		if Dimension(centroid) != 2 {
+			return nil, fmt.Errorf("Expected two-dimensional centroids but centroid %d has dimension %d", i, Dimension(centroid))
+		}
+		centroids[i] = Vector2{Component(centroid, 0), Component(centroid, 1)}
+	}
+	cells := make([][]Vector2, len(centroids))
+	for i, centroid := range centroids {
+		cell := []Vector2{{bounds.MinX, bounds.MinY}, {bounds.MaxX, bounds.MinY}, {bounds.MaxX, bounds.MaxY}, {bounds.MinX, bounds.MaxY}}
+		for j, other := range centroids {
+			if j == i {
+				continue
+			}
+			if other == centroid {
+				if j < i {
+					cell = nil
+					break
+				}
+				continue
+			}
+			cell = clipToBisector(cell, centroid, other)
+		}
+		if len(cell) < 3 {
+			cell = nil
+		}
+		cells[i] = cell
+	}
+	return cells, nil
+}
+
+// clipToBisector clips the convex polygon to the half-plane of the points at least as close to `a` as to `b` (Sutherland-Hodgman),
+// i.e., the points `p` for which `(p - m)·(b - a) <= 0` with `m` the midpoint of `a` and `b`.
+func clipToBisector(polygon []Vector2, a, b Vector2) []Vector2 {
+	normal := Vector2{b[0] - a[0], b[1] - a[1]}
+	midpoint := Vector2{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2}
+	side := func(p Vector2) float64 {
+		return (p[0]-midpoint[0])*normal[0] + (p[1]-midpoint[1])*normal[1]
+	}
+	var clipped []Vector2
+	for i, current := range polygon {
+		previous := polygon[(i+len(polygon)-1)%len(polygon)]
+		sc, sp := side(current), side(previous)
+		if (sc < 0 && sp > 0) || (sc > 0 && sp < 0) {
+			t := sp / (sp - sc)
+			clipped = append(clipped, Vector2{previous[0] + t*(current[0]-previous[0]), previous[1] + t*(current[1]-previous[1])})
+		}
+		if sc <= 0 {
+			clipped = append(clipped, current)
+		}
+	}
+	return clipped
+}
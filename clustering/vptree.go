@@ -0,0 +1,134 @@
+package clustering
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// VPTree is a vantage-point tree over items of any kind, e.g., strings under an edit distance or time series under dynamic time warping,
+// answering exact nearest neighbour and range queries under any metric satisfying the triangle inequality.
+// Every node splits its items by whether they are closer to a random vantage point than the median distance.
+// A VPTree of vectors, built with `NewVPTree(dataset.Vectors(), metric, src)`, is a NeighbourIndex.
+type VPTree[T any] struct {
+	items  []T
+	metric func(a, b T) float64
+	// order holds the indices of the items such that every node covers a contiguous range, starting with its vantage point.
+	order []int
+	nodes []vpNode
+}
+
+// vpNode covers the items in `order[lo:hi]`, its inside child covers the items closer to the vantage point than the threshold and its outside child the others.
+// Both children are -1 for a leaf.
+type vpNode struct {
+	lo, hi          int
+	threshold       float64
+	inside, outside int
+}
+
+// NewVPTree will build a VPTree over the items with distances measured by the metric, choosing the vantage points with the source.
+func NewVPTree[T any](items []T, metric func(a, b T) float64, src rand.Source) *VPTree[T] {
+	tree := &VPTree[T]{items: items, metric: metric, order: make([]int, len(items))}
+	for i := range tree.order {
+		tree.order[i] = i
+	}
+	if len(items) > 0 {
+		tree.build(0, len(items), rand.New(src), make([]float64, len(items)))
+	}
+	return tree
+}
+
+// build adds the node covering `order[lo:hi]` and its descendants, returning its position.
+func (tree *VPTree[T]) build(lo, hi int, rng *rand.Rand, distances []float64) int {
+	position := len(tree.nodes)
+	tree.nodes = append(tree.nodes, vpNode{lo: lo, hi: hi, inside: -1, outside: -1})
+	if hi-lo <= kdLeafSize {
+		return position
+	}
+	vantage := lo + rng.Intn(hi-lo)
+	tree.order[lo], tree.order[vantage] = tree.order[vantage], tree.order[lo]
+	rest := tree.order[lo+1 : hi]
+	for j, i := range rest {
+		distances[lo+1+j] = tree.metric(tree.items[tree.order[lo]], tree.items[i])
+	}
+	sort.Sort(byDistance{indices: rest, distances: distances[lo+1 : hi]})
+	middle := lo + 1 + len(rest)/2
+	tree.nodes[position].threshold = distances[middle]
+	inside := tree.build(lo+1, middle, rng, distances)
+	outside := tree.build(middle, hi, rng, distances)
+	tree.nodes[position].inside, tree.nodes[position].outside = inside, outside
+	return position
+}
+
+// byDistance sorts indices together with their distances.
+type byDistance struct {
+	indices   []int
+	distances []float64
+}
+
+func (sorter byDistance) Len() int           { return len(sorter.indices) }
+func (sorter byDistance) Less(a, b int) bool { return sorter.distances[a] < sorter.distances[b] }
+func (sorter byDistance) Swap(a, b int) {
+	sorter.indices[a], sorter.indices[b] = sorter.indices[b], sorter.indices[a]
+	sorter.distances[a], sorter.distances[b] = sorter.distances[b], sorter.distances[a]
+}
+
+// search visits every item of the node that may be within the bound of the query.
+func (tree *VPTree[T]) search(position int, query T, visit func(i int, distance float64), bound func() float64) {
+	node := tree.nodes[position]
+	if node.inside < 0 {
+		for _, i := range tree.order[node.lo:node.hi] {
+			visit(i, tree.metric(query, tree.items[i]))
+		}
+		return
+	}
+	vantage := tree.order[node.lo]
+	distance := tree.metric(query, tree.items[vantage])
+	visit(vantage, distance)
+	if distance < node.threshold {
+		tree.search(node.inside, query, visit, bound)
+		if distance+bound() >= node.threshold {
+			tree.search(node.outside, query, visit, bound)
+		}
+	} else {
+		tree.search(node.outside, query, visit, bound)
+		if distance-bound() <= node.threshold {
+			tree.search(node.inside, query, visit, bound)
+		}
+	}
+}
+
+// Nearest returns the `k` items closest to the query in increasing order of distance, or all items if there are fewer.
+func (tree *VPTree[T]) Nearest(query T, k int) []Neighbour {
+	nearest := &nearestNeighbours{k: max(k, 0)}
+	if len(tree.nodes) > 0 {
+		tree.search(0, query, nearest.offer, nearest.bound)
+	}
+	return nearest.sorted()
+}
+
+// WithinRadius returns the items within distance `radius` of the query in increasing order of distance.
+func (tree *VPTree[T]) WithinRadius(query T, radius float64) []Neighbour {
+	var within []Neighbour
+	if len(tree.nodes) == 0 || radius < 0 || math.IsNaN(radius) {
+		return within
+	}
+	tree.search(0, query, func(i int, distance float64) {
+		if distance <= radius {
+			within = append(within, Neighbour{Index: i, Distance: distance})
+		}
+	}, func() float64 { return radius })
+	sortNeighbours(within)
+	return within
+}
+
+// DBSCAN will perform DBSCAN clustering, as in DBSCANFromDistances, on the items of the tree using range queries instead of all pairwise distances.
+func (tree *VPTree[T]) DBSCAN(eps float64, minPoints int) ([]Cluster, error) {
+	if err := checkDBSCANParameters(eps, minPoints); err != nil {
+		return nil, err
+	}
+	neighbours := func(i int) []int {
+		return neighbourIndices(tree.WithinRadius(tree.items[i], eps))
+	}
+	return dbscan(len(tree.items), neighbours, minPoints), nil
+}
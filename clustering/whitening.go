@@ -0,0 +1,87 @@
+package clustering
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ZCAWhitener is an InvertibleTransformer decorrelating the components and scaling them to unit variance (ZCA or Mahalanobis whitening),
+// i.e., `W (v - mean)` with `W = Σ^(-1/2)` the inverse square root of the covariance of the fitted dataset.
+// Of all whitening transforms, ZCA keeps the whitened vectors as close as possible to the original ones.
+type ZCAWhitener struct {
+	// Mean is the mean of the fitted dataset.
+	Mean []float64
+	// Whitening is the whitening matrix `W` and Dewhitening its inverse.
+	Whitening, Dewhitening [][]float64
+}
+
+// FitZCAWhitener will create a ZCAWhitener from the covariance of the vectors in the dataset,
+// the regularization is added to every eigenvalue of the covariance so directions without variance are not blown up.
+func FitZCAWhitener(dataset *Dataset, regularization float64) (ZCAWhitener, error) {
+	if dataset.IsEmpty() {
+		return ZCAWhitener{}, errors.New("Cannot fit a whitening transform on an empty dataset")
+	}
+	if regularization < 0 {
+		return ZCAWhitener{}, fmt.Errorf("Expected a non-negative regularization but got %v", regularization)
+	}
+	mean, covariance := covariance(dataset)
+	whitening := covariance.spectralMap(func(value float64) float64 {
+		return 1 / math.Sqrt(math.Max(0, value)+regularization)
+	})
+	dewhitening := covariance.spectralMap(func(value float64) float64 {
+		return math.Sqrt(math.Max(0, value) + regularization)
+	})
+	for i := range whitening {
+		for j := range whitening[i] {
+			if math.IsInf(whitening[i][j], 0) || math.IsNaN(whitening[i][j]) {
+				return ZCAWhitener{}, errors.New("The covariance of the dataset is singular, a positive regularization is required")
+			}
+		}
+	}
+	return ZCAWhitener{Mean: mean, Whitening: whitening, Dewhitening: dewhitening}, nil
+}
+
+// Transform whitens the vector.
+func (whitener ZCAWhitener) Transform(v Vector) Vector {
+	checkTransformDimension(v, len(whitener.Mean))
+	centered := components(v)
+	for i := range centered {
+		centered[i] -= whitener.Mean[i]
+	}
+	whitened := matrix(whitener.Whitening).mulVec(centered)
+	return v.Creator().New(func(i int) float64 {
+		return whitened[i]
+	})
+}
+
+// InverseTransform maps the whitened vector back into the original vector space.
+func (whitener ZCAWhitener) InverseTransform(v Vector) Vector {
+	checkTransformDimension(v, len(whitener.Mean))
+	dewhitened := matrix(whitener.Dewhitening).mulVec(components(v))
+	return v.Creator().New(func(i int) float64 {
+		return dewhitened[i] + whitener.Mean[i]
+	})
+}
+
+// covariance computes the mean and the (population) covariance matrix of the vectors in the non-empty dataset.
+func covariance(dataset *Dataset) ([]float64, matrix) {
+	data := dataset.data
+	dimension, n := Dimension(data[0]), float64(len(data))
+	mean := make([]float64, dimension)
+	for _, vec := range data {
+		checkTransformDimension(vec, dimension)
+		for i := range mean {
+			mean[i] += Component(vec, i) / n
+		}
+	}
+	covariance := newMatrix(dimension, dimension)
+	diff := make([]float64, dimension)
+	for _, vec := range data {
+		for i := range diff {
+			diff[i] = Component(vec, i) - mean[i]
+		}
+		covariance.addOuter(diff, 1/n)
+	}
+	return mean, covariance
+}
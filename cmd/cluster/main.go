@@ -0,0 +1,310 @@
+// Command cluster clusters the records of a CSV, JSON or newline-delimited JSON file and writes the cluster of every record,
+// the centroids of the clusters and validity scores of the clustering.
+//
+// Usage:
+//
+//	cluster [flags] [file]
+//
+// The records are read from the file, or from standard input if it is omitted or `-`. For example,
+//
+//	cluster -algorithm kmeans -k 3 -scale standard -centroids centroids.csv points.csv > assignments.csv
+//
+// clusters the standardized rows of a CSV file with a header into 3 clusters, writing the assignments to standard output
+// and the centroids, in the units of the file, to centroids.csv. The validity scores are written to standard error.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/frederikdesmedt/clustering"
+)
+
+var (
+	format    = flag.String("format", "", "format of the input: csv, json or ndjson, derived from the extension of the file if not set and csv for standard input")
+	header    = flag.Bool("header", true, "the first record of a CSV file holds the names of the columns")
+	delimiter = flag.String("delimiter", ",", "the delimiter of the fields of a CSV file, \\t for tabs")
+	columns   = flag.String("columns", "", "comma-separated names of the numeric columns or fields to cluster, all columns of a CSV file if not set, required for JSON")
+	id        = flag.String("id", "", "name of the JSON field identifying a record, records are identified by their position if not set")
+
+	algorithm  = flag.String("algorithm", "kmeans", "clustering algorithm: kmeans, kmedoids, fuzzy, gmm or dbscan")
+	k          = flag.Int("k", 3, "number of clusters for kmeans, kmedoids, fuzzy and gmm")
+	eps        = flag.Float64("eps", 0.5, "neighbourhood radius for dbscan")
+	minPoints  = flag.Int("min-points", 5, "minimum number of neighbours of a core point for dbscan")
+	metricName = flag.String("metric", "euclidean", "metric for kmedoids, dbscan and the silhouette: euclidean, cosine or pearson")
+	scale      = flag.String("scale", "none", "scaling applied before clustering: none, standard, minmax or robust")
+	seed       = flag.Int64("seed", 1, "seed of the random initialization")
+
+	output    = flag.String("output", "-", "file the assignments are written to, standard output if -")
+	outFormat = flag.String("output-format", "csv", "format of the assignments: csv or ndjson")
+	centroids = flag.String("centroids", "", "file the centroids of the clusters are written to as CSV, in the units of the input")
+	scores    = flag.Bool("scores", true, "write validity scores of the clustering to standard error")
+)
+
+var metrics = map[string]clustering.Metric{"euclidean": clustering.Euclidean, "cosine": clustering.Cosine, "pearson": clustering.Pearson}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "cluster: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	distance, ok := metrics[*metricName]
+	if !ok {
+		return fmt.Errorf("Unknown metric %q", *metricName)
+	}
+	dataset, names, ids, err := load(path)
+	if err != nil {
+		return err
+	}
+	if dataset.Count() == 0 {
+		return errors.New("The input holds no records")
+	}
+	scaled, err := scaleDataset(&dataset)
+	if err != nil {
+		return err
+	}
+	clusterer, err := fit(&scaled, distance)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := clustering.AssignRows(&scaled, clusterer, ids)
+	if err != nil {
+		return err
+	}
+	if err := create(*output, func(w io.Writer) error {
+		if *outFormat == "ndjson" {
+			return clustering.WriteAssignmentsNDJSON(w, assignments)
+		} else if *outFormat != "csv" {
+			return fmt.Errorf("Unknown output format %q", *outFormat)
+		}
+		return clustering.WriteAssignmentsCSV(w, assignments)
+	}); err != nil {
+		return err
+	}
+	if *centroids != "" {
+		if err := create(*centroids, func(w io.Writer) error { return writeCentroids(w, &dataset, assignments, names) }); err != nil {
+			return err
+		}
+	}
+	if *scores {
+		writeScores(os.Stderr, &scaled, clusterer, distance)
+	}
+	return nil
+}
+
+// load reads the dataset, the names of its components and the IDs of its records, which are nil if records are identified by their position.
+func load(path string) (clustering.Dataset, []string, []string, error) {
+	input := os.Stdin
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return clustering.Dataset{}, nil, nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+	kind := *format
+	if kind == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			kind = "json"
+		case ".ndjson", ".jsonl":
+			kind = "ndjson"
+		default:
+			kind = "csv"
+		}
+	}
+	var names []string
+	if *columns != "" {
+		names = strings.Split(*columns, ",")
+	}
+
+	switch kind {
+	case "csv":
+		opts := clustering.CSVOptions{Header: *header, ColumnNames: names}
+		if *delimiter == `\t` {
+			opts.Delimiter = '\t'
+		} else if r, size := utf8.DecodeRuneInString(*delimiter); size == len(*delimiter) && size > 0 {
+			opts.Delimiter = r
+		} else {
+			return clustering.Dataset{}, nil, nil, fmt.Errorf("Expected a single character delimiter but got %q", *delimiter)
+		}
+		if *id != "" {
+			return clustering.Dataset{}, nil, nil, errors.New("Records of a CSV file are identified by their position, -id only applies to JSON")
+		}
+		source := clustering.NewCSVSource(input, opts)
+		dataset, err := clustering.Collect(source, clustering.VectorN{}.Creator())
+		if len(names) == 0 {
+			names = source.ColumnNames()
+		}
+		return dataset, names, nil, err
+	case "json", "ndjson":
+		if len(names) == 0 {
+			return clustering.Dataset{}, nil, nil, errors.New("The fields to cluster must be selected with -columns for JSON")
+		}
+		load := clustering.LoadJSON
+		if kind == "ndjson" {
+			load = clustering.LoadNDJSON
+		}
+		dataset, metadata, err := load(input, clustering.JSONOptions{Fields: names})
+		if err != nil || *id == "" {
+			return dataset, names, nil, err
+		}
+		ids := make([]string, len(metadata))
+		for i, fields := range metadata {
+			value, ok := fields[*id]
+			if !ok {
+				return clustering.Dataset{}, nil, nil, fmt.Errorf("Record %d has no field %q", i, *id)
+			}
+			ids[i] = fmt.Sprint(value)
+		}
+		return dataset, names, ids, nil
+	}
+	return clustering.Dataset{}, nil, nil, fmt.Errorf("Unknown input format %q", kind)
+}
+
+func scaleDataset(dataset *clustering.Dataset) (clustering.Dataset, error) {
+	var transformer clustering.Transformer
+	var err error
+	switch *scale {
+	case "none":
+		return *dataset, nil
+	case "standard":
+		transformer, err = clustering.FitStandardizer(dataset)
+	case "minmax":
+		transformer, err = clustering.FitMinMaxScaler(dataset)
+	case "robust":
+		transformer, err = clustering.FitRobustScaler(dataset)
+	default:
+		return clustering.Dataset{}, fmt.Errorf("Unknown scaling %q", *scale)
+	}
+	if err != nil {
+		return clustering.Dataset{}, err
+	}
+	return dataset.Transform(transformer), nil
+}
+
+func fit(dataset *clustering.Dataset, metric clustering.Metric) (clustering.SimpleFlatClusterer, error) {
+	src := rand.NewSource(*seed)
+	switch *algorithm {
+	case "kmeans":
+		clusterer := dataset.KMeansPlusPlus(*k, src)
+		return &clusterer, nil
+	case "fuzzy":
+		return dataset.FuzzyCMeans(*k, clustering.FuzzyCMeansOptions{}, src)
+	case "gmm":
+		return dataset.GMM(*k, clustering.GMMOptions{}, src)
+	case "kmedoids":
+		medoids, err := dataset.KMedoids(*k, metric)
+		if err != nil {
+			return nil, err
+		}
+		return clustering.AssignmentClusterer(dataset, medoids.Assignments, metric)
+	case "dbscan":
+		var labels []clustering.Cluster
+		var err error
+		if *metricName == "euclidean" {
+			labels, err = dataset.DBSCAN(*eps, *minPoints)
+		} else {
+			labels, err = clustering.DBSCANFromDistances(clustering.CachedDistances(dataset, metric), *eps, *minPoints)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return clustering.AssignmentClusterer(dataset, labels, metric)
+	}
+	return nil, fmt.Errorf("Unknown algorithm %q", *algorithm)
+}
+
+// writeCentroids writes the mean of the records of every cluster, leaving out noise, in the units of the input.
+func writeCentroids(w io.Writer, dataset *clustering.Dataset, assignments []clustering.Assignment, names []string) error {
+	dimension := clustering.Dimension(dataset.At(0))
+	if len(names) == 0 {
+		for i := 0; i < dimension; i++ {
+			names = append(names, "x"+strconv.Itoa(i))
+		}
+	}
+	sums, counts, order := make(map[clustering.Cluster][]float64), make(map[clustering.Cluster]int), []clustering.Cluster(nil)
+	for i, assignment := range assignments {
+		if assignment.Cluster == clustering.Noise {
+			continue
+		}
+		if sums[assignment.Cluster] == nil {
+			sums[assignment.Cluster] = make([]float64, dimension)
+			order = append(order, assignment.Cluster)
+		}
+		for d := range sums[assignment.Cluster] {
+			sums[assignment.Cluster][d] += clustering.Component(dataset.At(i), d)
+		}
+		counts[assignment.Cluster]++
+	}
+	if _, err := fmt.Fprintf(w, "cluster,size,%s\n", strings.Join(names, ",")); err != nil {
+		return err
+	}
+	for _, cluster := range order {
+		record := []string{strconv.Itoa(int(cluster)), strconv.Itoa(counts[cluster])}
+		for _, sum := range sums[cluster] {
+			record = append(record, strconv.FormatFloat(sum/float64(counts[cluster]), 'g', -1, 64))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(record, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeScores writes the validity scores that can be computed for the clustering, on the scaled dataset.
+func writeScores(w io.Writer, dataset *clustering.Dataset, clusterer clustering.SimpleFlatClusterer, metric clustering.Metric) {
+	score := func(name string, value float64, err error) {
+		if err == nil && !math.IsNaN(value) {
+			fmt.Fprintf(w, "%s\t%.6g\n", name, value)
+		}
+	}
+	fmt.Fprintf(w, "clusters\t%d\n", len(clusterer.Clusters()))
+	inertia, err := clustering.Inertia(dataset, clusterer)
+	score("inertia", inertia, err)
+	silhouette, _, err := clustering.Silhouette(dataset, clusterer, metric)
+	score("silhouette", silhouette, err)
+	daviesBouldin, err := clustering.DaviesBouldin(dataset, clusterer)
+	score("davies-bouldin", daviesBouldin, err)
+	calinskiHarabasz, err := clustering.CalinskiHarabasz(dataset, clusterer)
+	score("calinski-harabasz", calinskiHarabasz, err)
+}
+
+// create writes a file with `write`, or standard output if the path is `-`.
+func create(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}